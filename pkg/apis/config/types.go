@@ -24,6 +24,9 @@ type ControllerConfiguration struct {
 	ETCD ETCD
 	// HealthCheckConfig is the config for the health check controller
 	HealthCheckConfig *healthcheck.HealthCheckConfig
+	// CapacityCheck is the configuration of the capacity checks which the worker controller performs before it
+	// provisions machines.
+	CapacityCheck *CapacityCheck
 }
 
 // ETCD is an etcd configuration.
@@ -39,3 +42,14 @@ type ETCDStorage struct {
 	// Capacity is the storage capacity used in etcd-main volume claims.
 	Capacity *resource.Quantity
 }
+
+// CapacityCheck is the configuration of the capacity checks of the plans of the worker pools.
+type CapacityCheck struct {
+	// Enabled specifies whether the capacity of the plans is checked.
+	Enabled *bool
+	// WarningLevel is the capacity level up to which a warning event is emitted for a plan.
+	WarningLevel *string
+	// Blocking specifies whether the machine deployments whose plan has unavailable capacity keep their previous
+	// machine class and replicas.
+	Blocking *bool
+}
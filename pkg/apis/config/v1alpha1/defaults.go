@@ -6,8 +6,29 @@ package v1alpha1
 
 import (
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
 )
 
 func addDefaultingFuncs(scheme *runtime.Scheme) error {
 	return RegisterDefaults(scheme)
 }
+
+// SetDefaults_ControllerConfiguration sets defaults for the ControllerConfiguration.
+func SetDefaults_ControllerConfiguration(obj *ControllerConfiguration) {
+	if obj.CapacityCheck == nil {
+		obj.CapacityCheck = &CapacityCheck{}
+	}
+}
+
+// SetDefaults_CapacityCheck sets defaults for the CapacityCheck.
+func SetDefaults_CapacityCheck(obj *CapacityCheck) {
+	if obj.Enabled == nil {
+		obj.Enabled = ptr.To(true)
+	}
+	if obj.WarningLevel == nil {
+		obj.WarningLevel = ptr.To("limited")
+	}
+	if obj.Blocking == nil {
+		obj.Blocking = ptr.To(true)
+	}
+}
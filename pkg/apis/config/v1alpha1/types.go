@@ -26,6 +26,10 @@ type ControllerConfiguration struct {
 	// HealthCheckConfig is the config for the health check controller
 	// +optional
 	HealthCheckConfig *healthcheckconfigv1alpha1.HealthCheckConfig `json:"healthCheckConfig,omitempty"`
+	// CapacityCheck is the configuration of the capacity checks which the worker controller performs before it
+	// provisions machines.
+	// +optional
+	CapacityCheck *CapacityCheck `json:"capacityCheck,omitempty"`
 }
 
 // ETCD is an etcd configuration.
@@ -43,3 +47,18 @@ type ETCDStorage struct {
 	// +optional
 	Capacity *resource.Quantity `json:"capacity,omitempty"`
 }
+
+// CapacityCheck is the configuration of the capacity checks of the plans of the worker pools.
+type CapacityCheck struct {
+	// Enabled specifies whether the capacity of the plans is checked. Defaults to true.
+	// +optional
+	Enabled *bool `json:"enabled,omitempty"`
+	// WarningLevel is the capacity level up to which a warning event is emitted for a plan. It is either "limited" or
+	// "unavailable". Defaults to "limited".
+	// +optional
+	WarningLevel *string `json:"warningLevel,omitempty"`
+	// Blocking specifies whether the machine deployments whose plan has unavailable capacity keep their previous
+	// machine class and replicas. Defaults to true.
+	// +optional
+	Blocking *bool `json:"blocking,omitempty"`
+}
@@ -28,6 +28,16 @@ func init() {
 // RegisterConversions adds conversion functions to the given scheme.
 // Public to allow building arbitrary schemes.
 func RegisterConversions(s *runtime.Scheme) error {
+	if err := s.AddGeneratedConversionFunc((*CapacityCheck)(nil), (*config.CapacityCheck)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_CapacityCheck_To_config_CapacityCheck(a.(*CapacityCheck), b.(*config.CapacityCheck), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*config.CapacityCheck)(nil), (*CapacityCheck)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_config_CapacityCheck_To_v1alpha1_CapacityCheck(a.(*config.CapacityCheck), b.(*CapacityCheck), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*ControllerConfiguration)(nil), (*config.ControllerConfiguration)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_ControllerConfiguration_To_config_ControllerConfiguration(a.(*ControllerConfiguration), b.(*config.ControllerConfiguration), scope)
 	}); err != nil {
@@ -61,6 +71,30 @@ func RegisterConversions(s *runtime.Scheme) error {
 	return nil
 }
 
+func autoConvert_v1alpha1_CapacityCheck_To_config_CapacityCheck(in *CapacityCheck, out *config.CapacityCheck, s conversion.Scope) error {
+	out.Enabled = (*bool)(unsafe.Pointer(in.Enabled))
+	out.WarningLevel = (*string)(unsafe.Pointer(in.WarningLevel))
+	out.Blocking = (*bool)(unsafe.Pointer(in.Blocking))
+	return nil
+}
+
+// Convert_v1alpha1_CapacityCheck_To_config_CapacityCheck is an autogenerated conversion function.
+func Convert_v1alpha1_CapacityCheck_To_config_CapacityCheck(in *CapacityCheck, out *config.CapacityCheck, s conversion.Scope) error {
+	return autoConvert_v1alpha1_CapacityCheck_To_config_CapacityCheck(in, out, s)
+}
+
+func autoConvert_config_CapacityCheck_To_v1alpha1_CapacityCheck(in *config.CapacityCheck, out *CapacityCheck, s conversion.Scope) error {
+	out.Enabled = (*bool)(unsafe.Pointer(in.Enabled))
+	out.WarningLevel = (*string)(unsafe.Pointer(in.WarningLevel))
+	out.Blocking = (*bool)(unsafe.Pointer(in.Blocking))
+	return nil
+}
+
+// Convert_config_CapacityCheck_To_v1alpha1_CapacityCheck is an autogenerated conversion function.
+func Convert_config_CapacityCheck_To_v1alpha1_CapacityCheck(in *config.CapacityCheck, out *CapacityCheck, s conversion.Scope) error {
+	return autoConvert_config_CapacityCheck_To_v1alpha1_CapacityCheck(in, out, s)
+}
+
 func autoConvert_v1alpha1_ControllerConfiguration_To_config_ControllerConfiguration(in *ControllerConfiguration, out *config.ControllerConfiguration, s conversion.Scope) error {
 	if in.ClientConnection != nil {
 		in, out := &in.ClientConnection, &out.ClientConnection
@@ -75,6 +109,7 @@ func autoConvert_v1alpha1_ControllerConfiguration_To_config_ControllerConfigurat
 		return err
 	}
 	out.HealthCheckConfig = (*apisconfigv1alpha1.HealthCheckConfig)(unsafe.Pointer(in.HealthCheckConfig))
+	out.CapacityCheck = (*config.CapacityCheck)(unsafe.Pointer(in.CapacityCheck))
 	return nil
 }
 
@@ -97,6 +132,7 @@ func autoConvert_config_ControllerConfiguration_To_v1alpha1_ControllerConfigurat
 		return err
 	}
 	out.HealthCheckConfig = (*apisconfigv1alpha1.HealthCheckConfig)(unsafe.Pointer(in.HealthCheckConfig))
+	out.CapacityCheck = (*CapacityCheck)(unsafe.Pointer(in.CapacityCheck))
 	return nil
 }
 
@@ -17,5 +17,13 @@ import (
 // Public to allow building arbitrary schemes.
 // All generated defaulters are covering - they call all nested defaulters.
 func RegisterDefaults(scheme *runtime.Scheme) error {
+	scheme.AddTypeDefaultingFunc(&ControllerConfiguration{}, func(obj interface{}) { SetObjectDefaults_ControllerConfiguration(obj.(*ControllerConfiguration)) })
 	return nil
 }
+
+func SetObjectDefaults_ControllerConfiguration(in *ControllerConfiguration) {
+	SetDefaults_ControllerConfiguration(in)
+	if in.CapacityCheck != nil {
+		SetDefaults_CapacityCheck(in.CapacityCheck)
+	}
+}
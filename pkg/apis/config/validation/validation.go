@@ -0,0 +1,35 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package validation
+
+import (
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/config"
+)
+
+var validCapacityWarningLevels = sets.New("limited", "unavailable")
+
+// ValidateControllerConfiguration validates the given controller configuration.
+func ValidateControllerConfiguration(cfg *config.ControllerConfiguration) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if cfg.CapacityCheck != nil {
+		allErrs = append(allErrs, validateCapacityCheck(cfg.CapacityCheck, field.NewPath("capacityCheck"))...)
+	}
+
+	return allErrs
+}
+
+func validateCapacityCheck(capacityCheck *config.CapacityCheck, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if capacityCheck.WarningLevel != nil && !validCapacityWarningLevels.Has(*capacityCheck.WarningLevel) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("warningLevel"), *capacityCheck.WarningLevel, sets.List(validCapacityWarningLevels)))
+	}
+
+	return allErrs
+}
@@ -0,0 +1,47 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package validation_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/config"
+	. "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/config/validation"
+)
+
+var _ = Describe("ControllerConfiguration validation", func() {
+	Describe("#ValidateControllerConfiguration", func() {
+		It("should allow an empty configuration", func() {
+			Expect(ValidateControllerConfiguration(&config.ControllerConfiguration{})).To(BeEmpty())
+		})
+
+		DescribeTable("capacity check warning level",
+			func(warningLevel *string, valid bool) {
+				errs := ValidateControllerConfiguration(&config.ControllerConfiguration{
+					CapacityCheck: &config.CapacityCheck{WarningLevel: warningLevel},
+				})
+
+				if valid {
+					Expect(errs).To(BeEmpty())
+				} else {
+					Expect(errs).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeNotSupported),
+						"Field": Equal("capacityCheck.warningLevel"),
+					}))))
+				}
+			},
+
+			Entry("unset", nil, true),
+			Entry("limited", ptr.To("limited"), true),
+			Entry("unavailable", ptr.To("unavailable"), true),
+			Entry("normal", ptr.To("normal"), false),
+			Entry("misspelled", ptr.To("Limited"), false),
+		)
+	})
+})
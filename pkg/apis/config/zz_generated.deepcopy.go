@@ -15,6 +15,37 @@ import (
 	componentbaseconfig "k8s.io/component-base/config"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapacityCheck) DeepCopyInto(out *CapacityCheck) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.WarningLevel != nil {
+		in, out := &in.WarningLevel, &out.WarningLevel
+		*out = new(string)
+		**out = **in
+	}
+	if in.Blocking != nil {
+		in, out := &in.Blocking, &out.Blocking
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapacityCheck.
+func (in *CapacityCheck) DeepCopy() *CapacityCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(CapacityCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ControllerConfiguration) DeepCopyInto(out *ControllerConfiguration) {
 	*out = *in
@@ -30,6 +61,11 @@ func (in *ControllerConfiguration) DeepCopyInto(out *ControllerConfiguration) {
 		*out = new(v1alpha1.HealthCheckConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.CapacityCheck != nil {
+		in, out := &in.CapacityCheck, &out.CapacityCheck
+		*out = new(CapacityCheck)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -5,39 +5,146 @@
 package helper
 
 import (
+	"bytes"
 	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"text/template"
+
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	"k8s.io/utils/ptr"
 
 	api "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal"
 )
 
 // FindMachineImage takes a list of machine images and tries to find the first entry
-// whose name, version, and zone matches with the given name, version, and zone. If no such entry is
+// whose name, version, and architecture matches with the given name, version, and architecture. If no such entry is
 // found then an error will be returned.
-func FindMachineImage(machineImages []api.MachineImage, name, version string) (*api.MachineImage, error) {
+func FindMachineImage(machineImages []api.MachineImage, name, version string, architecture *string) (*api.MachineImage, error) {
 	for _, machineImage := range machineImages {
-		if machineImage.Name == name && machineImage.Version == version {
+		if machineImage.Name == name && machineImage.Version == version && equalArchitecture(machineImage.Architecture, architecture) {
 			return &machineImage, nil
 		}
 	}
-	return nil, fmt.Errorf("no machine image with name %q, version %q found", name, version)
+	return nil, fmt.Errorf("no machine image with name %q, version %q and architecture %q found", name, version, ptr.Deref(architecture, v1beta1constants.ArchitectureAMD64))
 }
 
-// FindImageFromCloudProfile takes a list of machine images, and the desired image name and version. It tries
-// to find the image with the given name and version in the desired cloud profile. If it cannot be found then an error
-// is returned.
-func FindImageFromCloudProfile(cloudProfileConfig *api.CloudProfileConfig, imageName, imageVersion string) (*api.MachineImageVersion, error) {
+// FindImageFromCloudProfile takes a list of machine images, and the desired image name, version and architecture. It
+// tries to find the image with the given name, version and architecture in the desired cloud profile. If it cannot be
+// found then an error is returned.
+func FindImageFromCloudProfile(cloudProfileConfig *api.CloudProfileConfig, imageName, imageVersion string, architecture *string) (*api.MachineImageVersion, error) {
 	if cloudProfileConfig != nil {
 		for _, machineImage := range cloudProfileConfig.MachineImages {
 			if machineImage.Name != imageName {
 				continue
 			}
 			for _, version := range machineImage.Versions {
-				if imageVersion == version.Version {
+				if imageVersion == version.Version && equalArchitecture(version.Architecture, architecture) {
 					return &version, nil
 				}
 			}
 		}
 	}
 
-	return nil, fmt.Errorf("could not find an image for name %q in version %q", imageName, imageVersion)
+	return nil, fmt.Errorf("could not find an image for name %q in version %q for architecture %q", imageName, imageVersion, ptr.Deref(architecture, v1beta1constants.ArchitectureAMD64))
+}
+
+// MachineTimeouts returns the default timeouts of the machines of a worker pool with the given plan and machine image.
+// For every timeout, the first entry of the cloud profile config which matches and sets the timeout is used.
+func MachineTimeouts(cloudProfileConfig *api.CloudProfileConfig, plan, imageName string) api.MachineTimeouts {
+	var timeouts api.MachineTimeouts
+	if cloudProfileConfig == nil {
+		return timeouts
+	}
+
+	family, _, _ := strings.Cut(plan, ".")
+	for _, entry := range cloudProfileConfig.MachineTimeouts {
+		if (len(entry.PlanFamilies) > 0 && !slices.Contains(entry.PlanFamilies, family)) ||
+			(len(entry.MachineImages) > 0 && !slices.Contains(entry.MachineImages, imageName)) {
+			continue
+		}
+
+		if timeouts.CreationTimeout == nil {
+			timeouts.CreationTimeout = entry.CreationTimeout
+		}
+		if timeouts.HealthTimeout == nil {
+			timeouts.HealthTimeout = entry.HealthTimeout
+		}
+		if timeouts.DrainTimeout == nil {
+			timeouts.DrainTimeout = entry.DrainTimeout
+		}
+	}
+	return timeouts
+}
+
+// HibernationMode returns the hibernation mode of a worker pool with the given worker config. It defaults to the
+// hibernation mode of the given control plane config and to `Delete` if neither of them sets it.
+func HibernationMode(controlPlaneConfig *api.ControlPlaneConfig, workerConfig *api.WorkerConfig) string {
+	if workerConfig != nil && workerConfig.HibernationMode != nil {
+		return *workerConfig.HibernationMode
+	}
+	if controlPlaneConfig != nil && controlPlaneConfig.HibernationMode != nil {
+		return *controlPlaneConfig.HibernationMode
+	}
+	return api.HibernationModeDelete
+}
+
+// equalArchitecture compares the given architectures, an unset architecture is treated as amd64.
+func equalArchitecture(a, b *string) bool {
+	return ptr.Deref(a, v1beta1constants.ArchitectureAMD64) == ptr.Deref(b, v1beta1constants.ArchitectureAMD64)
+}
+
+// OperatingSystemCustomIPXE is the ID of the Equinix Metal operating system that boots a custom iPXE script.
+const OperatingSystemCustomIPXE = "custom_ipxe"
+
+// IsCustomIPXE returns true if the given machine image boots via a custom iPXE script.
+func IsCustomIPXE(machineImage *api.MachineImageVersion) bool {
+	return machineImage.ID == OperatingSystemCustomIPXE || (machineImage.ID == "" && machineImage.IPXEScriptURL != "")
+}
+
+// IPXETemplateValues contains the values which can be referenced in the iPXE configuration of a worker pool.
+type IPXETemplateValues struct {
+	// ShootName is the name of the shoot.
+	ShootName string
+	// Pool is the name of the worker pool.
+	Pool string
+	// Metro is the metro of the shoot.
+	Metro string
+	// KernelArgs are the space separated kernel arguments of the worker pool.
+	KernelArgs string
+}
+
+// RenderIPXEScript renders the given inline iPXE script template with the given values.
+func RenderIPXEScript(text string, values IPXETemplateValues) (string, error) {
+	return renderIPXETemplate(text, values)
+}
+
+// RenderIPXEScriptURL renders the given iPXE script URL template with the given values. The values are percent-encoded,
+// so that they can be used both as path segments and as query parameters of the URL, e.g. spaces are encoded as `%20`
+// instead of `+`, which is only decoded to a space in query parameters.
+func RenderIPXEScriptURL(text string, values IPXETemplateValues) (string, error) {
+	return renderIPXETemplate(text, IPXETemplateValues{
+		ShootName:  escapeIPXEScriptURLValue(values.ShootName),
+		Pool:       escapeIPXEScriptURLValue(values.Pool),
+		Metro:      escapeIPXEScriptURLValue(values.Metro),
+		KernelArgs: escapeIPXEScriptURLValue(values.KernelArgs),
+	})
+}
+
+func escapeIPXEScriptURLValue(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+func renderIPXETemplate(text string, values IPXETemplateValues) (string, error) {
+	tpl, err := template.New("ipxe").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var out bytes.Buffer
+	if err := tpl.Execute(&out, values); err != nil {
+		return "", err
+	}
+	return out.String(), nil
 }
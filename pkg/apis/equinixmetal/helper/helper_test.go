@@ -5,8 +5,12 @@
 package helper_test
 
 import (
+	"time"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 
 	api "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal"
 	. "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal/helper"
@@ -14,26 +18,30 @@ import (
 
 const configImage = "some-uuid"
 const profileImage = "some-other-uuid"
+const profileImageARM64 = "some-arm64-uuid"
 
 var _ = Describe("Helper", func() {
 	DescribeTable("#FindMachineImage",
-		func(machineImages []api.MachineImage, name, version string, expectedMachineImage *api.MachineImage, expectErr bool) {
-			machineImage, err := FindMachineImage(machineImages, name, version)
+		func(machineImages []api.MachineImage, name, version string, architecture *string, expectedMachineImage *api.MachineImage, expectErr bool) {
+			machineImage, err := FindMachineImage(machineImages, name, version, architecture)
 			expectResults(machineImage, expectedMachineImage, err, expectErr)
 		},
 
-		Entry("list is nil", nil, "foo", "1.2.3", nil, true),
-		Entry("empty list", []api.MachineImage{}, "foo", "1.2.3", nil, true),
-		Entry("entry not found (no name)", []api.MachineImage{{Name: "bar", Version: "1.2.3", ID: "1234"}}, "foo", "1.2.3", nil, true),
-		Entry("entry not found (no version)", []api.MachineImage{{Name: "bar", Version: "1.2.3", ID: "1234"}}, "foo", "1.2.4", nil, true),
-		Entry("entry exists", []api.MachineImage{{Name: "bar", Version: "1.2.3", ID: "1234"}}, "bar", "1.2.3", &api.MachineImage{Name: "bar", Version: "1.2.3", ID: "1234"}, false),
+		Entry("list is nil", nil, "foo", "1.2.3", nil, nil, true),
+		Entry("empty list", []api.MachineImage{}, "foo", "1.2.3", nil, nil, true),
+		Entry("entry not found (no name)", []api.MachineImage{{Name: "bar", Version: "1.2.3", ID: "1234"}}, "foo", "1.2.3", nil, nil, true),
+		Entry("entry not found (no version)", []api.MachineImage{{Name: "bar", Version: "1.2.3", ID: "1234"}}, "foo", "1.2.4", nil, nil, true),
+		Entry("entry not found (no architecture)", []api.MachineImage{{Name: "bar", Version: "1.2.3", ID: "1234"}}, "bar", "1.2.3", ptr.To("arm64"), nil, true),
+		Entry("entry exists", []api.MachineImage{{Name: "bar", Version: "1.2.3", ID: "1234"}}, "bar", "1.2.3", nil, &api.MachineImage{Name: "bar", Version: "1.2.3", ID: "1234"}, false),
+		Entry("entry exists (defaulted architecture)", []api.MachineImage{{Name: "bar", Version: "1.2.3", ID: "1234"}}, "bar", "1.2.3", ptr.To("amd64"), &api.MachineImage{Name: "bar", Version: "1.2.3", ID: "1234"}, false),
+		Entry("entry exists (arm64)", []api.MachineImage{{Name: "bar", Version: "1.2.3", ID: "1234"}, {Name: "bar", Version: "1.2.3", ID: "5678", Architecture: ptr.To("arm64")}}, "bar", "1.2.3", ptr.To("arm64"), &api.MachineImage{Name: "bar", Version: "1.2.3", ID: "5678", Architecture: ptr.To("arm64")}, false),
 	)
 
 	DescribeTable("#FindImage",
-		func(profileImages []api.MachineImages, imageName, version string, expectedImage string) {
+		func(profileImages []api.MachineImages, imageName, version string, architecture *string, expectedImage string) {
 			cfg := &api.CloudProfileConfig{}
 			cfg.MachineImages = profileImages
-			image, err := FindImageFromCloudProfile(cfg, imageName, version)
+			image, err := FindImageFromCloudProfile(cfg, imageName, version, architecture)
 
 			if expectedImage != "" {
 				Expect(image.ID).To(Equal(expectedImage))
@@ -43,13 +51,86 @@ var _ = Describe("Helper", func() {
 			}
 		},
 
-		Entry("list is nil", nil, "ubuntu", "1", ""),
+		Entry("list is nil", nil, "ubuntu", "1", nil, ""),
+
+		Entry("profile empty list", []api.MachineImages{}, "ubuntu", "1", nil, ""),
+		Entry("profile entry not found (image does not exist)", makeProfileMachineImages("debian", "1"), "ubuntu", "1", nil, ""),
+		Entry("profile entry not found (version does not exist)", makeProfileMachineImages("ubuntu", "2"), "ubuntu", "1", nil, ""),
+		Entry("profile entry not found (architecture does not exist)", makeProfileMachineImages("ubuntu", "1"), "ubuntu", "1", ptr.To("arm64"), ""),
+		Entry("profile entry", makeProfileMachineImages("ubuntu", "1"), "ubuntu", "1", nil, profileImage),
+		Entry("profile entry (arm64)", append(makeProfileMachineImages("ubuntu", "1"), api.MachineImages{Name: "ubuntu", Versions: []api.MachineImageVersion{{Version: "1", ID: profileImageARM64, Architecture: ptr.To("arm64")}}}), "ubuntu", "1", ptr.To("arm64"), profileImageARM64),
+	)
+
+	Describe("#MachineTimeouts", func() {
+		var (
+			minutes = func(m int) *metav1.Duration {
+				return &metav1.Duration{Duration: time.Duration(m) * time.Minute}
+			}
+
+			cloudProfileConfig = &api.CloudProfileConfig{
+				MachineTimeouts: []api.MachineTimeouts{
+					{PlanFamilies: []string{"n3"}, MachineImages: []string{"flatcar"}, CreationTimeout: minutes(40)},
+					{PlanFamilies: []string{"m3", "n3"}, CreationTimeout: minutes(30), HealthTimeout: minutes(20)},
+					{MachineImages: []string{"flatcar"}, DrainTimeout: minutes(5)},
+				},
+			}
+		)
+
+		It("should return no timeouts without a cloud profile config", func() {
+			Expect(MachineTimeouts(nil, "n3.xlarge.x86", "flatcar")).To(BeZero())
+		})
+
+		It("should return the timeouts of the first matching entries", func() {
+			Expect(MachineTimeouts(cloudProfileConfig, "n3.xlarge.x86", "flatcar")).To(Equal(api.MachineTimeouts{
+				CreationTimeout: minutes(40),
+				HealthTimeout:   minutes(20),
+				DrainTimeout:    minutes(5),
+			}))
+			Expect(MachineTimeouts(cloudProfileConfig, "m3.large.x86", "ubuntu")).To(Equal(api.MachineTimeouts{
+				CreationTimeout: minutes(30),
+				HealthTimeout:   minutes(20),
+			}))
+			Expect(MachineTimeouts(cloudProfileConfig, "c3.small.x86", "ubuntu")).To(BeZero())
+		})
+	})
+
+	DescribeTable("#HibernationMode",
+		func(controlPlaneConfig *api.ControlPlaneConfig, workerConfig *api.WorkerConfig, expected string) {
+			Expect(HibernationMode(controlPlaneConfig, workerConfig)).To(Equal(expected))
+		},
 
-		Entry("profile empty list", []api.MachineImages{}, "ubuntu", "1", ""),
-		Entry("profile entry not found (image does not exist)", makeProfileMachineImages("debian", "1"), "ubuntu", "1", ""),
-		Entry("profile entry not found (version does not exist)", makeProfileMachineImages("ubuntu", "2"), "ubuntu", "1", ""),
-		Entry("profile entry", makeProfileMachineImages("ubuntu", "1"), "ubuntu", "1", profileImage),
+		Entry("no configs", nil, nil, api.HibernationModeDelete),
+		Entry("no hibernation modes", &api.ControlPlaneConfig{}, &api.WorkerConfig{}, api.HibernationModeDelete),
+		Entry("control plane config", &api.ControlPlaneConfig{HibernationMode: ptr.To(api.HibernationModePowerOff)}, &api.WorkerConfig{}, api.HibernationModePowerOff),
+		Entry("worker config overrides control plane config", &api.ControlPlaneConfig{HibernationMode: ptr.To(api.HibernationModePowerOff)}, &api.WorkerConfig{HibernationMode: ptr.To(api.HibernationModeDelete)}, api.HibernationModeDelete),
 	)
+
+	Describe("#RenderIPXEScriptURL", func() {
+		values := IPXETemplateValues{
+			ShootName:  "foo",
+			Pool:       "pool 1",
+			Metro:      "ny",
+			KernelArgs: "console=ttyS1,115200n8 foo=a+b&c",
+		}
+
+		It("should percent-encode the values in path segments and query parameters", func() {
+			Expect(RenderIPXEScriptURL("https://example.com/{{ .ShootName }}/{{ .Pool }}.ipxe?metro={{ .Metro }}&args={{ .KernelArgs }}", values)).To(Equal(
+				"https://example.com/foo/pool%201.ipxe?metro=ny&args=console%3DttyS1%2C115200n8%20foo%3Da%2Bb%26c"))
+		})
+
+		It("should fail for unknown template variables", func() {
+			_, err := RenderIPXEScriptURL("https://example.com/{{ .Unknown }}", values)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("#RenderIPXEScript", func() {
+		It("should not escape the values", func() {
+			Expect(RenderIPXEScript("#!ipxe\nkernel https://example.com/vmlinuz {{ .KernelArgs }}\n", IPXETemplateValues{
+				KernelArgs: "console=ttyS1,115200n8 foo=bar",
+			})).To(Equal("#!ipxe\nkernel https://example.com/vmlinuz console=ttyS1,115200n8 foo=bar\n"))
+		})
+	})
 })
 
 func makeProfileMachineImages(name, version string) []api.MachineImages {
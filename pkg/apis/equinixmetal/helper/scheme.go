@@ -56,3 +56,27 @@ func CloudProfileConfigFromCluster(cluster *controller.Cluster) (*api.CloudProfi
 	}
 	return cloudProfileConfig, nil
 }
+
+// WorkerConfigFromRawExtension decodes the provider specific worker configuration of a worker pool. An empty
+// configuration is returned if the given raw extension is not set.
+func WorkerConfigFromRawExtension(raw *runtime.RawExtension) (*api.WorkerConfig, error) {
+	workerConfig := &api.WorkerConfig{}
+	if raw != nil && raw.Raw != nil {
+		if _, _, err := decoder.Decode(raw.Raw, nil, workerConfig); err != nil {
+			return nil, errors.Wrapf(err, "could not decode providerConfig of worker pool")
+		}
+	}
+	return workerConfig, nil
+}
+
+// ControlPlaneConfigFromCluster decodes the provider specific control plane configuration of the shoot of a cluster.
+// An empty configuration is returned if the shoot has no control plane configuration.
+func ControlPlaneConfigFromCluster(cluster *controller.Cluster) (*api.ControlPlaneConfig, error) {
+	controlPlaneConfig := &api.ControlPlaneConfig{}
+	if cluster != nil && cluster.Shoot != nil && cluster.Shoot.Spec.Provider.ControlPlaneConfig != nil && cluster.Shoot.Spec.Provider.ControlPlaneConfig.Raw != nil {
+		if _, _, err := decoder.Decode(cluster.Shoot.Spec.Provider.ControlPlaneConfig.Raw, nil, controlPlaneConfig); err != nil {
+			return nil, errors.Wrapf(err, "could not decode controlPlaneConfig of shoot '%s'", cluster.Shoot.Name)
+		}
+	}
+	return controlPlaneConfig, nil
+}
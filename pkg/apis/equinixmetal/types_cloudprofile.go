@@ -17,6 +17,25 @@ type CloudProfileConfig struct {
 	// MachineImages is the list of machine images that are understood by the controller. It maps
 	// logical names and versions to provider-specific identifiers.
 	MachineImages []MachineImages
+	// MachineTimeouts is a list of default timeouts of the machines of worker pools which do not configure them. For
+	// every timeout, the first entry matching the plan and the machine image of a worker pool which sets the timeout
+	// is used.
+	MachineTimeouts []MachineTimeouts
+}
+
+// MachineTimeouts contains default timeouts of the machines of worker pools with matching plans and machine images.
+type MachineTimeouts struct {
+	// PlanFamilies is a list of plan families, i.e., the parts of the plans before the first dot (e.g., `m3` for
+	// `m3.large.x86`). If it is empty, the entry matches all plans.
+	PlanFamilies []string
+	// MachineImages is a list of names of machine images. If it is empty, the entry matches all machine images.
+	MachineImages []string
+	// CreationTimeout is the timeout after which the creation of a machine is declared failed.
+	CreationTimeout *metav1.Duration
+	// HealthTimeout is the timeout after which a machine is declared unhealthy.
+	HealthTimeout *metav1.Duration
+	// DrainTimeout is the timeout after which a machine is forcefully deleted.
+	DrainTimeout *metav1.Duration
 }
 
 // MachineImages is a mapping from logical names and versions to provider-specific identifiers.
@@ -35,4 +54,6 @@ type MachineImageVersion struct {
 	ID string
 	// IPXEScriptURL is url to point to a IPXE script.
 	IPXEScriptURL string
+	// Architecture is the CPU architecture of the machine image. Defaults to amd64.
+	Architecture *string
 }
@@ -13,4 +13,8 @@ import (
 // ControlPlaneConfig contains configuration settings for the control plane.
 type ControlPlaneConfig struct {
 	metav1.TypeMeta
+
+	// HibernationMode is the mode in which the worker pools of the shoot are hibernated, either `Delete` or
+	// `PowerOff`. It can be overridden per worker pool. Defaults to `Delete`.
+	HibernationMode *string
 }
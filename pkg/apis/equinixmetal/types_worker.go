@@ -5,7 +5,92 @@
 package equinixmetal
 
 import (
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// BillingCycleHourly is the hourly billing cycle of devices.
+	BillingCycleHourly = "hourly"
+	// BillingCycleDaily is the daily billing cycle of devices.
+	BillingCycleDaily = "daily"
+	// BillingCycleMonthly is the monthly billing cycle of devices.
+	BillingCycleMonthly = "monthly"
+	// BillingCycleYearly is the yearly billing cycle of devices.
+	BillingCycleYearly = "yearly"
+)
+
+// CustomDataKeyGardener is the key of the custom data of devices which is reserved for data passed by the extension.
+const CustomDataKeyGardener = "gardener"
+
+const (
+	// NetworkTypeLayer3 is the network type of devices with bonded layer 3 ports.
+	NetworkTypeLayer3 = "layer3"
+	// NetworkTypeHybrid is the network type of devices with a layer 3 port and an unbonded layer 2 port.
+	NetworkTypeHybrid = "hybrid"
+	// NetworkTypeHybridBonded is the network type of devices with bonded ports which are both layer 3 and layer 2.
+	NetworkTypeHybridBonded = "hybrid-bonded"
+	// NetworkTypeLayer2Bonded is the network type of devices with bonded layer 2 ports.
+	NetworkTypeLayer2Bonded = "layer2-bonded"
+	// NetworkTypeLayer2Individual is the network type of devices with unbonded layer 2 ports.
+	NetworkTypeLayer2Individual = "layer2-individual"
+)
+
+const (
+	// VolumeRAIDLevelRAID0 is the RAID level of logical volumes which are striped across the disks.
+	VolumeRAIDLevelRAID0 = "raid0"
+	// VolumeRAIDLevelRAID1 is the RAID level of logical volumes which are mirrored across the disks.
+	VolumeRAIDLevelRAID1 = "raid1"
+	// VolumeFilesystemExt4 is the ext4 filesystem of logical volumes.
+	VolumeFilesystemExt4 = "ext4"
+	// VolumeFilesystemXFS is the xfs filesystem of logical volumes.
+	VolumeFilesystemXFS = "xfs"
+)
+
+const (
+	// DiskTypeNVMe is the type of NVMe disks.
+	DiskTypeNVMe = "nvme"
+	// DiskTypeSSD is the type of non-rotational disks which are not NVMe disks.
+	DiskTypeSSD = "ssd"
+	// DiskTypeHDD is the type of rotational disks.
+	DiskTypeHDD = "hdd"
+)
+
+const (
+	// LogicalVolumeContainerd is the name of the logical volume mounted at `/var/lib/containerd`.
+	LogicalVolumeContainerd = "containerd"
+	// LogicalVolumeKubelet is the name of the logical volume mounted at `/var/lib/kubelet`.
+	LogicalVolumeKubelet = "kubelet"
+	// LogicalVolumeLog is the name of the logical volume mounted at `/var/log`.
+	LogicalVolumeLog = "log"
+)
+
+const (
+	// UpdateStrategyRollingUpdate is the update strategy of machine deployments which create new machines before old
+	// machines are deleted.
+	UpdateStrategyRollingUpdate = "RollingUpdate"
+	// UpdateStrategyDeleteBeforeCreate is the update strategy of machine deployments which delete old machines before
+	// new machines are created.
+	UpdateStrategyDeleteBeforeCreate = "DeleteBeforeCreate"
+)
+
+const (
+	// HibernationModeDelete is the hibernation mode of worker pools whose devices are deleted while the shoot is
+	// hibernated.
+	HibernationModeDelete = "Delete"
+	// HibernationModePowerOff is the hibernation mode of worker pools whose devices are powered off and kept while the
+	// shoot is hibernated.
+	HibernationModePowerOff = "PowerOff"
+)
+
+const (
+	// AdoptionBootstrapReinstall is the bootstrap method of adopted devices which are reinstalled with the machine image
+	// and the user data of their worker pool.
+	AdoptionBootstrapReinstall = "Reinstall"
+	// AdoptionBootstrapUserData is the bootstrap method of adopted devices whose user data is replaced by the user data
+	// of their worker pool before they are rebooted.
+	AdoptionBootstrapUserData = "UserData"
 )
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -16,10 +101,208 @@ type WorkerConfig struct {
 
 	// ReservationIDs is the list of IDs of reserved devices.
 	ReservationIDs []string
+	// ReservationSelector selects hardware reservations of the project which should be used for the machines of
+	// this worker pool in addition to the ones listed in ReservationIDs. The selected reservations are resolved on
+	// every reconciliation.
+	ReservationSelector *ReservationSelector
 	// ReservedDevicesOnly indicates whether only reserved devices should be used (based on the list of reservation IDs) when
 	// new machines are created. If false and the list of reservation IDs is exhausted then the next available device
 	// (unreserved) will be used. Default: false
 	ReservedDevicesOnly *bool
+	// OnDemandOverflow splits the worker pool into a machine deployment which only uses the hardware reservations of
+	// the worker pool and is sized to their number, and an overflow machine deployment for on-demand devices. The
+	// overflow machine deployment has a lower priority for the cluster-autoscaler, so that reserved devices are
+	// preferred. It cannot be combined with ReservedDevicesOnly.
+	OnDemandOverflow *bool
+	// BillingCycle is the billing cycle of the machines of this worker pool. Possible values are `hourly`, `daily`,
+	// `monthly` and `yearly`. Default: hourly
+	BillingCycle *string
+	// Tags is a list of additional tags which are added to the devices of this worker pool.
+	Tags []string
+	// TagsFromPoolLabels is a list of label keys of this worker pool. For each of them, a tag `<key>=<value>` is added
+	// to the devices of this worker pool.
+	TagsFromPoolLabels []string
+	// CustomData is arbitrary JSON data which is passed to the devices of this worker pool and can be read from the
+	// metadata service.
+	CustomData *runtime.RawExtension
+	// Network contains the network configuration of the devices of this worker pool.
+	Network *WorkerNetwork
+	// Storage is a custom storage layout of the devices of this worker pool.
+	Storage *Storage
+	// VolumeLayout is the layout of the local disks of the devices of this worker pool.
+	VolumeLayout *VolumeLayout
+	// SpotInstance indicates whether the machines of this worker pool should be spot market devices.
+	SpotInstance *bool
+	// SpotPriceMax is the maximum price (in US dollars per hour) to bid for spot market devices.
+	SpotPriceMax *string
+	// IPXE contains an iPXE configuration overriding the one of the machine image for the machines of this worker pool.
+	// It is only allowed if the machine image boots via custom iPXE.
+	IPXE *IPXE
+	// SSH contains additional SSH keys which are added to the devices of this worker pool. They are not added if SSH
+	// access to the nodes is disabled for the shoot.
+	SSH *WorkerSSH
+	// FallbackMachineTypes is an ordered list of plans which are used for new machines of this worker pool if the
+	// capacity of its machine type is unavailable in the metro of the shoot.
+	FallbackMachineTypes []string
+	// HibernationMode is the mode in which this worker pool is hibernated, either `Delete` or `PowerOff`. Defaults to
+	// the hibernation mode of the ControlPlaneConfig of the shoot.
+	HibernationMode *string
+	// AdoptDevices selects existing devices of the project which are adopted by this worker pool.
+	AdoptDevices *DeviceAdoption
+}
+
+// DeviceAdoption selects existing devices which are adopted by a worker pool.
+type DeviceAdoption struct {
+	// DeviceIDs is the list of IDs of the devices to adopt.
+	DeviceIDs []string
+	// Tags selects the devices of the project which have all of the given tags.
+	Tags []string
+	// Bootstrap is the method with which the adopted devices join the cluster, either `Reinstall` or `UserData`.
+	// Defaults to `Reinstall`.
+	Bootstrap *string
+}
+
+// WorkerSSH contains SSH keys which are added to the devices of a worker pool in addition to the key managed by
+// Gardener.
+type WorkerSSH struct {
+	// ProjectKeyIDs is a list of IDs of SSH keys of the project.
+	ProjectKeyIDs []string
+	// UserKeyIDs is a list of IDs of personal SSH keys of users.
+	UserKeyIDs []string
+	// AllProjectKeys indicates whether all SSH keys of the project should be added.
+	AllProjectKeys *bool
+}
+
+// ReservationSelector selects hardware reservations of the project. A hardware reservation is selected if it
+// matches all given criteria, each criterion matches if the reservation has one of the listed values.
+type ReservationSelector struct {
+	// Plans is a list of plans of the hardware reservations. Defaults to the machine type of the worker pool.
+	Plans []string
+	// Facilities is a list of facility codes of the hardware reservations.
+	Facilities []string
+	// Metros is a list of metro codes of the hardware reservations.
+	Metros []string
+	// Tags is a list of custom tags of the hardware reservations.
+	Tags []string
+}
+
+// WorkerNetwork contains the network configuration of the devices of a worker pool.
+type WorkerNetwork struct {
+	// Type is the network type of the devices. Possible values are `layer3`, `hybrid`, `hybrid-bonded`,
+	// `layer2-bonded` and `layer2-individual`. Default: layer3
+	Type *string
+	// VLANs is the list of VLANs which are attached to the devices. VLANs can only be attached if the network type is
+	// not `layer3`.
+	VLANs []VLAN
+}
+
+// VLAN is a VLAN which is attached to the devices of a worker pool.
+type VLAN struct {
+	// Name is the name of the VLAN. VLANs without an ID are managed by the extension, i.e., they are created in the
+	// metro of the shoot and deleted together with its workers. Worker pools using the same name share the VLAN.
+	Name string
+	// ID is the VLAN ID (VXLAN) of an existing VLAN in the metro of the shoot.
+	ID *int32
+}
+
+// Storage is a custom storage layout of devices, see
+// https://deploy.equinix.com/developers/docs/metal/storage/custom-partitioning-raid/.
+type Storage struct {
+	// Disks is the list of disks and their partitions.
+	Disks []StorageDisk
+	// RAID is the list of software RAID arrays.
+	RAID []StorageRAID
+	// Filesystems is the list of filesystems. The layout must contain a filesystem mounted at `/`.
+	Filesystems []StorageFilesystem
+}
+
+// StorageDisk is a disk of a custom storage layout.
+type StorageDisk struct {
+	// Device is the path of the disk, e.g. `/dev/sda`.
+	Device string
+	// WipeTable indicates whether the partition table of the disk should be wiped.
+	WipeTable *bool
+	// Partitions is the list of partitions of the disk.
+	Partitions []StoragePartition
+}
+
+// StoragePartition is a partition of a disk of a custom storage layout.
+type StoragePartition struct {
+	// Label is the label of the partition.
+	Label string
+	// Number is the number of the partition.
+	Number int32
+	// Size is the size of the partition, e.g. `512MB`. A size of `0` uses the remaining space of the disk.
+	Size string
+}
+
+// StorageRAID is a software RAID array of a custom storage layout.
+type StorageRAID struct {
+	// Name is the path of the RAID array, e.g. `/dev/md/ROOT`.
+	Name string
+	// Level is the RAID level. Possible values are `raid0`, `raid1`, `raid5`, `raid6` and `raid10`.
+	Level string
+	// Devices is the list of partitions of the RAID array, e.g. `/dev/sda2`.
+	Devices []string
+}
+
+// StorageFilesystem is a filesystem of a custom storage layout.
+type StorageFilesystem struct {
+	// Device is the path of the partition or RAID array of the filesystem.
+	Device string
+	// Format is the format of the filesystem. Possible values are `ext4`, `xfs`, `vfat` and `swap`.
+	Format string
+	// Point is the mount point of the filesystem. It is not used for `swap` filesystems.
+	Point *string
+	// Options is the list of mount options of the filesystem.
+	Options []string
+}
+
+// VolumeLayout is the layout of the local disks of devices. The selected disks are combined into an LVM volume group
+// which contains separate logical volumes, e.g. for containerd, the kubelet and the logs.
+type VolumeLayout struct {
+	// Disks selects the local disks of the volume group. By default, all unused disks are selected.
+	Disks *DiskSelector
+	// RAIDLevel is the RAID level of the logical volumes. Possible values are `raid0` and `raid1`. By default, the
+	// logical volumes are linear.
+	RAIDLevel *string
+	// Filesystem is the filesystem of the logical volumes. Possible values are `ext4` and `xfs`. Default: ext4
+	Filesystem *string
+	// Volumes is the list of logical volumes. By default, a single logical volume for containerd is created.
+	Volumes []LogicalVolume
+}
+
+// DiskSelector selects local disks of devices. Disks which are partitioned or contain a filesystem are never selected.
+type DiskSelector struct {
+	// Types is the list of disk types. Possible values are `nvme`, `ssd` and `hdd`.
+	Types []string
+	// MinSize is the minimum size of the disks.
+	MinSize *resource.Quantity
+	// MaxSize is the maximum size of the disks.
+	MaxSize *resource.Quantity
+}
+
+// LogicalVolume is a logical volume of a volume layout.
+type LogicalVolume struct {
+	// Name is the name of the logical volume which determines its mount point. Possible values are `containerd`
+	// (`/var/lib/containerd`), `kubelet` (`/var/lib/kubelet`) and `log` (`/var/log`).
+	Name string
+	// Size is the size of the logical volume. The logical volume without a size uses the remaining space of the
+	// volume group.
+	Size *resource.Quantity
+}
+
+// IPXE contains an iPXE configuration for the machines of a worker pool. The script URL and the inline script may
+// reference the template variables `{{ .ShootName }}`, `{{ .Pool }}`, `{{ .Metro }}` and `{{ .KernelArgs }}`.
+type IPXE struct {
+	// ScriptURL is the URL of the iPXE script.
+	ScriptURL *string
+	// Script is an inline iPXE script.
+	Script *string
+	// KernelArgs is a list of additional kernel arguments which can be referenced by the script (URL).
+	KernelArgs []string
+	// AlwaysPXE indicates whether the machines should boot via iPXE on every boot and not only during provisioning.
+	AlwaysPXE *bool
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -34,6 +317,118 @@ type WorkerStatus struct {
 	// resources that are still using this version. Hence, it stores the used versions in the provider status to ensure
 	// reconciliation is possible.
 	MachineImages []MachineImage
+	// WorkerPools contains status information about the worker pools.
+	WorkerPools []WorkerPoolStatus
+	// Machines contains information about the devices of the machines of the worker.
+	Machines []MachineStatus
+	// HibernatedDevices is the list of devices which are powered off while the shoot is hibernated. They are powered
+	// on and adopted by new machines when the shoot wakes up.
+	HibernatedDevices []HibernatedDevice
+	// AdoptedDevices is the list of existing devices which have been adopted by the worker pools. Devices are only
+	// adopted once, i.e., they are not adopted again after their machines have been deleted.
+	AdoptedDevices []AdoptedDevice
+}
+
+// AdoptedDevice is an existing device which has been adopted by a worker pool.
+type AdoptedDevice struct {
+	// Name is the name of the machine which adopted the device.
+	Name string
+	// Pool is the name of the worker pool of the device.
+	Pool string
+	// DeviceID is the ID of the device.
+	DeviceID string
+}
+
+// HibernatedDevice is a device which is powered off while the shoot is hibernated.
+type HibernatedDevice struct {
+	// Name is the name of the machine of the device.
+	Name string
+	// Node is the name of the node of the device.
+	Node string
+	// Pool is the name of the worker pool of the device.
+	Pool string
+	// MachineDeployment is the name of the machine deployment of the machine of the device.
+	MachineDeployment string
+	// DeviceID is the ID of the device.
+	DeviceID string
+}
+
+// MachineStatus contains information about the device of a machine.
+type MachineStatus struct {
+	// Name is the name of the machine.
+	Name string
+	// Node is the name of the node of the machine.
+	Node string
+	// Pool is the name of the worker pool of the machine.
+	Pool string
+	// DeviceID is the ID of the device.
+	DeviceID string
+	// Hostname is the hostname of the device.
+	Hostname string
+	// Facility is the code of the facility the device is located in.
+	Facility string
+	// Plan is the slug of the plan of the device.
+	Plan string
+	// ReservationID is the ID of the hardware reservation used by the device.
+	ReservationID *string
+	// PublicIPs is the list of public IP addresses of the device.
+	PublicIPs []string
+	// PrivateIPs is the list of private IP addresses of the device.
+	PrivateIPs []string
+	// State is the provisioning state of the device.
+	State string
+	// CreatedAt is the creation time of the device.
+	CreatedAt *metav1.Time
+}
+
+// WorkerPoolStatus contains status information about a worker pool.
+type WorkerPoolStatus struct {
+	// Name is the name of the worker pool.
+	Name string
+	// ReservationIDs is the list of IDs of the hardware reservations which have been selected by the reservation
+	// selector of the worker pool.
+	ReservationIDs []string
+	// Reservations reports the usage of the hardware reservations of the worker pool.
+	Reservations *ReservationReport
+	// MachineType is the machine type which is used for new machines of the worker pool. It is only reported for
+	// worker pools with fallback machine types.
+	MachineType string
+	// UpdateStrategies reports the update strategies of the machine deployments of the worker pool which only use
+	// reserved devices. They are adjusted to the free hardware reservations of the machine deployments.
+	UpdateStrategies []UpdateStrategyStatus
+	// HibernationMode is the mode in which the worker pool is hibernated. It is only reported for worker pools whose
+	// devices are powered off while the shoot is hibernated.
+	HibernationMode string
+	// AlwaysPXE indicates whether the devices of the worker pool have been configured to boot via iPXE on every boot.
+	// It is kept after `alwaysPXE` has been removed from the worker pool until its devices have been reset.
+	AlwaysPXE bool
+}
+
+// UpdateStrategyStatus reports the update strategy of a machine deployment which only uses reserved devices.
+type UpdateStrategyStatus struct {
+	// Name is the name of the machine deployment.
+	Name string
+	// Type is the type of the update strategy, either `RollingUpdate` or `DeleteBeforeCreate`.
+	Type string
+	// FreeReservations is the number of free hardware reservations of the machine deployment.
+	FreeReservations int32
+	// MaxSurge is the maximum number of machines which can be created above the desired number of machines during an
+	// update.
+	MaxSurge int32
+	// MaxUnavailable is the maximum number of machines which can be unavailable during an update.
+	MaxUnavailable int32
+}
+
+// ReservationReport reports the usage of the hardware reservations of a worker pool.
+type ReservationReport struct {
+	// Total is the number of existing hardware reservations of the worker pool, spare reservations are only counted if they are in use by the worker pool.
+	Total int32
+	// InUse is the list of IDs of the hardware reservations which are used by devices of the worker pool.
+	InUse []string
+	// Free is the list of IDs of the hardware reservations which are neither used by a device nor spare.
+	Free []string
+	// OnDemandDevices is the number of devices of the worker pool which do not use a hardware reservation.
+	OnDemandDevices int32
 }
 
 // MachineImage is a mapping from logical names and versions to provider-specific machine image data.
@@ -46,4 +441,6 @@ type MachineImage struct {
 	ID string
 	// IPXEScriptURL is url to point to a IPXE script.
 	IPXEScriptURL string
+	// Architecture is the CPU architecture of the machine image.
+	Architecture *string
 }
@@ -18,6 +18,31 @@ type CloudProfileConfig struct {
 	// MachineImages is the list of machine images that are understood by the controller. It maps
 	// logical names and versions to provider-specific identifiers.
 	MachineImages []MachineImages `json:"machineImages"`
+	// MachineTimeouts is a list of default timeouts of the machines of worker pools which do not configure them. For
+	// every timeout, the first entry matching the plan and the machine image of a worker pool which sets the timeout
+	// is used.
+	// +optional
+	MachineTimeouts []MachineTimeouts `json:"machineTimeouts,omitempty"`
+}
+
+// MachineTimeouts contains default timeouts of the machines of worker pools with matching plans and machine images.
+type MachineTimeouts struct {
+	// PlanFamilies is a list of plan families, i.e., the parts of the plans before the first dot (e.g., `m3` for
+	// `m3.large.x86`). If it is empty, the entry matches all plans.
+	// +optional
+	PlanFamilies []string `json:"planFamilies,omitempty"`
+	// MachineImages is a list of names of machine images. If it is empty, the entry matches all machine images.
+	// +optional
+	MachineImages []string `json:"machineImages,omitempty"`
+	// CreationTimeout is the timeout after which the creation of a machine is declared failed.
+	// +optional
+	CreationTimeout *metav1.Duration `json:"creationTimeout,omitempty"`
+	// HealthTimeout is the timeout after which a machine is declared unhealthy.
+	// +optional
+	HealthTimeout *metav1.Duration `json:"healthTimeout,omitempty"`
+	// DrainTimeout is the timeout after which a machine is forcefully deleted.
+	// +optional
+	DrainTimeout *metav1.Duration `json:"drainTimeout,omitempty"`
 }
 
 // MachineImages is a mapping from logical names and versions to provider-specific identifiers.
@@ -38,4 +63,7 @@ type MachineImageVersion struct {
 	// IPXEScriptURL is url to point to a IPXE script.
 	// +optional
 	IPXEScriptURL string `json:"ipxeScriptUrl,omitempty"`
+	// Architecture is the CPU architecture of the machine image. Defaults to amd64.
+	// +optional
+	Architecture *string `json:"architecture,omitempty"`
 }
@@ -14,4 +14,9 @@ import (
 // ControlPlaneConfig contains configuration settings for the control plane.
 type ControlPlaneConfig struct {
 	metav1.TypeMeta `json:",inline"`
+
+	// HibernationMode is the mode in which the worker pools of the shoot are hibernated, either `Delete` or
+	// `PowerOff`. It can be overridden per worker pool. Defaults to `Delete`.
+	// +optional
+	HibernationMode *string `json:"hibernationMode,omitempty"`
 }
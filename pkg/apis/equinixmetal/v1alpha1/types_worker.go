@@ -5,7 +5,9 @@
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // +genclient
@@ -18,11 +20,256 @@ type WorkerConfig struct {
 	// ReservationIDs is the list of IDs of reserved devices.
 	// +optional
 	ReservationIDs []string `json:"reservationIDs,omitempty"`
+	// ReservationSelector selects hardware reservations of the project which should be used for the machines of
+	// this worker pool in addition to the ones listed in ReservationIDs. The selected reservations are resolved on
+	// every reconciliation.
+	// +optional
+	ReservationSelector *ReservationSelector `json:"reservationSelector,omitempty"`
 	// ReservedDevicesOnly indicates whether only reserved devices should be used (based on the list of reservation IDs) when
 	// new machines are created. If false and the list of reservation IDs is exhausted then the next available device
 	// (unreserved) will be used. Default: false
 	// +optional.
 	ReservedDevicesOnly *bool `json:"reservedDevicesOnly,omitempty"`
+	// OnDemandOverflow splits the worker pool into a machine deployment which only uses the hardware reservations of
+	// the worker pool and is sized to their number, and an overflow machine deployment for on-demand devices. The
+	// overflow machine deployment has a lower priority for the cluster-autoscaler, so that reserved devices are
+	// preferred. It cannot be combined with ReservedDevicesOnly.
+	// +optional
+	OnDemandOverflow *bool `json:"onDemandOverflow,omitempty"`
+	// BillingCycle is the billing cycle of the machines of this worker pool. Possible values are `hourly`, `daily`,
+	// `monthly` and `yearly`. Default: hourly
+	// +optional
+	BillingCycle *string `json:"billingCycle,omitempty"`
+	// Tags is a list of additional tags which are added to the devices of this worker pool.
+	// +optional
+	Tags []string `json:"tags,omitempty"`
+	// TagsFromPoolLabels is a list of label keys of this worker pool. For each of them, a tag `<key>=<value>` is added
+	// to the devices of this worker pool.
+	// +optional
+	TagsFromPoolLabels []string `json:"tagsFromPoolLabels,omitempty"`
+	// CustomData is arbitrary JSON data which is passed to the devices of this worker pool and can be read from the
+	// metadata service.
+	// +optional
+	CustomData *runtime.RawExtension `json:"customData,omitempty"`
+	// Network contains the network configuration of the devices of this worker pool.
+	// +optional
+	Network *WorkerNetwork `json:"network,omitempty"`
+	// Storage is a custom storage layout of the devices of this worker pool.
+	// +optional
+	Storage *Storage `json:"storage,omitempty"`
+	// VolumeLayout is the layout of the local disks of the devices of this worker pool.
+	// +optional
+	VolumeLayout *VolumeLayout `json:"volumeLayout,omitempty"`
+	// SpotInstance indicates whether the machines of this worker pool should be spot market devices.
+	// +optional
+	SpotInstance *bool `json:"spotInstance,omitempty"`
+	// SpotPriceMax is the maximum price (in US dollars per hour) to bid for spot market devices.
+	// +optional
+	SpotPriceMax *string `json:"spotPriceMax,omitempty"`
+	// IPXE contains an iPXE configuration overriding the one of the machine image for the machines of this worker pool.
+	// It is only allowed if the machine image boots via custom iPXE.
+	// +optional
+	IPXE *IPXE `json:"ipxe,omitempty"`
+	// SSH contains additional SSH keys which are added to the devices of this worker pool. They are not added if SSH
+	// access to the nodes is disabled for the shoot.
+	// +optional
+	SSH *WorkerSSH `json:"ssh,omitempty"`
+	// FallbackMachineTypes is an ordered list of plans which are used for new machines of this worker pool if the
+	// capacity of its machine type is unavailable in the metro of the shoot.
+	// +optional
+	FallbackMachineTypes []string `json:"fallbackMachineTypes,omitempty"`
+	// HibernationMode is the mode in which this worker pool is hibernated, either `Delete` or `PowerOff`. Defaults to
+	// the hibernation mode of the ControlPlaneConfig of the shoot.
+	// +optional
+	HibernationMode *string `json:"hibernationMode,omitempty"`
+	// AdoptDevices selects existing devices of the project which are adopted by this worker pool.
+	// +optional
+	AdoptDevices *DeviceAdoption `json:"adoptDevices,omitempty"`
+}
+
+// DeviceAdoption selects existing devices which are adopted by a worker pool.
+type DeviceAdoption struct {
+	// DeviceIDs is the list of IDs of the devices to adopt.
+	// +optional
+	DeviceIDs []string `json:"deviceIDs,omitempty"`
+	// Tags selects the devices of the project which have all of the given tags.
+	// +optional
+	Tags []string `json:"tags,omitempty"`
+	// Bootstrap is the method with which the adopted devices join the cluster, either `Reinstall` or `UserData`.
+	// Defaults to `Reinstall`.
+	// +optional
+	Bootstrap *string `json:"bootstrap,omitempty"`
+}
+
+// WorkerSSH contains SSH keys which are added to the devices of a worker pool in addition to the key managed by
+// Gardener.
+type WorkerSSH struct {
+	// ProjectKeyIDs is a list of IDs of SSH keys of the project.
+	// +optional
+	ProjectKeyIDs []string `json:"projectKeyIDs,omitempty"`
+	// UserKeyIDs is a list of IDs of personal SSH keys of users.
+	// +optional
+	UserKeyIDs []string `json:"userKeyIDs,omitempty"`
+	// AllProjectKeys indicates whether all SSH keys of the project should be added.
+	// +optional
+	AllProjectKeys *bool `json:"allProjectKeys,omitempty"`
+}
+
+// ReservationSelector selects hardware reservations of the project. A hardware reservation is selected if it
+// matches all given criteria, each criterion matches if the reservation has one of the listed values.
+type ReservationSelector struct {
+	// Plans is a list of plans of the hardware reservations. Defaults to the machine type of the worker pool.
+	// +optional
+	Plans []string `json:"plans,omitempty"`
+	// Facilities is a list of facility codes of the hardware reservations.
+	// +optional
+	Facilities []string `json:"facilities,omitempty"`
+	// Metros is a list of metro codes of the hardware reservations.
+	// +optional
+	Metros []string `json:"metros,omitempty"`
+	// Tags is a list of custom tags of the hardware reservations.
+	// +optional
+	Tags []string `json:"tags,omitempty"`
+}
+
+// WorkerNetwork contains the network configuration of the devices of a worker pool.
+type WorkerNetwork struct {
+	// Type is the network type of the devices. Possible values are `layer3`, `hybrid`, `hybrid-bonded`,
+	// `layer2-bonded` and `layer2-individual`. Default: layer3
+	// +optional
+	Type *string `json:"type,omitempty"`
+	// VLANs is the list of VLANs which are attached to the devices. VLANs can only be attached if the network type is
+	// not `layer3`.
+	// +optional
+	VLANs []VLAN `json:"vlans,omitempty"`
+}
+
+// VLAN is a VLAN which is attached to the devices of a worker pool.
+type VLAN struct {
+	// Name is the name of the VLAN. VLANs without an ID are managed by the extension, i.e., they are created in the
+	// metro of the shoot and deleted together with its workers. Worker pools using the same name share the VLAN.
+	Name string `json:"name"`
+	// ID is the VLAN ID (VXLAN) of an existing VLAN in the metro of the shoot.
+	// +optional
+	ID *int32 `json:"id,omitempty"`
+}
+
+// Storage is a custom storage layout of devices, see
+// https://deploy.equinix.com/developers/docs/metal/storage/custom-partitioning-raid/.
+type Storage struct {
+	// Disks is the list of disks and their partitions.
+	// +optional
+	Disks []StorageDisk `json:"disks,omitempty"`
+	// RAID is the list of software RAID arrays.
+	// +optional
+	RAID []StorageRAID `json:"raid,omitempty"`
+	// Filesystems is the list of filesystems. The layout must contain a filesystem mounted at `/`.
+	Filesystems []StorageFilesystem `json:"filesystems"`
+}
+
+// StorageDisk is a disk of a custom storage layout.
+type StorageDisk struct {
+	// Device is the path of the disk, e.g. `/dev/sda`.
+	Device string `json:"device"`
+	// WipeTable indicates whether the partition table of the disk should be wiped.
+	// +optional
+	WipeTable *bool `json:"wipeTable,omitempty"`
+	// Partitions is the list of partitions of the disk.
+	// +optional
+	Partitions []StoragePartition `json:"partitions,omitempty"`
+}
+
+// StoragePartition is a partition of a disk of a custom storage layout.
+type StoragePartition struct {
+	// Label is the label of the partition.
+	Label string `json:"label"`
+	// Number is the number of the partition.
+	Number int32 `json:"number"`
+	// Size is the size of the partition, e.g. `512MB`. A size of `0` uses the remaining space of the disk.
+	Size string `json:"size"`
+}
+
+// StorageRAID is a software RAID array of a custom storage layout.
+type StorageRAID struct {
+	// Name is the path of the RAID array, e.g. `/dev/md/ROOT`.
+	Name string `json:"name"`
+	// Level is the RAID level. Possible values are `raid0`, `raid1`, `raid5`, `raid6` and `raid10`.
+	Level string `json:"level"`
+	// Devices is the list of partitions of the RAID array, e.g. `/dev/sda2`.
+	Devices []string `json:"devices"`
+}
+
+// StorageFilesystem is a filesystem of a custom storage layout.
+type StorageFilesystem struct {
+	// Device is the path of the partition or RAID array of the filesystem.
+	Device string `json:"device"`
+	// Format is the format of the filesystem. Possible values are `ext4`, `xfs`, `vfat` and `swap`.
+	Format string `json:"format"`
+	// Point is the mount point of the filesystem. It is not used for `swap` filesystems.
+	// +optional
+	Point *string `json:"point,omitempty"`
+	// Options is the list of mount options of the filesystem.
+	// +optional
+	Options []string `json:"options,omitempty"`
+}
+
+// VolumeLayout is the layout of the local disks of devices. The selected disks are combined into an LVM volume group
+// which contains separate logical volumes, e.g. for containerd, the kubelet and the logs.
+type VolumeLayout struct {
+	// Disks selects the local disks of the volume group. By default, all unused disks are selected.
+	// +optional
+	Disks *DiskSelector `json:"disks,omitempty"`
+	// RAIDLevel is the RAID level of the logical volumes. Possible values are `raid0` and `raid1`. By default, the
+	// logical volumes are linear.
+	// +optional
+	RAIDLevel *string `json:"raidLevel,omitempty"`
+	// Filesystem is the filesystem of the logical volumes. Possible values are `ext4` and `xfs`. Default: ext4
+	// +optional
+	Filesystem *string `json:"filesystem,omitempty"`
+	// Volumes is the list of logical volumes. By default, a single logical volume for containerd is created.
+	// +optional
+	Volumes []LogicalVolume `json:"volumes,omitempty"`
+}
+
+// DiskSelector selects local disks of devices. Disks which are partitioned or contain a filesystem are never selected.
+type DiskSelector struct {
+	// Types is the list of disk types. Possible values are `nvme`, `ssd` and `hdd`.
+	// +optional
+	Types []string `json:"types,omitempty"`
+	// MinSize is the minimum size of the disks.
+	// +optional
+	MinSize *resource.Quantity `json:"minSize,omitempty"`
+	// MaxSize is the maximum size of the disks.
+	// +optional
+	MaxSize *resource.Quantity `json:"maxSize,omitempty"`
+}
+
+// LogicalVolume is a logical volume of a volume layout.
+type LogicalVolume struct {
+	// Name is the name of the logical volume which determines its mount point. Possible values are `containerd`
+	// (`/var/lib/containerd`), `kubelet` (`/var/lib/kubelet`) and `log` (`/var/log`).
+	Name string `json:"name"`
+	// Size is the size of the logical volume. The logical volume without a size uses the remaining space of the
+	// volume group.
+	// +optional
+	Size *resource.Quantity `json:"size,omitempty"`
+}
+
+// IPXE contains an iPXE configuration for the machines of a worker pool. The script URL and the inline script may
+// reference the template variables `{{ .ShootName }}`, `{{ .Pool }}`, `{{ .Metro }}` and `{{ .KernelArgs }}`.
+type IPXE struct {
+	// ScriptURL is the URL of the iPXE script.
+	// +optional
+	ScriptURL *string `json:"scriptUrl,omitempty"`
+	// Script is an inline iPXE script.
+	// +optional
+	Script *string `json:"script,omitempty"`
+	// KernelArgs is a list of additional kernel arguments which can be referenced by the script (URL).
+	// +optional
+	KernelArgs []string `json:"kernelArgs,omitempty"`
+	// AlwaysPXE indicates whether the machines should boot via iPXE on every boot and not only during provisioning.
+	// +optional
+	AlwaysPXE *bool `json:"alwaysPXE,omitempty"`
 }
 
 // +genclient
@@ -39,6 +286,140 @@ type WorkerStatus struct {
 	// reconciliation is possible.
 	// +optional
 	MachineImages []MachineImage `json:"machineImages,omitempty"`
+	// WorkerPools contains status information about the worker pools.
+	// +optional
+	WorkerPools []WorkerPoolStatus `json:"workerPools,omitempty"`
+	// Machines contains information about the devices of the machines of the worker.
+	// +optional
+	Machines []MachineStatus `json:"machines,omitempty"`
+	// HibernatedDevices is the list of devices which are powered off while the shoot is hibernated. They are powered
+	// on and adopted by new machines when the shoot wakes up.
+	// +optional
+	HibernatedDevices []HibernatedDevice `json:"hibernatedDevices,omitempty"`
+	// AdoptedDevices is the list of existing devices which have been adopted by the worker pools. Devices are only
+	// adopted once, i.e., they are not adopted again after their machines have been deleted.
+	// +optional
+	AdoptedDevices []AdoptedDevice `json:"adoptedDevices,omitempty"`
+}
+
+// AdoptedDevice is an existing device which has been adopted by a worker pool.
+type AdoptedDevice struct {
+	// Name is the name of the machine which adopted the device.
+	Name string `json:"name"`
+	// Pool is the name of the worker pool of the device.
+	Pool string `json:"pool"`
+	// DeviceID is the ID of the device.
+	DeviceID string `json:"deviceID"`
+}
+
+// HibernatedDevice is a device which is powered off while the shoot is hibernated.
+type HibernatedDevice struct {
+	// Name is the name of the machine of the device.
+	Name string `json:"name"`
+	// Node is the name of the node of the device.
+	// +optional
+	Node string `json:"node,omitempty"`
+	// Pool is the name of the worker pool of the device.
+	Pool string `json:"pool"`
+	// MachineDeployment is the name of the machine deployment of the machine of the device.
+	MachineDeployment string `json:"machineDeployment"`
+	// DeviceID is the ID of the device.
+	DeviceID string `json:"deviceID"`
+}
+
+// MachineStatus contains information about the device of a machine.
+type MachineStatus struct {
+	// Name is the name of the machine.
+	Name string `json:"name"`
+	// Node is the name of the node of the machine.
+	// +optional
+	Node string `json:"node,omitempty"`
+	// Pool is the name of the worker pool of the machine.
+	Pool string `json:"pool"`
+	// DeviceID is the ID of the device.
+	DeviceID string `json:"deviceID"`
+	// Hostname is the hostname of the device.
+	// +optional
+	Hostname string `json:"hostname,omitempty"`
+	// Facility is the code of the facility the device is located in.
+	// +optional
+	Facility string `json:"facility,omitempty"`
+	// Plan is the slug of the plan of the device.
+	// +optional
+	Plan string `json:"plan,omitempty"`
+	// ReservationID is the ID of the hardware reservation used by the device.
+	// +optional
+	ReservationID *string `json:"reservationID,omitempty"`
+	// PublicIPs is the list of public IP addresses of the device.
+	// +optional
+	PublicIPs []string `json:"publicIPs,omitempty"`
+	// PrivateIPs is the list of private IP addresses of the device.
+	// +optional
+	PrivateIPs []string `json:"privateIPs,omitempty"`
+	// State is the provisioning state of the device.
+	// +optional
+	State string `json:"state,omitempty"`
+	// CreatedAt is the creation time of the device.
+	// +optional
+	CreatedAt *metav1.Time `json:"createdAt,omitempty"`
+}
+
+// WorkerPoolStatus contains status information about a worker pool.
+type WorkerPoolStatus struct {
+	// Name is the name of the worker pool.
+	Name string `json:"name"`
+	// ReservationIDs is the list of IDs of the hardware reservations which have been selected by the reservation
+	// selector of the worker pool.
+	// +optional
+	ReservationIDs []string `json:"reservationIDs,omitempty"`
+	// Reservations reports the usage of the hardware reservations of the worker pool.
+	// +optional
+	Reservations *ReservationReport `json:"reservations,omitempty"`
+	// MachineType is the machine type which is used for new machines of the worker pool. It is only reported for
+	// worker pools with fallback machine types.
+	// +optional
+	MachineType string `json:"machineType,omitempty"`
+	// UpdateStrategies reports the update strategies of the machine deployments of the worker pool which only use
+	// reserved devices. They are adjusted to the free hardware reservations of the machine deployments.
+	// +optional
+	UpdateStrategies []UpdateStrategyStatus `json:"updateStrategies,omitempty"`
+	// HibernationMode is the mode in which the worker pool is hibernated. It is only reported for worker pools whose
+	// devices are powered off while the shoot is hibernated.
+	// +optional
+	HibernationMode string `json:"hibernationMode,omitempty"`
+	// AlwaysPXE indicates whether the devices of the worker pool have been configured to boot via iPXE on every boot.
+	// It is kept after `alwaysPXE` has been removed from the worker pool until its devices have been reset.
+	// +optional
+	AlwaysPXE bool `json:"alwaysPXE,omitempty"`
+}
+
+// UpdateStrategyStatus reports the update strategy of a machine deployment which only uses reserved devices.
+type UpdateStrategyStatus struct {
+	// Name is the name of the machine deployment.
+	Name string `json:"name"`
+	// Type is the type of the update strategy, either `RollingUpdate` or `DeleteBeforeCreate`.
+	Type string `json:"type"`
+	// FreeReservations is the number of free hardware reservations of the machine deployment.
+	FreeReservations int32 `json:"freeReservations"`
+	// MaxSurge is the maximum number of machines which can be created above the desired number of machines during an
+	// update.
+	MaxSurge int32 `json:"maxSurge"`
+	// MaxUnavailable is the maximum number of machines which can be unavailable during an update.
+	MaxUnavailable int32 `json:"maxUnavailable"`
+}
+
+// ReservationReport reports the usage of the hardware reservations of a worker pool.
+type ReservationReport struct {
+	// Total is the number of existing hardware reservations of the worker pool, spare reservations are only counted if they are in use by the worker pool.
+	Total int32 `json:"total"`
+	// InUse is the list of IDs of the hardware reservations which are used by devices of the worker pool.
+	// +optional
+	InUse []string `json:"inUse,omitempty"`
+	// Free is the list of IDs of the hardware reservations which are neither used by a device nor spare.
+	// +optional
+	Free []string `json:"free,omitempty"`
+	// OnDemandDevices is the number of devices of the worker pool which do not use a hardware reservation.
+	OnDemandDevices int32 `json:"onDemandDevices"`
 }
 
 // MachineImage is a mapping from logical names and versions to provider-specific machine image data.
@@ -53,4 +434,7 @@ type MachineImage struct {
 	// IPXEScriptURL is url to point to a IPXE script.
 	// +optional
 	IPXEScriptURL string `json:"ipxeScriptUrl,omitempty"`
+	// Architecture is the CPU architecture of the machine image.
+	// +optional
+	Architecture *string `json:"architecture,omitempty"`
 }
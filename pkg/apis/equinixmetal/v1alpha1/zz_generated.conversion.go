@@ -13,6 +13,8 @@ import (
 	unsafe "unsafe"
 
 	equinixmetal "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal"
+	resource "k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	conversion "k8s.io/apimachinery/pkg/conversion"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
@@ -24,6 +26,16 @@ func init() {
 // RegisterConversions adds conversion functions to the given scheme.
 // Public to allow building arbitrary schemes.
 func RegisterConversions(s *runtime.Scheme) error {
+	if err := s.AddGeneratedConversionFunc((*AdoptedDevice)(nil), (*equinixmetal.AdoptedDevice)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_AdoptedDevice_To_equinixmetal_AdoptedDevice(a.(*AdoptedDevice), b.(*equinixmetal.AdoptedDevice), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*equinixmetal.AdoptedDevice)(nil), (*AdoptedDevice)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_equinixmetal_AdoptedDevice_To_v1alpha1_AdoptedDevice(a.(*equinixmetal.AdoptedDevice), b.(*AdoptedDevice), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*CloudProfileConfig)(nil), (*equinixmetal.CloudProfileConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_CloudProfileConfig_To_equinixmetal_CloudProfileConfig(a.(*CloudProfileConfig), b.(*equinixmetal.CloudProfileConfig), scope)
 	}); err != nil {
@@ -44,6 +56,46 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*DeviceAdoption)(nil), (*equinixmetal.DeviceAdoption)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_DeviceAdoption_To_equinixmetal_DeviceAdoption(a.(*DeviceAdoption), b.(*equinixmetal.DeviceAdoption), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*equinixmetal.DeviceAdoption)(nil), (*DeviceAdoption)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_equinixmetal_DeviceAdoption_To_v1alpha1_DeviceAdoption(a.(*equinixmetal.DeviceAdoption), b.(*DeviceAdoption), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*DiskSelector)(nil), (*equinixmetal.DiskSelector)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_DiskSelector_To_equinixmetal_DiskSelector(a.(*DiskSelector), b.(*equinixmetal.DiskSelector), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*equinixmetal.DiskSelector)(nil), (*DiskSelector)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_equinixmetal_DiskSelector_To_v1alpha1_DiskSelector(a.(*equinixmetal.DiskSelector), b.(*DiskSelector), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*HibernatedDevice)(nil), (*equinixmetal.HibernatedDevice)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_HibernatedDevice_To_equinixmetal_HibernatedDevice(a.(*HibernatedDevice), b.(*equinixmetal.HibernatedDevice), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*equinixmetal.HibernatedDevice)(nil), (*HibernatedDevice)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_equinixmetal_HibernatedDevice_To_v1alpha1_HibernatedDevice(a.(*equinixmetal.HibernatedDevice), b.(*HibernatedDevice), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*IPXE)(nil), (*equinixmetal.IPXE)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_IPXE_To_equinixmetal_IPXE(a.(*IPXE), b.(*equinixmetal.IPXE), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*equinixmetal.IPXE)(nil), (*IPXE)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_equinixmetal_IPXE_To_v1alpha1_IPXE(a.(*equinixmetal.IPXE), b.(*IPXE), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*InfrastructureConfig)(nil), (*equinixmetal.InfrastructureConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_InfrastructureConfig_To_equinixmetal_InfrastructureConfig(a.(*InfrastructureConfig), b.(*equinixmetal.InfrastructureConfig), scope)
 	}); err != nil {
@@ -64,6 +116,16 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*LogicalVolume)(nil), (*equinixmetal.LogicalVolume)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_LogicalVolume_To_equinixmetal_LogicalVolume(a.(*LogicalVolume), b.(*equinixmetal.LogicalVolume), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*equinixmetal.LogicalVolume)(nil), (*LogicalVolume)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_equinixmetal_LogicalVolume_To_v1alpha1_LogicalVolume(a.(*equinixmetal.LogicalVolume), b.(*LogicalVolume), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*MachineImage)(nil), (*equinixmetal.MachineImage)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_MachineImage_To_equinixmetal_MachineImage(a.(*MachineImage), b.(*equinixmetal.MachineImage), scope)
 	}); err != nil {
@@ -94,6 +156,126 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*MachineStatus)(nil), (*equinixmetal.MachineStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_MachineStatus_To_equinixmetal_MachineStatus(a.(*MachineStatus), b.(*equinixmetal.MachineStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*equinixmetal.MachineStatus)(nil), (*MachineStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_equinixmetal_MachineStatus_To_v1alpha1_MachineStatus(a.(*equinixmetal.MachineStatus), b.(*MachineStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*MachineTimeouts)(nil), (*equinixmetal.MachineTimeouts)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_MachineTimeouts_To_equinixmetal_MachineTimeouts(a.(*MachineTimeouts), b.(*equinixmetal.MachineTimeouts), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*equinixmetal.MachineTimeouts)(nil), (*MachineTimeouts)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_equinixmetal_MachineTimeouts_To_v1alpha1_MachineTimeouts(a.(*equinixmetal.MachineTimeouts), b.(*MachineTimeouts), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*ReservationReport)(nil), (*equinixmetal.ReservationReport)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_ReservationReport_To_equinixmetal_ReservationReport(a.(*ReservationReport), b.(*equinixmetal.ReservationReport), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*equinixmetal.ReservationReport)(nil), (*ReservationReport)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_equinixmetal_ReservationReport_To_v1alpha1_ReservationReport(a.(*equinixmetal.ReservationReport), b.(*ReservationReport), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*ReservationSelector)(nil), (*equinixmetal.ReservationSelector)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_ReservationSelector_To_equinixmetal_ReservationSelector(a.(*ReservationSelector), b.(*equinixmetal.ReservationSelector), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*equinixmetal.ReservationSelector)(nil), (*ReservationSelector)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_equinixmetal_ReservationSelector_To_v1alpha1_ReservationSelector(a.(*equinixmetal.ReservationSelector), b.(*ReservationSelector), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*Storage)(nil), (*equinixmetal.Storage)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_Storage_To_equinixmetal_Storage(a.(*Storage), b.(*equinixmetal.Storage), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*equinixmetal.Storage)(nil), (*Storage)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_equinixmetal_Storage_To_v1alpha1_Storage(a.(*equinixmetal.Storage), b.(*Storage), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*StorageDisk)(nil), (*equinixmetal.StorageDisk)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_StorageDisk_To_equinixmetal_StorageDisk(a.(*StorageDisk), b.(*equinixmetal.StorageDisk), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*equinixmetal.StorageDisk)(nil), (*StorageDisk)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_equinixmetal_StorageDisk_To_v1alpha1_StorageDisk(a.(*equinixmetal.StorageDisk), b.(*StorageDisk), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*StorageFilesystem)(nil), (*equinixmetal.StorageFilesystem)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_StorageFilesystem_To_equinixmetal_StorageFilesystem(a.(*StorageFilesystem), b.(*equinixmetal.StorageFilesystem), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*equinixmetal.StorageFilesystem)(nil), (*StorageFilesystem)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_equinixmetal_StorageFilesystem_To_v1alpha1_StorageFilesystem(a.(*equinixmetal.StorageFilesystem), b.(*StorageFilesystem), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*StoragePartition)(nil), (*equinixmetal.StoragePartition)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_StoragePartition_To_equinixmetal_StoragePartition(a.(*StoragePartition), b.(*equinixmetal.StoragePartition), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*equinixmetal.StoragePartition)(nil), (*StoragePartition)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_equinixmetal_StoragePartition_To_v1alpha1_StoragePartition(a.(*equinixmetal.StoragePartition), b.(*StoragePartition), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*StorageRAID)(nil), (*equinixmetal.StorageRAID)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_StorageRAID_To_equinixmetal_StorageRAID(a.(*StorageRAID), b.(*equinixmetal.StorageRAID), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*equinixmetal.StorageRAID)(nil), (*StorageRAID)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_equinixmetal_StorageRAID_To_v1alpha1_StorageRAID(a.(*equinixmetal.StorageRAID), b.(*StorageRAID), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*UpdateStrategyStatus)(nil), (*equinixmetal.UpdateStrategyStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_UpdateStrategyStatus_To_equinixmetal_UpdateStrategyStatus(a.(*UpdateStrategyStatus), b.(*equinixmetal.UpdateStrategyStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*equinixmetal.UpdateStrategyStatus)(nil), (*UpdateStrategyStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_equinixmetal_UpdateStrategyStatus_To_v1alpha1_UpdateStrategyStatus(a.(*equinixmetal.UpdateStrategyStatus), b.(*UpdateStrategyStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*VLAN)(nil), (*equinixmetal.VLAN)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_VLAN_To_equinixmetal_VLAN(a.(*VLAN), b.(*equinixmetal.VLAN), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*equinixmetal.VLAN)(nil), (*VLAN)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_equinixmetal_VLAN_To_v1alpha1_VLAN(a.(*equinixmetal.VLAN), b.(*VLAN), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*VolumeLayout)(nil), (*equinixmetal.VolumeLayout)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_VolumeLayout_To_equinixmetal_VolumeLayout(a.(*VolumeLayout), b.(*equinixmetal.VolumeLayout), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*equinixmetal.VolumeLayout)(nil), (*VolumeLayout)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_equinixmetal_VolumeLayout_To_v1alpha1_VolumeLayout(a.(*equinixmetal.VolumeLayout), b.(*VolumeLayout), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*WorkerConfig)(nil), (*equinixmetal.WorkerConfig)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_WorkerConfig_To_equinixmetal_WorkerConfig(a.(*WorkerConfig), b.(*equinixmetal.WorkerConfig), scope)
 	}); err != nil {
@@ -104,6 +286,36 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*WorkerNetwork)(nil), (*equinixmetal.WorkerNetwork)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_WorkerNetwork_To_equinixmetal_WorkerNetwork(a.(*WorkerNetwork), b.(*equinixmetal.WorkerNetwork), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*equinixmetal.WorkerNetwork)(nil), (*WorkerNetwork)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_equinixmetal_WorkerNetwork_To_v1alpha1_WorkerNetwork(a.(*equinixmetal.WorkerNetwork), b.(*WorkerNetwork), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*WorkerPoolStatus)(nil), (*equinixmetal.WorkerPoolStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_WorkerPoolStatus_To_equinixmetal_WorkerPoolStatus(a.(*WorkerPoolStatus), b.(*equinixmetal.WorkerPoolStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*equinixmetal.WorkerPoolStatus)(nil), (*WorkerPoolStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_equinixmetal_WorkerPoolStatus_To_v1alpha1_WorkerPoolStatus(a.(*equinixmetal.WorkerPoolStatus), b.(*WorkerPoolStatus), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*WorkerSSH)(nil), (*equinixmetal.WorkerSSH)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v1alpha1_WorkerSSH_To_equinixmetal_WorkerSSH(a.(*WorkerSSH), b.(*equinixmetal.WorkerSSH), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*equinixmetal.WorkerSSH)(nil), (*WorkerSSH)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_equinixmetal_WorkerSSH_To_v1alpha1_WorkerSSH(a.(*equinixmetal.WorkerSSH), b.(*WorkerSSH), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*WorkerStatus)(nil), (*equinixmetal.WorkerStatus)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v1alpha1_WorkerStatus_To_equinixmetal_WorkerStatus(a.(*WorkerStatus), b.(*equinixmetal.WorkerStatus), scope)
 	}); err != nil {
@@ -117,8 +329,33 @@ func RegisterConversions(s *runtime.Scheme) error {
 	return nil
 }
 
+func autoConvert_v1alpha1_AdoptedDevice_To_equinixmetal_AdoptedDevice(in *AdoptedDevice, out *equinixmetal.AdoptedDevice, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Pool = in.Pool
+	out.DeviceID = in.DeviceID
+	return nil
+}
+
+// Convert_v1alpha1_AdoptedDevice_To_equinixmetal_AdoptedDevice is an autogenerated conversion function.
+func Convert_v1alpha1_AdoptedDevice_To_equinixmetal_AdoptedDevice(in *AdoptedDevice, out *equinixmetal.AdoptedDevice, s conversion.Scope) error {
+	return autoConvert_v1alpha1_AdoptedDevice_To_equinixmetal_AdoptedDevice(in, out, s)
+}
+
+func autoConvert_equinixmetal_AdoptedDevice_To_v1alpha1_AdoptedDevice(in *equinixmetal.AdoptedDevice, out *AdoptedDevice, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Pool = in.Pool
+	out.DeviceID = in.DeviceID
+	return nil
+}
+
+// Convert_equinixmetal_AdoptedDevice_To_v1alpha1_AdoptedDevice is an autogenerated conversion function.
+func Convert_equinixmetal_AdoptedDevice_To_v1alpha1_AdoptedDevice(in *equinixmetal.AdoptedDevice, out *AdoptedDevice, s conversion.Scope) error {
+	return autoConvert_equinixmetal_AdoptedDevice_To_v1alpha1_AdoptedDevice(in, out, s)
+}
+
 func autoConvert_v1alpha1_CloudProfileConfig_To_equinixmetal_CloudProfileConfig(in *CloudProfileConfig, out *equinixmetal.CloudProfileConfig, s conversion.Scope) error {
 	out.MachineImages = *(*[]equinixmetal.MachineImages)(unsafe.Pointer(&in.MachineImages))
+	out.MachineTimeouts = *(*[]equinixmetal.MachineTimeouts)(unsafe.Pointer(&in.MachineTimeouts))
 	return nil
 }
 
@@ -129,6 +366,7 @@ func Convert_v1alpha1_CloudProfileConfig_To_equinixmetal_CloudProfileConfig(in *
 
 func autoConvert_equinixmetal_CloudProfileConfig_To_v1alpha1_CloudProfileConfig(in *equinixmetal.CloudProfileConfig, out *CloudProfileConfig, s conversion.Scope) error {
 	out.MachineImages = *(*[]MachineImages)(unsafe.Pointer(&in.MachineImages))
+	out.MachineTimeouts = *(*[]MachineTimeouts)(unsafe.Pointer(&in.MachineTimeouts))
 	return nil
 }
 
@@ -138,6 +376,7 @@ func Convert_equinixmetal_CloudProfileConfig_To_v1alpha1_CloudProfileConfig(in *
 }
 
 func autoConvert_v1alpha1_ControlPlaneConfig_To_equinixmetal_ControlPlaneConfig(in *ControlPlaneConfig, out *equinixmetal.ControlPlaneConfig, s conversion.Scope) error {
+	out.HibernationMode = (*string)(unsafe.Pointer(in.HibernationMode))
 	return nil
 }
 
@@ -147,6 +386,7 @@ func Convert_v1alpha1_ControlPlaneConfig_To_equinixmetal_ControlPlaneConfig(in *
 }
 
 func autoConvert_equinixmetal_ControlPlaneConfig_To_v1alpha1_ControlPlaneConfig(in *equinixmetal.ControlPlaneConfig, out *ControlPlaneConfig, s conversion.Scope) error {
+	out.HibernationMode = (*string)(unsafe.Pointer(in.HibernationMode))
 	return nil
 }
 
@@ -155,6 +395,108 @@ func Convert_equinixmetal_ControlPlaneConfig_To_v1alpha1_ControlPlaneConfig(in *
 	return autoConvert_equinixmetal_ControlPlaneConfig_To_v1alpha1_ControlPlaneConfig(in, out, s)
 }
 
+func autoConvert_v1alpha1_DeviceAdoption_To_equinixmetal_DeviceAdoption(in *DeviceAdoption, out *equinixmetal.DeviceAdoption, s conversion.Scope) error {
+	out.DeviceIDs = *(*[]string)(unsafe.Pointer(&in.DeviceIDs))
+	out.Tags = *(*[]string)(unsafe.Pointer(&in.Tags))
+	out.Bootstrap = (*string)(unsafe.Pointer(in.Bootstrap))
+	return nil
+}
+
+// Convert_v1alpha1_DeviceAdoption_To_equinixmetal_DeviceAdoption is an autogenerated conversion function.
+func Convert_v1alpha1_DeviceAdoption_To_equinixmetal_DeviceAdoption(in *DeviceAdoption, out *equinixmetal.DeviceAdoption, s conversion.Scope) error {
+	return autoConvert_v1alpha1_DeviceAdoption_To_equinixmetal_DeviceAdoption(in, out, s)
+}
+
+func autoConvert_equinixmetal_DeviceAdoption_To_v1alpha1_DeviceAdoption(in *equinixmetal.DeviceAdoption, out *DeviceAdoption, s conversion.Scope) error {
+	out.DeviceIDs = *(*[]string)(unsafe.Pointer(&in.DeviceIDs))
+	out.Tags = *(*[]string)(unsafe.Pointer(&in.Tags))
+	out.Bootstrap = (*string)(unsafe.Pointer(in.Bootstrap))
+	return nil
+}
+
+// Convert_equinixmetal_DeviceAdoption_To_v1alpha1_DeviceAdoption is an autogenerated conversion function.
+func Convert_equinixmetal_DeviceAdoption_To_v1alpha1_DeviceAdoption(in *equinixmetal.DeviceAdoption, out *DeviceAdoption, s conversion.Scope) error {
+	return autoConvert_equinixmetal_DeviceAdoption_To_v1alpha1_DeviceAdoption(in, out, s)
+}
+
+func autoConvert_v1alpha1_DiskSelector_To_equinixmetal_DiskSelector(in *DiskSelector, out *equinixmetal.DiskSelector, s conversion.Scope) error {
+	out.Types = *(*[]string)(unsafe.Pointer(&in.Types))
+	out.MinSize = (*resource.Quantity)(unsafe.Pointer(in.MinSize))
+	out.MaxSize = (*resource.Quantity)(unsafe.Pointer(in.MaxSize))
+	return nil
+}
+
+// Convert_v1alpha1_DiskSelector_To_equinixmetal_DiskSelector is an autogenerated conversion function.
+func Convert_v1alpha1_DiskSelector_To_equinixmetal_DiskSelector(in *DiskSelector, out *equinixmetal.DiskSelector, s conversion.Scope) error {
+	return autoConvert_v1alpha1_DiskSelector_To_equinixmetal_DiskSelector(in, out, s)
+}
+
+func autoConvert_equinixmetal_DiskSelector_To_v1alpha1_DiskSelector(in *equinixmetal.DiskSelector, out *DiskSelector, s conversion.Scope) error {
+	out.Types = *(*[]string)(unsafe.Pointer(&in.Types))
+	out.MinSize = (*resource.Quantity)(unsafe.Pointer(in.MinSize))
+	out.MaxSize = (*resource.Quantity)(unsafe.Pointer(in.MaxSize))
+	return nil
+}
+
+// Convert_equinixmetal_DiskSelector_To_v1alpha1_DiskSelector is an autogenerated conversion function.
+func Convert_equinixmetal_DiskSelector_To_v1alpha1_DiskSelector(in *equinixmetal.DiskSelector, out *DiskSelector, s conversion.Scope) error {
+	return autoConvert_equinixmetal_DiskSelector_To_v1alpha1_DiskSelector(in, out, s)
+}
+
+func autoConvert_v1alpha1_HibernatedDevice_To_equinixmetal_HibernatedDevice(in *HibernatedDevice, out *equinixmetal.HibernatedDevice, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Node = in.Node
+	out.Pool = in.Pool
+	out.MachineDeployment = in.MachineDeployment
+	out.DeviceID = in.DeviceID
+	return nil
+}
+
+// Convert_v1alpha1_HibernatedDevice_To_equinixmetal_HibernatedDevice is an autogenerated conversion function.
+func Convert_v1alpha1_HibernatedDevice_To_equinixmetal_HibernatedDevice(in *HibernatedDevice, out *equinixmetal.HibernatedDevice, s conversion.Scope) error {
+	return autoConvert_v1alpha1_HibernatedDevice_To_equinixmetal_HibernatedDevice(in, out, s)
+}
+
+func autoConvert_equinixmetal_HibernatedDevice_To_v1alpha1_HibernatedDevice(in *equinixmetal.HibernatedDevice, out *HibernatedDevice, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Node = in.Node
+	out.Pool = in.Pool
+	out.MachineDeployment = in.MachineDeployment
+	out.DeviceID = in.DeviceID
+	return nil
+}
+
+// Convert_equinixmetal_HibernatedDevice_To_v1alpha1_HibernatedDevice is an autogenerated conversion function.
+func Convert_equinixmetal_HibernatedDevice_To_v1alpha1_HibernatedDevice(in *equinixmetal.HibernatedDevice, out *HibernatedDevice, s conversion.Scope) error {
+	return autoConvert_equinixmetal_HibernatedDevice_To_v1alpha1_HibernatedDevice(in, out, s)
+}
+
+func autoConvert_v1alpha1_IPXE_To_equinixmetal_IPXE(in *IPXE, out *equinixmetal.IPXE, s conversion.Scope) error {
+	out.ScriptURL = (*string)(unsafe.Pointer(in.ScriptURL))
+	out.Script = (*string)(unsafe.Pointer(in.Script))
+	out.KernelArgs = *(*[]string)(unsafe.Pointer(&in.KernelArgs))
+	out.AlwaysPXE = (*bool)(unsafe.Pointer(in.AlwaysPXE))
+	return nil
+}
+
+// Convert_v1alpha1_IPXE_To_equinixmetal_IPXE is an autogenerated conversion function.
+func Convert_v1alpha1_IPXE_To_equinixmetal_IPXE(in *IPXE, out *equinixmetal.IPXE, s conversion.Scope) error {
+	return autoConvert_v1alpha1_IPXE_To_equinixmetal_IPXE(in, out, s)
+}
+
+func autoConvert_equinixmetal_IPXE_To_v1alpha1_IPXE(in *equinixmetal.IPXE, out *IPXE, s conversion.Scope) error {
+	out.ScriptURL = (*string)(unsafe.Pointer(in.ScriptURL))
+	out.Script = (*string)(unsafe.Pointer(in.Script))
+	out.KernelArgs = *(*[]string)(unsafe.Pointer(&in.KernelArgs))
+	out.AlwaysPXE = (*bool)(unsafe.Pointer(in.AlwaysPXE))
+	return nil
+}
+
+// Convert_equinixmetal_IPXE_To_v1alpha1_IPXE is an autogenerated conversion function.
+func Convert_equinixmetal_IPXE_To_v1alpha1_IPXE(in *equinixmetal.IPXE, out *IPXE, s conversion.Scope) error {
+	return autoConvert_equinixmetal_IPXE_To_v1alpha1_IPXE(in, out, s)
+}
+
 func autoConvert_v1alpha1_InfrastructureConfig_To_equinixmetal_InfrastructureConfig(in *InfrastructureConfig, out *equinixmetal.InfrastructureConfig, s conversion.Scope) error {
 	return nil
 }
@@ -193,11 +535,34 @@ func Convert_equinixmetal_InfrastructureStatus_To_v1alpha1_InfrastructureStatus(
 	return autoConvert_equinixmetal_InfrastructureStatus_To_v1alpha1_InfrastructureStatus(in, out, s)
 }
 
+func autoConvert_v1alpha1_LogicalVolume_To_equinixmetal_LogicalVolume(in *LogicalVolume, out *equinixmetal.LogicalVolume, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Size = (*resource.Quantity)(unsafe.Pointer(in.Size))
+	return nil
+}
+
+// Convert_v1alpha1_LogicalVolume_To_equinixmetal_LogicalVolume is an autogenerated conversion function.
+func Convert_v1alpha1_LogicalVolume_To_equinixmetal_LogicalVolume(in *LogicalVolume, out *equinixmetal.LogicalVolume, s conversion.Scope) error {
+	return autoConvert_v1alpha1_LogicalVolume_To_equinixmetal_LogicalVolume(in, out, s)
+}
+
+func autoConvert_equinixmetal_LogicalVolume_To_v1alpha1_LogicalVolume(in *equinixmetal.LogicalVolume, out *LogicalVolume, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Size = (*resource.Quantity)(unsafe.Pointer(in.Size))
+	return nil
+}
+
+// Convert_equinixmetal_LogicalVolume_To_v1alpha1_LogicalVolume is an autogenerated conversion function.
+func Convert_equinixmetal_LogicalVolume_To_v1alpha1_LogicalVolume(in *equinixmetal.LogicalVolume, out *LogicalVolume, s conversion.Scope) error {
+	return autoConvert_equinixmetal_LogicalVolume_To_v1alpha1_LogicalVolume(in, out, s)
+}
+
 func autoConvert_v1alpha1_MachineImage_To_equinixmetal_MachineImage(in *MachineImage, out *equinixmetal.MachineImage, s conversion.Scope) error {
 	out.Name = in.Name
 	out.Version = in.Version
 	out.ID = in.ID
 	out.IPXEScriptURL = in.IPXEScriptURL
+	out.Architecture = (*string)(unsafe.Pointer(in.Architecture))
 	return nil
 }
 
@@ -211,6 +576,7 @@ func autoConvert_equinixmetal_MachineImage_To_v1alpha1_MachineImage(in *equinixm
 	out.Version = in.Version
 	out.ID = in.ID
 	out.IPXEScriptURL = in.IPXEScriptURL
+	out.Architecture = (*string)(unsafe.Pointer(in.Architecture))
 	return nil
 }
 
@@ -223,6 +589,7 @@ func autoConvert_v1alpha1_MachineImageVersion_To_equinixmetal_MachineImageVersio
 	out.Version = in.Version
 	out.ID = in.ID
 	out.IPXEScriptURL = in.IPXEScriptURL
+	out.Architecture = (*string)(unsafe.Pointer(in.Architecture))
 	return nil
 }
 
@@ -235,6 +602,7 @@ func autoConvert_equinixmetal_MachineImageVersion_To_v1alpha1_MachineImageVersio
 	out.Version = in.Version
 	out.ID = in.ID
 	out.IPXEScriptURL = in.IPXEScriptURL
+	out.Architecture = (*string)(unsafe.Pointer(in.Architecture))
 	return nil
 }
 
@@ -265,9 +633,345 @@ func Convert_equinixmetal_MachineImages_To_v1alpha1_MachineImages(in *equinixmet
 	return autoConvert_equinixmetal_MachineImages_To_v1alpha1_MachineImages(in, out, s)
 }
 
+func autoConvert_v1alpha1_MachineStatus_To_equinixmetal_MachineStatus(in *MachineStatus, out *equinixmetal.MachineStatus, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Node = in.Node
+	out.Pool = in.Pool
+	out.DeviceID = in.DeviceID
+	out.Hostname = in.Hostname
+	out.Facility = in.Facility
+	out.Plan = in.Plan
+	out.ReservationID = (*string)(unsafe.Pointer(in.ReservationID))
+	out.PublicIPs = *(*[]string)(unsafe.Pointer(&in.PublicIPs))
+	out.PrivateIPs = *(*[]string)(unsafe.Pointer(&in.PrivateIPs))
+	out.State = in.State
+	out.CreatedAt = (*v1.Time)(unsafe.Pointer(in.CreatedAt))
+	return nil
+}
+
+// Convert_v1alpha1_MachineStatus_To_equinixmetal_MachineStatus is an autogenerated conversion function.
+func Convert_v1alpha1_MachineStatus_To_equinixmetal_MachineStatus(in *MachineStatus, out *equinixmetal.MachineStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha1_MachineStatus_To_equinixmetal_MachineStatus(in, out, s)
+}
+
+func autoConvert_equinixmetal_MachineStatus_To_v1alpha1_MachineStatus(in *equinixmetal.MachineStatus, out *MachineStatus, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Node = in.Node
+	out.Pool = in.Pool
+	out.DeviceID = in.DeviceID
+	out.Hostname = in.Hostname
+	out.Facility = in.Facility
+	out.Plan = in.Plan
+	out.ReservationID = (*string)(unsafe.Pointer(in.ReservationID))
+	out.PublicIPs = *(*[]string)(unsafe.Pointer(&in.PublicIPs))
+	out.PrivateIPs = *(*[]string)(unsafe.Pointer(&in.PrivateIPs))
+	out.State = in.State
+	out.CreatedAt = (*v1.Time)(unsafe.Pointer(in.CreatedAt))
+	return nil
+}
+
+// Convert_equinixmetal_MachineStatus_To_v1alpha1_MachineStatus is an autogenerated conversion function.
+func Convert_equinixmetal_MachineStatus_To_v1alpha1_MachineStatus(in *equinixmetal.MachineStatus, out *MachineStatus, s conversion.Scope) error {
+	return autoConvert_equinixmetal_MachineStatus_To_v1alpha1_MachineStatus(in, out, s)
+}
+
+func autoConvert_v1alpha1_MachineTimeouts_To_equinixmetal_MachineTimeouts(in *MachineTimeouts, out *equinixmetal.MachineTimeouts, s conversion.Scope) error {
+	out.PlanFamilies = *(*[]string)(unsafe.Pointer(&in.PlanFamilies))
+	out.MachineImages = *(*[]string)(unsafe.Pointer(&in.MachineImages))
+	out.CreationTimeout = (*v1.Duration)(unsafe.Pointer(in.CreationTimeout))
+	out.HealthTimeout = (*v1.Duration)(unsafe.Pointer(in.HealthTimeout))
+	out.DrainTimeout = (*v1.Duration)(unsafe.Pointer(in.DrainTimeout))
+	return nil
+}
+
+// Convert_v1alpha1_MachineTimeouts_To_equinixmetal_MachineTimeouts is an autogenerated conversion function.
+func Convert_v1alpha1_MachineTimeouts_To_equinixmetal_MachineTimeouts(in *MachineTimeouts, out *equinixmetal.MachineTimeouts, s conversion.Scope) error {
+	return autoConvert_v1alpha1_MachineTimeouts_To_equinixmetal_MachineTimeouts(in, out, s)
+}
+
+func autoConvert_equinixmetal_MachineTimeouts_To_v1alpha1_MachineTimeouts(in *equinixmetal.MachineTimeouts, out *MachineTimeouts, s conversion.Scope) error {
+	out.PlanFamilies = *(*[]string)(unsafe.Pointer(&in.PlanFamilies))
+	out.MachineImages = *(*[]string)(unsafe.Pointer(&in.MachineImages))
+	out.CreationTimeout = (*v1.Duration)(unsafe.Pointer(in.CreationTimeout))
+	out.HealthTimeout = (*v1.Duration)(unsafe.Pointer(in.HealthTimeout))
+	out.DrainTimeout = (*v1.Duration)(unsafe.Pointer(in.DrainTimeout))
+	return nil
+}
+
+// Convert_equinixmetal_MachineTimeouts_To_v1alpha1_MachineTimeouts is an autogenerated conversion function.
+func Convert_equinixmetal_MachineTimeouts_To_v1alpha1_MachineTimeouts(in *equinixmetal.MachineTimeouts, out *MachineTimeouts, s conversion.Scope) error {
+	return autoConvert_equinixmetal_MachineTimeouts_To_v1alpha1_MachineTimeouts(in, out, s)
+}
+
+func autoConvert_v1alpha1_ReservationReport_To_equinixmetal_ReservationReport(in *ReservationReport, out *equinixmetal.ReservationReport, s conversion.Scope) error {
+	out.Total = in.Total
+	out.InUse = *(*[]string)(unsafe.Pointer(&in.InUse))
+	out.Free = *(*[]string)(unsafe.Pointer(&in.Free))
+	out.OnDemandDevices = in.OnDemandDevices
+	return nil
+}
+
+// Convert_v1alpha1_ReservationReport_To_equinixmetal_ReservationReport is an autogenerated conversion function.
+func Convert_v1alpha1_ReservationReport_To_equinixmetal_ReservationReport(in *ReservationReport, out *equinixmetal.ReservationReport, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ReservationReport_To_equinixmetal_ReservationReport(in, out, s)
+}
+
+func autoConvert_equinixmetal_ReservationReport_To_v1alpha1_ReservationReport(in *equinixmetal.ReservationReport, out *ReservationReport, s conversion.Scope) error {
+	out.Total = in.Total
+	out.InUse = *(*[]string)(unsafe.Pointer(&in.InUse))
+	out.Free = *(*[]string)(unsafe.Pointer(&in.Free))
+	out.OnDemandDevices = in.OnDemandDevices
+	return nil
+}
+
+// Convert_equinixmetal_ReservationReport_To_v1alpha1_ReservationReport is an autogenerated conversion function.
+func Convert_equinixmetal_ReservationReport_To_v1alpha1_ReservationReport(in *equinixmetal.ReservationReport, out *ReservationReport, s conversion.Scope) error {
+	return autoConvert_equinixmetal_ReservationReport_To_v1alpha1_ReservationReport(in, out, s)
+}
+
+func autoConvert_v1alpha1_ReservationSelector_To_equinixmetal_ReservationSelector(in *ReservationSelector, out *equinixmetal.ReservationSelector, s conversion.Scope) error {
+	out.Plans = *(*[]string)(unsafe.Pointer(&in.Plans))
+	out.Facilities = *(*[]string)(unsafe.Pointer(&in.Facilities))
+	out.Metros = *(*[]string)(unsafe.Pointer(&in.Metros))
+	out.Tags = *(*[]string)(unsafe.Pointer(&in.Tags))
+	return nil
+}
+
+// Convert_v1alpha1_ReservationSelector_To_equinixmetal_ReservationSelector is an autogenerated conversion function.
+func Convert_v1alpha1_ReservationSelector_To_equinixmetal_ReservationSelector(in *ReservationSelector, out *equinixmetal.ReservationSelector, s conversion.Scope) error {
+	return autoConvert_v1alpha1_ReservationSelector_To_equinixmetal_ReservationSelector(in, out, s)
+}
+
+func autoConvert_equinixmetal_ReservationSelector_To_v1alpha1_ReservationSelector(in *equinixmetal.ReservationSelector, out *ReservationSelector, s conversion.Scope) error {
+	out.Plans = *(*[]string)(unsafe.Pointer(&in.Plans))
+	out.Facilities = *(*[]string)(unsafe.Pointer(&in.Facilities))
+	out.Metros = *(*[]string)(unsafe.Pointer(&in.Metros))
+	out.Tags = *(*[]string)(unsafe.Pointer(&in.Tags))
+	return nil
+}
+
+// Convert_equinixmetal_ReservationSelector_To_v1alpha1_ReservationSelector is an autogenerated conversion function.
+func Convert_equinixmetal_ReservationSelector_To_v1alpha1_ReservationSelector(in *equinixmetal.ReservationSelector, out *ReservationSelector, s conversion.Scope) error {
+	return autoConvert_equinixmetal_ReservationSelector_To_v1alpha1_ReservationSelector(in, out, s)
+}
+
+func autoConvert_v1alpha1_Storage_To_equinixmetal_Storage(in *Storage, out *equinixmetal.Storage, s conversion.Scope) error {
+	out.Disks = *(*[]equinixmetal.StorageDisk)(unsafe.Pointer(&in.Disks))
+	out.RAID = *(*[]equinixmetal.StorageRAID)(unsafe.Pointer(&in.RAID))
+	out.Filesystems = *(*[]equinixmetal.StorageFilesystem)(unsafe.Pointer(&in.Filesystems))
+	return nil
+}
+
+// Convert_v1alpha1_Storage_To_equinixmetal_Storage is an autogenerated conversion function.
+func Convert_v1alpha1_Storage_To_equinixmetal_Storage(in *Storage, out *equinixmetal.Storage, s conversion.Scope) error {
+	return autoConvert_v1alpha1_Storage_To_equinixmetal_Storage(in, out, s)
+}
+
+func autoConvert_equinixmetal_Storage_To_v1alpha1_Storage(in *equinixmetal.Storage, out *Storage, s conversion.Scope) error {
+	out.Disks = *(*[]StorageDisk)(unsafe.Pointer(&in.Disks))
+	out.RAID = *(*[]StorageRAID)(unsafe.Pointer(&in.RAID))
+	out.Filesystems = *(*[]StorageFilesystem)(unsafe.Pointer(&in.Filesystems))
+	return nil
+}
+
+// Convert_equinixmetal_Storage_To_v1alpha1_Storage is an autogenerated conversion function.
+func Convert_equinixmetal_Storage_To_v1alpha1_Storage(in *equinixmetal.Storage, out *Storage, s conversion.Scope) error {
+	return autoConvert_equinixmetal_Storage_To_v1alpha1_Storage(in, out, s)
+}
+
+func autoConvert_v1alpha1_StorageDisk_To_equinixmetal_StorageDisk(in *StorageDisk, out *equinixmetal.StorageDisk, s conversion.Scope) error {
+	out.Device = in.Device
+	out.WipeTable = (*bool)(unsafe.Pointer(in.WipeTable))
+	out.Partitions = *(*[]equinixmetal.StoragePartition)(unsafe.Pointer(&in.Partitions))
+	return nil
+}
+
+// Convert_v1alpha1_StorageDisk_To_equinixmetal_StorageDisk is an autogenerated conversion function.
+func Convert_v1alpha1_StorageDisk_To_equinixmetal_StorageDisk(in *StorageDisk, out *equinixmetal.StorageDisk, s conversion.Scope) error {
+	return autoConvert_v1alpha1_StorageDisk_To_equinixmetal_StorageDisk(in, out, s)
+}
+
+func autoConvert_equinixmetal_StorageDisk_To_v1alpha1_StorageDisk(in *equinixmetal.StorageDisk, out *StorageDisk, s conversion.Scope) error {
+	out.Device = in.Device
+	out.WipeTable = (*bool)(unsafe.Pointer(in.WipeTable))
+	out.Partitions = *(*[]StoragePartition)(unsafe.Pointer(&in.Partitions))
+	return nil
+}
+
+// Convert_equinixmetal_StorageDisk_To_v1alpha1_StorageDisk is an autogenerated conversion function.
+func Convert_equinixmetal_StorageDisk_To_v1alpha1_StorageDisk(in *equinixmetal.StorageDisk, out *StorageDisk, s conversion.Scope) error {
+	return autoConvert_equinixmetal_StorageDisk_To_v1alpha1_StorageDisk(in, out, s)
+}
+
+func autoConvert_v1alpha1_StorageFilesystem_To_equinixmetal_StorageFilesystem(in *StorageFilesystem, out *equinixmetal.StorageFilesystem, s conversion.Scope) error {
+	out.Device = in.Device
+	out.Format = in.Format
+	out.Point = (*string)(unsafe.Pointer(in.Point))
+	out.Options = *(*[]string)(unsafe.Pointer(&in.Options))
+	return nil
+}
+
+// Convert_v1alpha1_StorageFilesystem_To_equinixmetal_StorageFilesystem is an autogenerated conversion function.
+func Convert_v1alpha1_StorageFilesystem_To_equinixmetal_StorageFilesystem(in *StorageFilesystem, out *equinixmetal.StorageFilesystem, s conversion.Scope) error {
+	return autoConvert_v1alpha1_StorageFilesystem_To_equinixmetal_StorageFilesystem(in, out, s)
+}
+
+func autoConvert_equinixmetal_StorageFilesystem_To_v1alpha1_StorageFilesystem(in *equinixmetal.StorageFilesystem, out *StorageFilesystem, s conversion.Scope) error {
+	out.Device = in.Device
+	out.Format = in.Format
+	out.Point = (*string)(unsafe.Pointer(in.Point))
+	out.Options = *(*[]string)(unsafe.Pointer(&in.Options))
+	return nil
+}
+
+// Convert_equinixmetal_StorageFilesystem_To_v1alpha1_StorageFilesystem is an autogenerated conversion function.
+func Convert_equinixmetal_StorageFilesystem_To_v1alpha1_StorageFilesystem(in *equinixmetal.StorageFilesystem, out *StorageFilesystem, s conversion.Scope) error {
+	return autoConvert_equinixmetal_StorageFilesystem_To_v1alpha1_StorageFilesystem(in, out, s)
+}
+
+func autoConvert_v1alpha1_StoragePartition_To_equinixmetal_StoragePartition(in *StoragePartition, out *equinixmetal.StoragePartition, s conversion.Scope) error {
+	out.Label = in.Label
+	out.Number = in.Number
+	out.Size = in.Size
+	return nil
+}
+
+// Convert_v1alpha1_StoragePartition_To_equinixmetal_StoragePartition is an autogenerated conversion function.
+func Convert_v1alpha1_StoragePartition_To_equinixmetal_StoragePartition(in *StoragePartition, out *equinixmetal.StoragePartition, s conversion.Scope) error {
+	return autoConvert_v1alpha1_StoragePartition_To_equinixmetal_StoragePartition(in, out, s)
+}
+
+func autoConvert_equinixmetal_StoragePartition_To_v1alpha1_StoragePartition(in *equinixmetal.StoragePartition, out *StoragePartition, s conversion.Scope) error {
+	out.Label = in.Label
+	out.Number = in.Number
+	out.Size = in.Size
+	return nil
+}
+
+// Convert_equinixmetal_StoragePartition_To_v1alpha1_StoragePartition is an autogenerated conversion function.
+func Convert_equinixmetal_StoragePartition_To_v1alpha1_StoragePartition(in *equinixmetal.StoragePartition, out *StoragePartition, s conversion.Scope) error {
+	return autoConvert_equinixmetal_StoragePartition_To_v1alpha1_StoragePartition(in, out, s)
+}
+
+func autoConvert_v1alpha1_StorageRAID_To_equinixmetal_StorageRAID(in *StorageRAID, out *equinixmetal.StorageRAID, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Level = in.Level
+	out.Devices = *(*[]string)(unsafe.Pointer(&in.Devices))
+	return nil
+}
+
+// Convert_v1alpha1_StorageRAID_To_equinixmetal_StorageRAID is an autogenerated conversion function.
+func Convert_v1alpha1_StorageRAID_To_equinixmetal_StorageRAID(in *StorageRAID, out *equinixmetal.StorageRAID, s conversion.Scope) error {
+	return autoConvert_v1alpha1_StorageRAID_To_equinixmetal_StorageRAID(in, out, s)
+}
+
+func autoConvert_equinixmetal_StorageRAID_To_v1alpha1_StorageRAID(in *equinixmetal.StorageRAID, out *StorageRAID, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Level = in.Level
+	out.Devices = *(*[]string)(unsafe.Pointer(&in.Devices))
+	return nil
+}
+
+// Convert_equinixmetal_StorageRAID_To_v1alpha1_StorageRAID is an autogenerated conversion function.
+func Convert_equinixmetal_StorageRAID_To_v1alpha1_StorageRAID(in *equinixmetal.StorageRAID, out *StorageRAID, s conversion.Scope) error {
+	return autoConvert_equinixmetal_StorageRAID_To_v1alpha1_StorageRAID(in, out, s)
+}
+
+func autoConvert_v1alpha1_UpdateStrategyStatus_To_equinixmetal_UpdateStrategyStatus(in *UpdateStrategyStatus, out *equinixmetal.UpdateStrategyStatus, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Type = in.Type
+	out.FreeReservations = in.FreeReservations
+	out.MaxSurge = in.MaxSurge
+	out.MaxUnavailable = in.MaxUnavailable
+	return nil
+}
+
+// Convert_v1alpha1_UpdateStrategyStatus_To_equinixmetal_UpdateStrategyStatus is an autogenerated conversion function.
+func Convert_v1alpha1_UpdateStrategyStatus_To_equinixmetal_UpdateStrategyStatus(in *UpdateStrategyStatus, out *equinixmetal.UpdateStrategyStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha1_UpdateStrategyStatus_To_equinixmetal_UpdateStrategyStatus(in, out, s)
+}
+
+func autoConvert_equinixmetal_UpdateStrategyStatus_To_v1alpha1_UpdateStrategyStatus(in *equinixmetal.UpdateStrategyStatus, out *UpdateStrategyStatus, s conversion.Scope) error {
+	out.Name = in.Name
+	out.Type = in.Type
+	out.FreeReservations = in.FreeReservations
+	out.MaxSurge = in.MaxSurge
+	out.MaxUnavailable = in.MaxUnavailable
+	return nil
+}
+
+// Convert_equinixmetal_UpdateStrategyStatus_To_v1alpha1_UpdateStrategyStatus is an autogenerated conversion function.
+func Convert_equinixmetal_UpdateStrategyStatus_To_v1alpha1_UpdateStrategyStatus(in *equinixmetal.UpdateStrategyStatus, out *UpdateStrategyStatus, s conversion.Scope) error {
+	return autoConvert_equinixmetal_UpdateStrategyStatus_To_v1alpha1_UpdateStrategyStatus(in, out, s)
+}
+
+func autoConvert_v1alpha1_VLAN_To_equinixmetal_VLAN(in *VLAN, out *equinixmetal.VLAN, s conversion.Scope) error {
+	out.Name = in.Name
+	out.ID = (*int32)(unsafe.Pointer(in.ID))
+	return nil
+}
+
+// Convert_v1alpha1_VLAN_To_equinixmetal_VLAN is an autogenerated conversion function.
+func Convert_v1alpha1_VLAN_To_equinixmetal_VLAN(in *VLAN, out *equinixmetal.VLAN, s conversion.Scope) error {
+	return autoConvert_v1alpha1_VLAN_To_equinixmetal_VLAN(in, out, s)
+}
+
+func autoConvert_equinixmetal_VLAN_To_v1alpha1_VLAN(in *equinixmetal.VLAN, out *VLAN, s conversion.Scope) error {
+	out.Name = in.Name
+	out.ID = (*int32)(unsafe.Pointer(in.ID))
+	return nil
+}
+
+// Convert_equinixmetal_VLAN_To_v1alpha1_VLAN is an autogenerated conversion function.
+func Convert_equinixmetal_VLAN_To_v1alpha1_VLAN(in *equinixmetal.VLAN, out *VLAN, s conversion.Scope) error {
+	return autoConvert_equinixmetal_VLAN_To_v1alpha1_VLAN(in, out, s)
+}
+
+func autoConvert_v1alpha1_VolumeLayout_To_equinixmetal_VolumeLayout(in *VolumeLayout, out *equinixmetal.VolumeLayout, s conversion.Scope) error {
+	out.Disks = (*equinixmetal.DiskSelector)(unsafe.Pointer(in.Disks))
+	out.RAIDLevel = (*string)(unsafe.Pointer(in.RAIDLevel))
+	out.Filesystem = (*string)(unsafe.Pointer(in.Filesystem))
+	out.Volumes = *(*[]equinixmetal.LogicalVolume)(unsafe.Pointer(&in.Volumes))
+	return nil
+}
+
+// Convert_v1alpha1_VolumeLayout_To_equinixmetal_VolumeLayout is an autogenerated conversion function.
+func Convert_v1alpha1_VolumeLayout_To_equinixmetal_VolumeLayout(in *VolumeLayout, out *equinixmetal.VolumeLayout, s conversion.Scope) error {
+	return autoConvert_v1alpha1_VolumeLayout_To_equinixmetal_VolumeLayout(in, out, s)
+}
+
+func autoConvert_equinixmetal_VolumeLayout_To_v1alpha1_VolumeLayout(in *equinixmetal.VolumeLayout, out *VolumeLayout, s conversion.Scope) error {
+	out.Disks = (*DiskSelector)(unsafe.Pointer(in.Disks))
+	out.RAIDLevel = (*string)(unsafe.Pointer(in.RAIDLevel))
+	out.Filesystem = (*string)(unsafe.Pointer(in.Filesystem))
+	out.Volumes = *(*[]LogicalVolume)(unsafe.Pointer(&in.Volumes))
+	return nil
+}
+
+// Convert_equinixmetal_VolumeLayout_To_v1alpha1_VolumeLayout is an autogenerated conversion function.
+func Convert_equinixmetal_VolumeLayout_To_v1alpha1_VolumeLayout(in *equinixmetal.VolumeLayout, out *VolumeLayout, s conversion.Scope) error {
+	return autoConvert_equinixmetal_VolumeLayout_To_v1alpha1_VolumeLayout(in, out, s)
+}
+
 func autoConvert_v1alpha1_WorkerConfig_To_equinixmetal_WorkerConfig(in *WorkerConfig, out *equinixmetal.WorkerConfig, s conversion.Scope) error {
 	out.ReservationIDs = *(*[]string)(unsafe.Pointer(&in.ReservationIDs))
+	out.ReservationSelector = (*equinixmetal.ReservationSelector)(unsafe.Pointer(in.ReservationSelector))
 	out.ReservedDevicesOnly = (*bool)(unsafe.Pointer(in.ReservedDevicesOnly))
+	out.OnDemandOverflow = (*bool)(unsafe.Pointer(in.OnDemandOverflow))
+	out.BillingCycle = (*string)(unsafe.Pointer(in.BillingCycle))
+	out.Tags = *(*[]string)(unsafe.Pointer(&in.Tags))
+	out.TagsFromPoolLabels = *(*[]string)(unsafe.Pointer(&in.TagsFromPoolLabels))
+	out.CustomData = (*runtime.RawExtension)(unsafe.Pointer(in.CustomData))
+	out.Network = (*equinixmetal.WorkerNetwork)(unsafe.Pointer(in.Network))
+	out.Storage = (*equinixmetal.Storage)(unsafe.Pointer(in.Storage))
+	out.VolumeLayout = (*equinixmetal.VolumeLayout)(unsafe.Pointer(in.VolumeLayout))
+	out.SpotInstance = (*bool)(unsafe.Pointer(in.SpotInstance))
+	out.SpotPriceMax = (*string)(unsafe.Pointer(in.SpotPriceMax))
+	out.IPXE = (*equinixmetal.IPXE)(unsafe.Pointer(in.IPXE))
+	out.SSH = (*equinixmetal.WorkerSSH)(unsafe.Pointer(in.SSH))
+	out.FallbackMachineTypes = *(*[]string)(unsafe.Pointer(&in.FallbackMachineTypes))
+	out.HibernationMode = (*string)(unsafe.Pointer(in.HibernationMode))
+	out.AdoptDevices = (*equinixmetal.DeviceAdoption)(unsafe.Pointer(in.AdoptDevices))
 	return nil
 }
 
@@ -278,7 +982,23 @@ func Convert_v1alpha1_WorkerConfig_To_equinixmetal_WorkerConfig(in *WorkerConfig
 
 func autoConvert_equinixmetal_WorkerConfig_To_v1alpha1_WorkerConfig(in *equinixmetal.WorkerConfig, out *WorkerConfig, s conversion.Scope) error {
 	out.ReservationIDs = *(*[]string)(unsafe.Pointer(&in.ReservationIDs))
+	out.ReservationSelector = (*ReservationSelector)(unsafe.Pointer(in.ReservationSelector))
 	out.ReservedDevicesOnly = (*bool)(unsafe.Pointer(in.ReservedDevicesOnly))
+	out.OnDemandOverflow = (*bool)(unsafe.Pointer(in.OnDemandOverflow))
+	out.BillingCycle = (*string)(unsafe.Pointer(in.BillingCycle))
+	out.Tags = *(*[]string)(unsafe.Pointer(&in.Tags))
+	out.TagsFromPoolLabels = *(*[]string)(unsafe.Pointer(&in.TagsFromPoolLabels))
+	out.CustomData = (*runtime.RawExtension)(unsafe.Pointer(in.CustomData))
+	out.Network = (*WorkerNetwork)(unsafe.Pointer(in.Network))
+	out.Storage = (*Storage)(unsafe.Pointer(in.Storage))
+	out.VolumeLayout = (*VolumeLayout)(unsafe.Pointer(in.VolumeLayout))
+	out.SpotInstance = (*bool)(unsafe.Pointer(in.SpotInstance))
+	out.SpotPriceMax = (*string)(unsafe.Pointer(in.SpotPriceMax))
+	out.IPXE = (*IPXE)(unsafe.Pointer(in.IPXE))
+	out.SSH = (*WorkerSSH)(unsafe.Pointer(in.SSH))
+	out.FallbackMachineTypes = *(*[]string)(unsafe.Pointer(&in.FallbackMachineTypes))
+	out.HibernationMode = (*string)(unsafe.Pointer(in.HibernationMode))
+	out.AdoptDevices = (*DeviceAdoption)(unsafe.Pointer(in.AdoptDevices))
 	return nil
 }
 
@@ -287,8 +1007,90 @@ func Convert_equinixmetal_WorkerConfig_To_v1alpha1_WorkerConfig(in *equinixmetal
 	return autoConvert_equinixmetal_WorkerConfig_To_v1alpha1_WorkerConfig(in, out, s)
 }
 
+func autoConvert_v1alpha1_WorkerNetwork_To_equinixmetal_WorkerNetwork(in *WorkerNetwork, out *equinixmetal.WorkerNetwork, s conversion.Scope) error {
+	out.Type = (*string)(unsafe.Pointer(in.Type))
+	out.VLANs = *(*[]equinixmetal.VLAN)(unsafe.Pointer(&in.VLANs))
+	return nil
+}
+
+// Convert_v1alpha1_WorkerNetwork_To_equinixmetal_WorkerNetwork is an autogenerated conversion function.
+func Convert_v1alpha1_WorkerNetwork_To_equinixmetal_WorkerNetwork(in *WorkerNetwork, out *equinixmetal.WorkerNetwork, s conversion.Scope) error {
+	return autoConvert_v1alpha1_WorkerNetwork_To_equinixmetal_WorkerNetwork(in, out, s)
+}
+
+func autoConvert_equinixmetal_WorkerNetwork_To_v1alpha1_WorkerNetwork(in *equinixmetal.WorkerNetwork, out *WorkerNetwork, s conversion.Scope) error {
+	out.Type = (*string)(unsafe.Pointer(in.Type))
+	out.VLANs = *(*[]VLAN)(unsafe.Pointer(&in.VLANs))
+	return nil
+}
+
+// Convert_equinixmetal_WorkerNetwork_To_v1alpha1_WorkerNetwork is an autogenerated conversion function.
+func Convert_equinixmetal_WorkerNetwork_To_v1alpha1_WorkerNetwork(in *equinixmetal.WorkerNetwork, out *WorkerNetwork, s conversion.Scope) error {
+	return autoConvert_equinixmetal_WorkerNetwork_To_v1alpha1_WorkerNetwork(in, out, s)
+}
+
+func autoConvert_v1alpha1_WorkerPoolStatus_To_equinixmetal_WorkerPoolStatus(in *WorkerPoolStatus, out *equinixmetal.WorkerPoolStatus, s conversion.Scope) error {
+	out.Name = in.Name
+	out.ReservationIDs = *(*[]string)(unsafe.Pointer(&in.ReservationIDs))
+	out.Reservations = (*equinixmetal.ReservationReport)(unsafe.Pointer(in.Reservations))
+	out.MachineType = in.MachineType
+	out.UpdateStrategies = *(*[]equinixmetal.UpdateStrategyStatus)(unsafe.Pointer(&in.UpdateStrategies))
+	out.HibernationMode = in.HibernationMode
+	out.AlwaysPXE = in.AlwaysPXE
+	return nil
+}
+
+// Convert_v1alpha1_WorkerPoolStatus_To_equinixmetal_WorkerPoolStatus is an autogenerated conversion function.
+func Convert_v1alpha1_WorkerPoolStatus_To_equinixmetal_WorkerPoolStatus(in *WorkerPoolStatus, out *equinixmetal.WorkerPoolStatus, s conversion.Scope) error {
+	return autoConvert_v1alpha1_WorkerPoolStatus_To_equinixmetal_WorkerPoolStatus(in, out, s)
+}
+
+func autoConvert_equinixmetal_WorkerPoolStatus_To_v1alpha1_WorkerPoolStatus(in *equinixmetal.WorkerPoolStatus, out *WorkerPoolStatus, s conversion.Scope) error {
+	out.Name = in.Name
+	out.ReservationIDs = *(*[]string)(unsafe.Pointer(&in.ReservationIDs))
+	out.Reservations = (*ReservationReport)(unsafe.Pointer(in.Reservations))
+	out.MachineType = in.MachineType
+	out.UpdateStrategies = *(*[]UpdateStrategyStatus)(unsafe.Pointer(&in.UpdateStrategies))
+	out.HibernationMode = in.HibernationMode
+	out.AlwaysPXE = in.AlwaysPXE
+	return nil
+}
+
+// Convert_equinixmetal_WorkerPoolStatus_To_v1alpha1_WorkerPoolStatus is an autogenerated conversion function.
+func Convert_equinixmetal_WorkerPoolStatus_To_v1alpha1_WorkerPoolStatus(in *equinixmetal.WorkerPoolStatus, out *WorkerPoolStatus, s conversion.Scope) error {
+	return autoConvert_equinixmetal_WorkerPoolStatus_To_v1alpha1_WorkerPoolStatus(in, out, s)
+}
+
+func autoConvert_v1alpha1_WorkerSSH_To_equinixmetal_WorkerSSH(in *WorkerSSH, out *equinixmetal.WorkerSSH, s conversion.Scope) error {
+	out.ProjectKeyIDs = *(*[]string)(unsafe.Pointer(&in.ProjectKeyIDs))
+	out.UserKeyIDs = *(*[]string)(unsafe.Pointer(&in.UserKeyIDs))
+	out.AllProjectKeys = (*bool)(unsafe.Pointer(in.AllProjectKeys))
+	return nil
+}
+
+// Convert_v1alpha1_WorkerSSH_To_equinixmetal_WorkerSSH is an autogenerated conversion function.
+func Convert_v1alpha1_WorkerSSH_To_equinixmetal_WorkerSSH(in *WorkerSSH, out *equinixmetal.WorkerSSH, s conversion.Scope) error {
+	return autoConvert_v1alpha1_WorkerSSH_To_equinixmetal_WorkerSSH(in, out, s)
+}
+
+func autoConvert_equinixmetal_WorkerSSH_To_v1alpha1_WorkerSSH(in *equinixmetal.WorkerSSH, out *WorkerSSH, s conversion.Scope) error {
+	out.ProjectKeyIDs = *(*[]string)(unsafe.Pointer(&in.ProjectKeyIDs))
+	out.UserKeyIDs = *(*[]string)(unsafe.Pointer(&in.UserKeyIDs))
+	out.AllProjectKeys = (*bool)(unsafe.Pointer(in.AllProjectKeys))
+	return nil
+}
+
+// Convert_equinixmetal_WorkerSSH_To_v1alpha1_WorkerSSH is an autogenerated conversion function.
+func Convert_equinixmetal_WorkerSSH_To_v1alpha1_WorkerSSH(in *equinixmetal.WorkerSSH, out *WorkerSSH, s conversion.Scope) error {
+	return autoConvert_equinixmetal_WorkerSSH_To_v1alpha1_WorkerSSH(in, out, s)
+}
+
 func autoConvert_v1alpha1_WorkerStatus_To_equinixmetal_WorkerStatus(in *WorkerStatus, out *equinixmetal.WorkerStatus, s conversion.Scope) error {
 	out.MachineImages = *(*[]equinixmetal.MachineImage)(unsafe.Pointer(&in.MachineImages))
+	out.WorkerPools = *(*[]equinixmetal.WorkerPoolStatus)(unsafe.Pointer(&in.WorkerPools))
+	out.Machines = *(*[]equinixmetal.MachineStatus)(unsafe.Pointer(&in.Machines))
+	out.HibernatedDevices = *(*[]equinixmetal.HibernatedDevice)(unsafe.Pointer(&in.HibernatedDevices))
+	out.AdoptedDevices = *(*[]equinixmetal.AdoptedDevice)(unsafe.Pointer(&in.AdoptedDevices))
 	return nil
 }
 
@@ -299,6 +1101,10 @@ func Convert_v1alpha1_WorkerStatus_To_equinixmetal_WorkerStatus(in *WorkerStatus
 
 func autoConvert_equinixmetal_WorkerStatus_To_v1alpha1_WorkerStatus(in *equinixmetal.WorkerStatus, out *WorkerStatus, s conversion.Scope) error {
 	out.MachineImages = *(*[]MachineImage)(unsafe.Pointer(&in.MachineImages))
+	out.WorkerPools = *(*[]WorkerPoolStatus)(unsafe.Pointer(&in.WorkerPools))
+	out.Machines = *(*[]MachineStatus)(unsafe.Pointer(&in.Machines))
+	out.HibernatedDevices = *(*[]HibernatedDevice)(unsafe.Pointer(&in.HibernatedDevices))
+	out.AdoptedDevices = *(*[]AdoptedDevice)(unsafe.Pointer(&in.AdoptedDevices))
 	return nil
 }
 
@@ -10,9 +10,26 @@
 package v1alpha1
 
 import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdoptedDevice) DeepCopyInto(out *AdoptedDevice) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdoptedDevice.
+func (in *AdoptedDevice) DeepCopy() *AdoptedDevice {
+	if in == nil {
+		return nil
+	}
+	out := new(AdoptedDevice)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CloudProfileConfig) DeepCopyInto(out *CloudProfileConfig) {
 	*out = *in
@@ -24,6 +41,13 @@ func (in *CloudProfileConfig) DeepCopyInto(out *CloudProfileConfig) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.MachineTimeouts != nil {
+		in, out := &in.MachineTimeouts, &out.MachineTimeouts
+		*out = make([]MachineTimeouts, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -49,6 +73,11 @@ func (in *CloudProfileConfig) DeepCopyObject() runtime.Object {
 func (in *ControlPlaneConfig) DeepCopyInto(out *ControlPlaneConfig) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
+	if in.HibernationMode != nil {
+		in, out := &in.HibernationMode, &out.HibernationMode
+		*out = new(string)
+		**out = **in
+	}
 	return
 }
 
@@ -70,6 +99,120 @@ func (in *ControlPlaneConfig) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceAdoption) DeepCopyInto(out *DeviceAdoption) {
+	*out = *in
+	if in.DeviceIDs != nil {
+		in, out := &in.DeviceIDs, &out.DeviceIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Bootstrap != nil {
+		in, out := &in.Bootstrap, &out.Bootstrap
+		*out = new(string)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceAdoption.
+func (in *DeviceAdoption) DeepCopy() *DeviceAdoption {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceAdoption)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskSelector) DeepCopyInto(out *DiskSelector) {
+	*out = *in
+	if in.Types != nil {
+		in, out := &in.Types, &out.Types
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MinSize != nil {
+		in, out := &in.MinSize, &out.MinSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.MaxSize != nil {
+		in, out := &in.MaxSize, &out.MaxSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskSelector.
+func (in *DiskSelector) DeepCopy() *DiskSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HibernatedDevice) DeepCopyInto(out *HibernatedDevice) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HibernatedDevice.
+func (in *HibernatedDevice) DeepCopy() *HibernatedDevice {
+	if in == nil {
+		return nil
+	}
+	out := new(HibernatedDevice)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPXE) DeepCopyInto(out *IPXE) {
+	*out = *in
+	if in.ScriptURL != nil {
+		in, out := &in.ScriptURL, &out.ScriptURL
+		*out = new(string)
+		**out = **in
+	}
+	if in.Script != nil {
+		in, out := &in.Script, &out.Script
+		*out = new(string)
+		**out = **in
+	}
+	if in.KernelArgs != nil {
+		in, out := &in.KernelArgs, &out.KernelArgs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AlwaysPXE != nil {
+		in, out := &in.AlwaysPXE, &out.AlwaysPXE
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPXE.
+func (in *IPXE) DeepCopy() *IPXE {
+	if in == nil {
+		return nil
+	}
+	out := new(IPXE)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InfrastructureConfig) DeepCopyInto(out *InfrastructureConfig) {
 	*out = *in
@@ -120,9 +263,35 @@ func (in *InfrastructureStatus) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogicalVolume) DeepCopyInto(out *LogicalVolume) {
+	*out = *in
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogicalVolume.
+func (in *LogicalVolume) DeepCopy() *LogicalVolume {
+	if in == nil {
+		return nil
+	}
+	out := new(LogicalVolume)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MachineImage) DeepCopyInto(out *MachineImage) {
 	*out = *in
+	if in.Architecture != nil {
+		in, out := &in.Architecture, &out.Architecture
+		*out = new(string)
+		**out = **in
+	}
 	return
 }
 
@@ -139,6 +308,11 @@ func (in *MachineImage) DeepCopy() *MachineImage {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MachineImageVersion) DeepCopyInto(out *MachineImageVersion) {
 	*out = *in
+	if in.Architecture != nil {
+		in, out := &in.Architecture, &out.Architecture
+		*out = new(string)
+		**out = **in
+	}
 	return
 }
 
@@ -158,7 +332,9 @@ func (in *MachineImages) DeepCopyInto(out *MachineImages) {
 	if in.Versions != nil {
 		in, out := &in.Versions, &out.Versions
 		*out = make([]MachineImageVersion, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	return
 }
@@ -173,6 +349,345 @@ func (in *MachineImages) DeepCopy() *MachineImages {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineStatus) DeepCopyInto(out *MachineStatus) {
+	*out = *in
+	if in.ReservationID != nil {
+		in, out := &in.ReservationID, &out.ReservationID
+		*out = new(string)
+		**out = **in
+	}
+	if in.PublicIPs != nil {
+		in, out := &in.PublicIPs, &out.PublicIPs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PrivateIPs != nil {
+		in, out := &in.PrivateIPs, &out.PrivateIPs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CreatedAt != nil {
+		in, out := &in.CreatedAt, &out.CreatedAt
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineStatus.
+func (in *MachineStatus) DeepCopy() *MachineStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineTimeouts) DeepCopyInto(out *MachineTimeouts) {
+	*out = *in
+	if in.PlanFamilies != nil {
+		in, out := &in.PlanFamilies, &out.PlanFamilies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MachineImages != nil {
+		in, out := &in.MachineImages, &out.MachineImages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CreationTimeout != nil {
+		in, out := &in.CreationTimeout, &out.CreationTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.HealthTimeout != nil {
+		in, out := &in.HealthTimeout, &out.HealthTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.DrainTimeout != nil {
+		in, out := &in.DrainTimeout, &out.DrainTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineTimeouts.
+func (in *MachineTimeouts) DeepCopy() *MachineTimeouts {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineTimeouts)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReservationReport) DeepCopyInto(out *ReservationReport) {
+	*out = *in
+	if in.InUse != nil {
+		in, out := &in.InUse, &out.InUse
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Free != nil {
+		in, out := &in.Free, &out.Free
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReservationReport.
+func (in *ReservationReport) DeepCopy() *ReservationReport {
+	if in == nil {
+		return nil
+	}
+	out := new(ReservationReport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReservationSelector) DeepCopyInto(out *ReservationSelector) {
+	*out = *in
+	if in.Plans != nil {
+		in, out := &in.Plans, &out.Plans
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Facilities != nil {
+		in, out := &in.Facilities, &out.Facilities
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Metros != nil {
+		in, out := &in.Metros, &out.Metros
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReservationSelector.
+func (in *ReservationSelector) DeepCopy() *ReservationSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(ReservationSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Storage) DeepCopyInto(out *Storage) {
+	*out = *in
+	if in.Disks != nil {
+		in, out := &in.Disks, &out.Disks
+		*out = make([]StorageDisk, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RAID != nil {
+		in, out := &in.RAID, &out.RAID
+		*out = make([]StorageRAID, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Filesystems != nil {
+		in, out := &in.Filesystems, &out.Filesystems
+		*out = make([]StorageFilesystem, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Storage.
+func (in *Storage) DeepCopy() *Storage {
+	if in == nil {
+		return nil
+	}
+	out := new(Storage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageDisk) DeepCopyInto(out *StorageDisk) {
+	*out = *in
+	if in.WipeTable != nil {
+		in, out := &in.WipeTable, &out.WipeTable
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Partitions != nil {
+		in, out := &in.Partitions, &out.Partitions
+		*out = make([]StoragePartition, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageDisk.
+func (in *StorageDisk) DeepCopy() *StorageDisk {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageDisk)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageFilesystem) DeepCopyInto(out *StorageFilesystem) {
+	*out = *in
+	if in.Point != nil {
+		in, out := &in.Point, &out.Point
+		*out = new(string)
+		**out = **in
+	}
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageFilesystem.
+func (in *StorageFilesystem) DeepCopy() *StorageFilesystem {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageFilesystem)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StoragePartition) DeepCopyInto(out *StoragePartition) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StoragePartition.
+func (in *StoragePartition) DeepCopy() *StoragePartition {
+	if in == nil {
+		return nil
+	}
+	out := new(StoragePartition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StorageRAID) DeepCopyInto(out *StorageRAID) {
+	*out = *in
+	if in.Devices != nil {
+		in, out := &in.Devices, &out.Devices
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StorageRAID.
+func (in *StorageRAID) DeepCopy() *StorageRAID {
+	if in == nil {
+		return nil
+	}
+	out := new(StorageRAID)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpdateStrategyStatus) DeepCopyInto(out *UpdateStrategyStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpdateStrategyStatus.
+func (in *UpdateStrategyStatus) DeepCopy() *UpdateStrategyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UpdateStrategyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VLAN) DeepCopyInto(out *VLAN) {
+	*out = *in
+	if in.ID != nil {
+		in, out := &in.ID, &out.ID
+		*out = new(int32)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VLAN.
+func (in *VLAN) DeepCopy() *VLAN {
+	if in == nil {
+		return nil
+	}
+	out := new(VLAN)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeLayout) DeepCopyInto(out *VolumeLayout) {
+	*out = *in
+	if in.Disks != nil {
+		in, out := &in.Disks, &out.Disks
+		*out = new(DiskSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RAIDLevel != nil {
+		in, out := &in.RAIDLevel, &out.RAIDLevel
+		*out = new(string)
+		**out = **in
+	}
+	if in.Filesystem != nil {
+		in, out := &in.Filesystem, &out.Filesystem
+		*out = new(string)
+		**out = **in
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]LogicalVolume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeLayout.
+func (in *VolumeLayout) DeepCopy() *VolumeLayout {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeLayout)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkerConfig) DeepCopyInto(out *WorkerConfig) {
 	*out = *in
@@ -182,11 +697,91 @@ func (in *WorkerConfig) DeepCopyInto(out *WorkerConfig) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ReservationSelector != nil {
+		in, out := &in.ReservationSelector, &out.ReservationSelector
+		*out = new(ReservationSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.ReservedDevicesOnly != nil {
 		in, out := &in.ReservedDevicesOnly, &out.ReservedDevicesOnly
 		*out = new(bool)
 		**out = **in
 	}
+	if in.OnDemandOverflow != nil {
+		in, out := &in.OnDemandOverflow, &out.OnDemandOverflow
+		*out = new(bool)
+		**out = **in
+	}
+	if in.BillingCycle != nil {
+		in, out := &in.BillingCycle, &out.BillingCycle
+		*out = new(string)
+		**out = **in
+	}
+	if in.Tags != nil {
+		in, out := &in.Tags, &out.Tags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TagsFromPoolLabels != nil {
+		in, out := &in.TagsFromPoolLabels, &out.TagsFromPoolLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CustomData != nil {
+		in, out := &in.CustomData, &out.CustomData
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Network != nil {
+		in, out := &in.Network, &out.Network
+		*out = new(WorkerNetwork)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Storage != nil {
+		in, out := &in.Storage, &out.Storage
+		*out = new(Storage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VolumeLayout != nil {
+		in, out := &in.VolumeLayout, &out.VolumeLayout
+		*out = new(VolumeLayout)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SpotInstance != nil {
+		in, out := &in.SpotInstance, &out.SpotInstance
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SpotPriceMax != nil {
+		in, out := &in.SpotPriceMax, &out.SpotPriceMax
+		*out = new(string)
+		**out = **in
+	}
+	if in.IPXE != nil {
+		in, out := &in.IPXE, &out.IPXE
+		*out = new(IPXE)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SSH != nil {
+		in, out := &in.SSH, &out.SSH
+		*out = new(WorkerSSH)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.FallbackMachineTypes != nil {
+		in, out := &in.FallbackMachineTypes, &out.FallbackMachineTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.HibernationMode != nil {
+		in, out := &in.HibernationMode, &out.HibernationMode
+		*out = new(string)
+		**out = **in
+	}
+	if in.AdoptDevices != nil {
+		in, out := &in.AdoptDevices, &out.AdoptDevices
+		*out = new(DeviceAdoption)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -208,6 +803,96 @@ func (in *WorkerConfig) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkerNetwork) DeepCopyInto(out *WorkerNetwork) {
+	*out = *in
+	if in.Type != nil {
+		in, out := &in.Type, &out.Type
+		*out = new(string)
+		**out = **in
+	}
+	if in.VLANs != nil {
+		in, out := &in.VLANs, &out.VLANs
+		*out = make([]VLAN, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkerNetwork.
+func (in *WorkerNetwork) DeepCopy() *WorkerNetwork {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkerNetwork)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkerPoolStatus) DeepCopyInto(out *WorkerPoolStatus) {
+	*out = *in
+	if in.ReservationIDs != nil {
+		in, out := &in.ReservationIDs, &out.ReservationIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Reservations != nil {
+		in, out := &in.Reservations, &out.Reservations
+		*out = new(ReservationReport)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UpdateStrategies != nil {
+		in, out := &in.UpdateStrategies, &out.UpdateStrategies
+		*out = make([]UpdateStrategyStatus, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkerPoolStatus.
+func (in *WorkerPoolStatus) DeepCopy() *WorkerPoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkerPoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkerSSH) DeepCopyInto(out *WorkerSSH) {
+	*out = *in
+	if in.ProjectKeyIDs != nil {
+		in, out := &in.ProjectKeyIDs, &out.ProjectKeyIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.UserKeyIDs != nil {
+		in, out := &in.UserKeyIDs, &out.UserKeyIDs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllProjectKeys != nil {
+		in, out := &in.AllProjectKeys, &out.AllProjectKeys
+		*out = new(bool)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkerSSH.
+func (in *WorkerSSH) DeepCopy() *WorkerSSH {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkerSSH)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkerStatus) DeepCopyInto(out *WorkerStatus) {
 	*out = *in
@@ -215,6 +900,32 @@ func (in *WorkerStatus) DeepCopyInto(out *WorkerStatus) {
 	if in.MachineImages != nil {
 		in, out := &in.MachineImages, &out.MachineImages
 		*out = make([]MachineImage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.WorkerPools != nil {
+		in, out := &in.WorkerPools, &out.WorkerPools
+		*out = make([]WorkerPoolStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Machines != nil {
+		in, out := &in.Machines, &out.Machines
+		*out = make([]MachineStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.HibernatedDevices != nil {
+		in, out := &in.HibernatedDevices, &out.HibernatedDevices
+		*out = make([]HibernatedDevice, len(*in))
+		copy(*out, *in)
+	}
+	if in.AdoptedDevices != nil {
+		in, out := &in.AdoptedDevices, &out.AdoptedDevices
+		*out = make([]AdoptedDevice, len(*in))
 		copy(*out, *in)
 	}
 	return
@@ -6,16 +6,28 @@ package validation
 
 import (
 	"fmt"
+	"strings"
 
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
 
 	api "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal"
 )
 
-// ValidateCloudProfileConfig validates a CloudProfileConfig object.
-func ValidateCloudProfileConfig(cloudProfile *api.CloudProfileConfig) field.ErrorList {
+// ValidateCloudProfileConfig validates a CloudProfileConfig object. The given machine types are the ones offered by the
+// CloudProfile, every machine image version must be available for each of their architectures.
+func ValidateCloudProfileConfig(cloudProfile *api.CloudProfileConfig, machineTypes []gardencorev1beta1.MachineType) field.ErrorList {
 	allErrs := field.ErrorList{}
 
+	architectures := sets.New[string]()
+	for _, machineType := range machineTypes {
+		architectures.Insert(ptr.Deref(machineType.Architecture, v1beta1constants.ArchitectureAMD64))
+	}
+
 	machineImagesPath := field.NewPath("machineImages")
 	if len(cloudProfile.MachineImages) == 0 {
 		allErrs = append(allErrs, field.Required(machineImagesPath, "must provide at least one machine image"))
@@ -30,6 +42,11 @@ func ValidateCloudProfileConfig(cloudProfile *api.CloudProfileConfig) field.Erro
 		if len(machineImage.Versions) == 0 {
 			allErrs = append(allErrs, field.Required(idxPath.Child("versions"), fmt.Sprintf("must provide at least one version for machine image %q", machineImage.Name)))
 		}
+
+		var (
+			versions             []string
+			versionArchitectures = map[string]sets.Set[string]{}
+		)
 		for j, version := range machineImage.Versions {
 			jdxPath := idxPath.Child("versions").Index(j)
 
@@ -39,6 +56,65 @@ func ValidateCloudProfileConfig(cloudProfile *api.CloudProfileConfig) field.Erro
 			if len(version.ID) == 0 {
 				allErrs = append(allErrs, field.Required(jdxPath.Child("id"), "must provide an id"))
 			}
+
+			arch := ptr.Deref(version.Architecture, v1beta1constants.ArchitectureAMD64)
+			if !sets.New(v1beta1constants.ValidArchitectures...).Has(arch) {
+				allErrs = append(allErrs, field.NotSupported(jdxPath.Child("architecture"), arch, v1beta1constants.ValidArchitectures))
+			}
+
+			if _, ok := versionArchitectures[version.Version]; !ok {
+				versions = append(versions, version.Version)
+				versionArchitectures[version.Version] = sets.New[string]()
+			}
+			if versionArchitectures[version.Version].Has(arch) {
+				allErrs = append(allErrs, field.Duplicate(jdxPath, fmt.Sprintf("%s/%s", version.Version, arch)))
+			}
+			versionArchitectures[version.Version].Insert(arch)
+		}
+
+		for _, version := range versions {
+			for _, arch := range sets.List(architectures.Difference(versionArchitectures[version])) {
+				allErrs = append(allErrs, field.Required(idxPath.Child("versions"), fmt.Sprintf("must provide an image for architecture %q in version %q of machine image %q because the cloud profile offers machine types of this architecture", arch, version, machineImage.Name)))
+			}
+		}
+	}
+
+	for i, timeouts := range cloudProfile.MachineTimeouts {
+		allErrs = append(allErrs, validateMachineTimeouts(timeouts, field.NewPath("machineTimeouts").Index(i))...)
+	}
+
+	return allErrs
+}
+
+func validateMachineTimeouts(timeouts api.MachineTimeouts, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for i, family := range timeouts.PlanFamilies {
+		if len(family) == 0 {
+			allErrs = append(allErrs, field.Required(fldPath.Child("planFamilies").Index(i), "must provide a plan family"))
+		} else if strings.Contains(family, ".") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("planFamilies").Index(i), family, "must not contain a dot"))
+		}
+	}
+	for i, name := range timeouts.MachineImages {
+		if len(name) == 0 {
+			allErrs = append(allErrs, field.Required(fldPath.Child("machineImages").Index(i), "must provide a machine image name"))
+		}
+	}
+
+	if timeouts.CreationTimeout == nil && timeouts.HealthTimeout == nil && timeouts.DrainTimeout == nil {
+		allErrs = append(allErrs, field.Required(fldPath, "must provide at least one timeout"))
+	}
+	for _, timeout := range []struct {
+		name     string
+		duration *metav1.Duration
+	}{
+		{"creationTimeout", timeouts.CreationTimeout},
+		{"healthTimeout", timeouts.HealthTimeout},
+		{"drainTimeout", timeouts.DrainTimeout},
+	} {
+		if timeout.duration != nil && timeout.duration.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child(timeout.name), timeout.duration.Duration.String(), "must be positive"))
 		}
 	}
 
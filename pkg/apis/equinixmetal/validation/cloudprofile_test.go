@@ -5,10 +5,15 @@
 package validation_test
 
 import (
+	"time"
+
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	. "github.com/onsi/gomega/gstruct"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
 
 	api "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal"
 	. "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal/validation"
@@ -16,7 +21,10 @@ import (
 
 var _ = Describe("CloudProfileConfig validation", func() {
 	Describe("#ValidateCloudProfileConfig", func() {
-		var cloudProfileConfig *api.CloudProfileConfig
+		var (
+			cloudProfileConfig *api.CloudProfileConfig
+			machineTypes       []gardencorev1beta1.MachineType
+		)
 
 		BeforeEach(func() {
 			cloudProfileConfig = &api.CloudProfileConfig{
@@ -32,13 +40,17 @@ var _ = Describe("CloudProfileConfig validation", func() {
 					},
 				},
 			}
+			machineTypes = []gardencorev1beta1.MachineType{
+				{Name: "c3.small.x86"},
+				{Name: "c3.large.arm64", Architecture: ptr.To("arm64")},
+			}
 		})
 
 		Context("machine image validation", func() {
 			It("should enforce that at least one machine image has been defined", func() {
 				cloudProfileConfig.MachineImages = []api.MachineImages{}
 
-				errorList := ValidateCloudProfileConfig(cloudProfileConfig)
+				errorList := ValidateCloudProfileConfig(cloudProfileConfig, nil)
 
 				Expect(errorList).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
 					"Type":  Equal(field.ErrorTypeRequired),
@@ -49,7 +61,7 @@ var _ = Describe("CloudProfileConfig validation", func() {
 			It("should forbid unsupported machine image configuration", func() {
 				cloudProfileConfig.MachineImages = []api.MachineImages{{}}
 
-				errorList := ValidateCloudProfileConfig(cloudProfileConfig)
+				errorList := ValidateCloudProfileConfig(cloudProfileConfig, nil)
 
 				Expect(errorList).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
 					"Type":  Equal(field.ErrorTypeRequired),
@@ -68,7 +80,7 @@ var _ = Describe("CloudProfileConfig validation", func() {
 					},
 				}
 
-				errorList := ValidateCloudProfileConfig(cloudProfileConfig)
+				errorList := ValidateCloudProfileConfig(cloudProfileConfig, nil)
 
 				Expect(errorList).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
 					"Type":  Equal(field.ErrorTypeRequired),
@@ -79,5 +91,93 @@ var _ = Describe("CloudProfileConfig validation", func() {
 				}))))
 			})
 		})
+
+		Context("architecture validation", func() {
+			It("should require an image for each architecture offered by the machine types", func() {
+				errorList := ValidateCloudProfileConfig(cloudProfileConfig, machineTypes)
+
+				Expect(errorList).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeRequired),
+					"Field":  Equal("machineImages[0].versions"),
+					"Detail": ContainSubstring(`architecture "arm64" in version "1.2.3"`),
+				}))))
+			})
+
+			It("should allow images for all offered architectures", func() {
+				cloudProfileConfig.MachineImages[0].Versions = append(cloudProfileConfig.MachineImages[0].Versions, api.MachineImageVersion{
+					Version:      "1.2.3",
+					ID:           "some-arm64-image-id",
+					Architecture: ptr.To("arm64"),
+				})
+
+				Expect(ValidateCloudProfileConfig(cloudProfileConfig, machineTypes)).To(BeEmpty())
+			})
+
+			It("should forbid unsupported and duplicate architectures", func() {
+				cloudProfileConfig.MachineImages[0].Versions = append(cloudProfileConfig.MachineImages[0].Versions,
+					api.MachineImageVersion{
+						Version:      "1.2.3",
+						ID:           "some-other-image-id",
+						Architecture: ptr.To("amd64"),
+					},
+					api.MachineImageVersion{
+						Version:      "1.2.3",
+						ID:           "some-s390x-image-id",
+						Architecture: ptr.To("s390x"),
+					},
+				)
+
+				errorList := ValidateCloudProfileConfig(cloudProfileConfig, nil)
+
+				Expect(errorList).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeDuplicate),
+					"Field": Equal("machineImages[0].versions[1]"),
+				})), PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeNotSupported),
+					"Field": Equal("machineImages[0].versions[2].architecture"),
+				}))))
+			})
+		})
+
+		Context("machine timeouts validation", func() {
+			It("should allow valid machine timeouts", func() {
+				cloudProfileConfig.MachineTimeouts = []api.MachineTimeouts{
+					{PlanFamilies: []string{"n3"}, MachineImages: []string{"flatcar"}, CreationTimeout: &metav1.Duration{Duration: 40 * time.Minute}},
+					{HealthTimeout: &metav1.Duration{Duration: 20 * time.Minute}},
+				}
+
+				Expect(ValidateCloudProfileConfig(cloudProfileConfig, nil)).To(BeEmpty())
+			})
+
+			It("should forbid invalid machine timeouts", func() {
+				cloudProfileConfig.MachineTimeouts = []api.MachineTimeouts{
+					{PlanFamilies: []string{"", "n3.xlarge"}, MachineImages: []string{""}, DrainTimeout: &metav1.Duration{Duration: -time.Minute}},
+					{PlanFamilies: []string{"n3"}},
+				}
+
+				Expect(ValidateCloudProfileConfig(cloudProfileConfig, nil)).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeRequired),
+						"Field": Equal("machineTimeouts[0].planFamilies[0]"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeInvalid),
+						"Field": Equal("machineTimeouts[0].planFamilies[1]"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeRequired),
+						"Field": Equal("machineTimeouts[0].machineImages[0]"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeInvalid),
+						"Field": Equal("machineTimeouts[0].drainTimeout"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeRequired),
+						"Field": Equal("machineTimeouts[1]"),
+					})),
+				))
+			})
+		})
 	})
 })
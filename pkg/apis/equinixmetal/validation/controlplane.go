@@ -0,0 +1,23 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package validation
+
+import (
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	api "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal"
+)
+
+// ValidateControlPlaneConfig validates a ControlPlaneConfig object of a shoot.
+func ValidateControlPlaneConfig(controlPlaneConfig *api.ControlPlaneConfig, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if controlPlaneConfig.HibernationMode != nil && !validHibernationModes.Has(*controlPlaneConfig.HibernationMode) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("hibernationMode"), *controlPlaneConfig.HibernationMode, sets.List(validHibernationModes)))
+	}
+
+	return allErrs
+}
@@ -0,0 +1,41 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package validation_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+
+	api "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal"
+	. "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal/validation"
+)
+
+var _ = Describe("ControlPlaneConfig validation", func() {
+	Describe("#ValidateControlPlaneConfig", func() {
+		var fldPath = field.NewPath("controlPlaneConfig")
+
+		It("should allow an empty control plane config", func() {
+			Expect(ValidateControlPlaneConfig(&api.ControlPlaneConfig{}, fldPath)).To(BeEmpty())
+		})
+
+		It("should allow a supported hibernation mode", func() {
+			controlPlaneConfig := &api.ControlPlaneConfig{HibernationMode: ptr.To(api.HibernationModePowerOff)}
+
+			Expect(ValidateControlPlaneConfig(controlPlaneConfig, fldPath)).To(BeEmpty())
+		})
+
+		It("should forbid an unsupported hibernation mode", func() {
+			controlPlaneConfig := &api.ControlPlaneConfig{HibernationMode: ptr.To("Suspend")}
+
+			Expect(ValidateControlPlaneConfig(controlPlaneConfig, fldPath)).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+				"Type":  Equal(field.ErrorTypeNotSupported),
+				"Field": Equal("controlPlaneConfig.hibernationMode"),
+			}))))
+		})
+	})
+})
@@ -0,0 +1,476 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+
+	api "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal"
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal/helper"
+)
+
+var validBillingCycles = sets.New(
+	api.BillingCycleHourly,
+	api.BillingCycleDaily,
+	api.BillingCycleMonthly,
+	api.BillingCycleYearly,
+)
+
+var (
+	validRAIDLevels        = sets.New("raid0", "raid1", "raid5", "raid6", "raid10")
+	validFilesystemFormats = sets.New("ext4", "xfs", "vfat", "swap")
+	partitionSizeRegex     = regexp.MustCompile(`^\+?[0-9]+(K|M|G|T|KB|MB|GB|TB)?$`)
+)
+
+var (
+	validVolumeRAIDLevels  = sets.New(api.VolumeRAIDLevelRAID0, api.VolumeRAIDLevelRAID1)
+	validVolumeFilesystems = sets.New(api.VolumeFilesystemExt4, api.VolumeFilesystemXFS)
+	validDiskTypes         = sets.New(api.DiskTypeNVMe, api.DiskTypeSSD, api.DiskTypeHDD)
+	validLogicalVolumes    = sets.New(api.LogicalVolumeContainerd, api.LogicalVolumeKubelet, api.LogicalVolumeLog)
+)
+
+var validHibernationModes = sets.New(
+	api.HibernationModeDelete,
+	api.HibernationModePowerOff,
+)
+
+var validAdoptionBootstraps = sets.New(
+	api.AdoptionBootstrapReinstall,
+	api.AdoptionBootstrapUserData,
+)
+
+var validNetworkTypes = sets.New(
+	api.NetworkTypeLayer3,
+	api.NetworkTypeHybrid,
+	api.NetworkTypeHybridBonded,
+	api.NetworkTypeLayer2Bonded,
+	api.NetworkTypeLayer2Individual,
+)
+
+// ValidateWorkerConfig validates a WorkerConfig object of a worker pool using the given machine image.
+func ValidateWorkerConfig(workerConfig *api.WorkerConfig, machineImage *api.MachineImageVersion, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if workerConfig.BillingCycle != nil && !validBillingCycles.Has(*workerConfig.BillingCycle) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("billingCycle"), *workerConfig.BillingCycle, sets.List(validBillingCycles)))
+	}
+
+	if workerConfig.ReservationSelector != nil {
+		allErrs = append(allErrs, validateReservationSelector(workerConfig.ReservationSelector, fldPath.Child("reservationSelector"))...)
+	}
+
+	if ptr.Deref(workerConfig.OnDemandOverflow, false) {
+		onDemandOverflowPath := fldPath.Child("onDemandOverflow")
+		if len(workerConfig.ReservationIDs) == 0 && workerConfig.ReservationSelector == nil {
+			allErrs = append(allErrs, field.Forbidden(onDemandOverflowPath, "must only be set for worker pools with hardware reservations"))
+		}
+		if ptr.Deref(workerConfig.ReservedDevicesOnly, false) {
+			allErrs = append(allErrs, field.Forbidden(onDemandOverflowPath, "must not be combined with reservedDevicesOnly"))
+		}
+	}
+
+	tags := sets.New[string]()
+	for i, tag := range workerConfig.Tags {
+		idxPath := fldPath.Child("tags").Index(i)
+		if len(tag) == 0 {
+			allErrs = append(allErrs, field.Required(idxPath, "must not be empty"))
+		} else if tags.Has(tag) {
+			allErrs = append(allErrs, field.Duplicate(idxPath, tag))
+		}
+		tags.Insert(tag)
+	}
+
+	for i, key := range workerConfig.TagsFromPoolLabels {
+		for _, msg := range validation.IsQualifiedName(key) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("tagsFromPoolLabels").Index(i), key, msg))
+		}
+	}
+
+	if workerConfig.CustomData != nil {
+		var customData map[string]interface{}
+		if err := json.Unmarshal(workerConfig.CustomData.Raw, &customData); err != nil || customData == nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("customData"), string(workerConfig.CustomData.Raw), "must be a JSON object"))
+		} else if _, ok := customData[api.CustomDataKeyGardener]; ok {
+			allErrs = append(allErrs, field.Forbidden(fldPath.Child("customData"), fmt.Sprintf("key %q is reserved", api.CustomDataKeyGardener)))
+		}
+	}
+
+	if workerConfig.Network != nil {
+		allErrs = append(allErrs, validateWorkerNetwork(workerConfig.Network, fldPath.Child("network"))...)
+	}
+
+	if workerConfig.Storage != nil {
+		allErrs = append(allErrs, validateStorage(workerConfig.Storage, fldPath.Child("storage"))...)
+	}
+
+	if workerConfig.VolumeLayout != nil {
+		allErrs = append(allErrs, validateVolumeLayout(workerConfig.VolumeLayout, fldPath.Child("volumeLayout"))...)
+	}
+
+	if workerConfig.SpotPriceMax != nil {
+		spotPriceMaxPath := fldPath.Child("spotPriceMax")
+		if !ptr.Deref(workerConfig.SpotInstance, false) {
+			allErrs = append(allErrs, field.Forbidden(spotPriceMaxPath, "must only be set for spot instances"))
+		}
+		if price, err := strconv.ParseFloat(*workerConfig.SpotPriceMax, 64); err != nil || price <= 0 {
+			allErrs = append(allErrs, field.Invalid(spotPriceMaxPath, *workerConfig.SpotPriceMax, "must be a positive decimal number"))
+		}
+	}
+
+	if workerConfig.IPXE != nil {
+		allErrs = append(allErrs, validateIPXE(workerConfig.IPXE, machineImage, fldPath.Child("ipxe"))...)
+	}
+
+	if workerConfig.SSH != nil {
+		allErrs = append(allErrs, validateWorkerSSH(workerConfig.SSH, fldPath.Child("ssh"))...)
+	}
+
+	fallbackMachineTypes := sets.New[string]()
+	for i, machineType := range workerConfig.FallbackMachineTypes {
+		idxPath := fldPath.Child("fallbackMachineTypes").Index(i)
+		if len(machineType) == 0 {
+			allErrs = append(allErrs, field.Required(idxPath, "must not be empty"))
+		} else if fallbackMachineTypes.Has(machineType) {
+			allErrs = append(allErrs, field.Duplicate(idxPath, machineType))
+		}
+		fallbackMachineTypes.Insert(machineType)
+	}
+	if len(workerConfig.FallbackMachineTypes) > 0 && (len(workerConfig.ReservationIDs) > 0 || workerConfig.ReservationSelector != nil) {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("fallbackMachineTypes"), "must not be combined with hardware reservations as they are bound to a plan"))
+	}
+
+	if workerConfig.HibernationMode != nil && !validHibernationModes.Has(*workerConfig.HibernationMode) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("hibernationMode"), *workerConfig.HibernationMode, sets.List(validHibernationModes)))
+	}
+
+	if workerConfig.AdoptDevices != nil {
+		allErrs = append(allErrs, validateDeviceAdoption(workerConfig.AdoptDevices, workerConfig.IPXE, fldPath.Child("adoptDevices"))...)
+	}
+
+	return allErrs
+}
+
+func validateDeviceAdoption(adoption *api.DeviceAdoption, ipxe *api.IPXE, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if len(adoption.DeviceIDs) == 0 && len(adoption.Tags) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath, "must provide either deviceIDs or tags"))
+	}
+
+	for name, values := range map[string][]string{
+		"deviceIDs": adoption.DeviceIDs,
+		"tags":      adoption.Tags,
+	} {
+		seen := sets.New[string]()
+		for i, value := range values {
+			idxPath := fldPath.Child(name).Index(i)
+			if len(value) == 0 {
+				allErrs = append(allErrs, field.Required(idxPath, "must not be empty"))
+			} else if seen.Has(value) {
+				allErrs = append(allErrs, field.Duplicate(idxPath, value))
+			}
+			seen.Insert(value)
+		}
+	}
+
+	bootstrap := ptr.Deref(adoption.Bootstrap, api.AdoptionBootstrapReinstall)
+	if !validAdoptionBootstraps.Has(bootstrap) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("bootstrap"), bootstrap, sets.List(validAdoptionBootstraps)))
+	} else if bootstrap == api.AdoptionBootstrapReinstall && ipxe != nil && ipxe.Script != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("bootstrap"), "devices with an inline iPXE script cannot be reinstalled, use an iPXE script URL or the UserData bootstrap instead"))
+	}
+
+	return allErrs
+}
+
+func validateWorkerNetwork(network *api.WorkerNetwork, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	networkType := ptr.Deref(network.Type, api.NetworkTypeLayer3)
+	if !validNetworkTypes.Has(networkType) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("type"), networkType, sets.List(validNetworkTypes)))
+	}
+
+	if networkType == api.NetworkTypeLayer3 && len(network.VLANs) > 0 {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("vlans"), fmt.Sprintf("VLANs cannot be attached to devices with network type %q", api.NetworkTypeLayer3)))
+	}
+
+	names := sets.New[string]()
+	for i, vlan := range network.VLANs {
+		idxPath := fldPath.Child("vlans").Index(i)
+
+		if len(vlan.Name) == 0 {
+			allErrs = append(allErrs, field.Required(idxPath.Child("name"), "must provide a name"))
+		} else if names.Has(vlan.Name) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("name"), vlan.Name))
+		}
+		names.Insert(vlan.Name)
+
+		if vlan.ID != nil && (*vlan.ID < 2 || *vlan.ID > 3999) {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("id"), *vlan.ID, "must be between 2 and 3999"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateVolumeLayout(layout *api.VolumeLayout, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if disks := layout.Disks; disks != nil {
+		disksPath := fldPath.Child("disks")
+
+		types := sets.New[string]()
+		for i, diskType := range disks.Types {
+			idxPath := disksPath.Child("types").Index(i)
+			if !validDiskTypes.Has(diskType) {
+				allErrs = append(allErrs, field.NotSupported(idxPath, diskType, sets.List(validDiskTypes)))
+			} else if types.Has(diskType) {
+				allErrs = append(allErrs, field.Duplicate(idxPath, diskType))
+			}
+			types.Insert(diskType)
+		}
+
+		if disks.MinSize != nil && disks.MinSize.Sign() < 0 {
+			allErrs = append(allErrs, field.Invalid(disksPath.Child("minSize"), disks.MinSize.String(), "must not be negative"))
+		}
+		if disks.MaxSize != nil {
+			if disks.MaxSize.Sign() <= 0 {
+				allErrs = append(allErrs, field.Invalid(disksPath.Child("maxSize"), disks.MaxSize.String(), "must be positive"))
+			} else if disks.MinSize != nil && disks.MaxSize.Cmp(*disks.MinSize) < 0 {
+				allErrs = append(allErrs, field.Invalid(disksPath.Child("maxSize"), disks.MaxSize.String(), "must not be less than the minimum size"))
+			}
+		}
+	}
+
+	if layout.RAIDLevel != nil && !validVolumeRAIDLevels.Has(*layout.RAIDLevel) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("raidLevel"), *layout.RAIDLevel, sets.List(validVolumeRAIDLevels)))
+	}
+
+	if layout.Filesystem != nil && !validVolumeFilesystems.Has(*layout.Filesystem) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("filesystem"), *layout.Filesystem, sets.List(validVolumeFilesystems)))
+	}
+
+	var (
+		names         = sets.New[string]()
+		remainingPath *field.Path
+	)
+	for i, volume := range layout.Volumes {
+		idxPath := fldPath.Child("volumes").Index(i)
+
+		if !validLogicalVolumes.Has(volume.Name) {
+			allErrs = append(allErrs, field.NotSupported(idxPath.Child("name"), volume.Name, sets.List(validLogicalVolumes)))
+		} else if names.Has(volume.Name) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("name"), volume.Name))
+		}
+		names.Insert(volume.Name)
+
+		switch {
+		case volume.Size == nil && remainingPath != nil:
+			allErrs = append(allErrs, field.Forbidden(idxPath.Child("size"), fmt.Sprintf("must be set as %s already uses the remaining space of the volume group", remainingPath)))
+		case volume.Size == nil:
+			remainingPath = idxPath
+		case volume.Size.Sign() <= 0:
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("size"), volume.Size.String(), "must be positive"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateStorage(storage *api.Storage, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	// devices contains the partitions and RAID arrays which can be used by RAID arrays and filesystems.
+	devices := sets.New[string]()
+
+	disks := sets.New[string]()
+	for i, disk := range storage.Disks {
+		idxPath := fldPath.Child("disks").Index(i)
+
+		if !strings.HasPrefix(disk.Device, "/dev/") {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("device"), disk.Device, "must be a device path starting with /dev/"))
+		} else if disks.Has(disk.Device) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("device"), disk.Device))
+		}
+		disks.Insert(disk.Device)
+
+		numbers := sets.New[int32]()
+		for j, partition := range disk.Partitions {
+			partitionPath := idxPath.Child("partitions").Index(j)
+
+			if len(partition.Label) == 0 {
+				allErrs = append(allErrs, field.Required(partitionPath.Child("label"), "must provide a label"))
+			}
+			if partition.Number < 1 {
+				allErrs = append(allErrs, field.Invalid(partitionPath.Child("number"), partition.Number, "must be greater than 0"))
+			} else if numbers.Has(partition.Number) {
+				allErrs = append(allErrs, field.Duplicate(partitionPath.Child("number"), partition.Number))
+			}
+			numbers.Insert(partition.Number)
+			if !partitionSizeRegex.MatchString(partition.Size) {
+				allErrs = append(allErrs, field.Invalid(partitionPath.Child("size"), partition.Size, "must be a size like 512MB, or 0 to use the remaining space of the disk"))
+			}
+
+			devices.Insert(partitionDevice(disk.Device, partition.Number))
+		}
+	}
+
+	for i, raid := range storage.RAID {
+		idxPath := fldPath.Child("raid").Index(i)
+
+		if !strings.HasPrefix(raid.Name, "/dev/") {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("name"), raid.Name, "must be a device path starting with /dev/"))
+		} else if devices.Has(raid.Name) {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("name"), raid.Name))
+		}
+		if !validRAIDLevels.Has(raid.Level) {
+			allErrs = append(allErrs, field.NotSupported(idxPath.Child("level"), raid.Level, sets.List(validRAIDLevels)))
+		}
+		if len(raid.Devices) < 2 {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("devices"), raid.Devices, "must contain at least two devices"))
+		}
+		for j, device := range raid.Devices {
+			if !devices.Has(device) {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("devices").Index(j), device, "must be a partition of the storage layout"))
+			}
+		}
+	}
+	for _, raid := range storage.RAID {
+		devices.Insert(raid.Name)
+	}
+
+	var (
+		mountPoints = sets.New[string]()
+		hasRoot     bool
+	)
+	for i, filesystem := range storage.Filesystems {
+		idxPath := fldPath.Child("filesystems").Index(i)
+
+		if !devices.Has(filesystem.Device) {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("device"), filesystem.Device, "must be a partition or RAID array of the storage layout"))
+		}
+		if !validFilesystemFormats.Has(filesystem.Format) {
+			allErrs = append(allErrs, field.NotSupported(idxPath.Child("format"), filesystem.Format, sets.List(validFilesystemFormats)))
+		}
+
+		switch {
+		case filesystem.Format == "swap":
+			if filesystem.Point != nil {
+				allErrs = append(allErrs, field.Forbidden(idxPath.Child("point"), "must not be set for swap filesystems"))
+			}
+		case filesystem.Point == nil:
+			allErrs = append(allErrs, field.Required(idxPath.Child("point"), "must provide a mount point"))
+		case !path.IsAbs(*filesystem.Point) || path.Clean(*filesystem.Point) != *filesystem.Point:
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("point"), *filesystem.Point, "must be a clean absolute path"))
+		case mountPoints.Has(*filesystem.Point):
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("point"), *filesystem.Point))
+		default:
+			mountPoints.Insert(*filesystem.Point)
+			hasRoot = hasRoot || *filesystem.Point == "/"
+		}
+	}
+
+	if !hasRoot {
+		allErrs = append(allErrs, field.Required(fldPath.Child("filesystems"), "must contain a filesystem mounted at /"))
+	}
+
+	return allErrs
+}
+
+// partitionDevice returns the device path of the partition with the given number of the given disk, e.g. `/dev/sda1`
+// or `/dev/nvme0n1p1`.
+func partitionDevice(disk string, number int32) string {
+	if len(disk) > 0 && disk[len(disk)-1] >= '0' && disk[len(disk)-1] <= '9' {
+		return fmt.Sprintf("%sp%d", disk, number)
+	}
+	return fmt.Sprintf("%s%d", disk, number)
+}
+
+func validateReservationSelector(selector *api.ReservationSelector, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for name, values := range map[string][]string{
+		"plans":      selector.Plans,
+		"facilities": selector.Facilities,
+		"metros":     selector.Metros,
+		"tags":       selector.Tags,
+	} {
+		for i, value := range values {
+			if len(value) == 0 {
+				allErrs = append(allErrs, field.Required(fldPath.Child(name).Index(i), "must not be empty"))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+func validateWorkerSSH(ssh *api.WorkerSSH, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	for name, ids := range map[string][]string{
+		"projectKeyIDs": ssh.ProjectKeyIDs,
+		"userKeyIDs":    ssh.UserKeyIDs,
+	} {
+		seen := sets.New[string]()
+		for i, id := range ids {
+			idxPath := fldPath.Child(name).Index(i)
+			if len(id) == 0 {
+				allErrs = append(allErrs, field.Required(idxPath, "must not be empty"))
+			} else if seen.Has(id) {
+				allErrs = append(allErrs, field.Duplicate(idxPath, id))
+			}
+			seen.Insert(id)
+		}
+	}
+
+	return allErrs
+}
+
+func validateIPXE(ipxe *api.IPXE, machineImage *api.MachineImageVersion, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	if !helper.IsCustomIPXE(machineImage) {
+		return append(allErrs, field.Forbidden(fldPath, "iPXE configuration is only allowed for machine images using the custom_ipxe operating system"))
+	}
+
+	if ipxe.ScriptURL != nil && ipxe.Script != nil {
+		allErrs = append(allErrs, field.Forbidden(fldPath.Child("script"), "must not be set together with scriptUrl"))
+	}
+	if ipxe.ScriptURL == nil && ipxe.Script == nil && machineImage.IPXEScriptURL == "" {
+		allErrs = append(allErrs, field.Required(fldPath, "must provide either scriptUrl or script because the machine image does not define an iPXE script url"))
+	}
+
+	if ipxe.ScriptURL != nil {
+		scriptURL, err := helper.RenderIPXEScriptURL(*ipxe.ScriptURL, helper.IPXETemplateValues{})
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("scriptUrl"), *ipxe.ScriptURL, err.Error()))
+		} else if u, err := url.Parse(scriptURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("scriptUrl"), *ipxe.ScriptURL, "must be a valid http or https url"))
+		}
+	}
+
+	if ipxe.Script != nil {
+		if script, err := helper.RenderIPXEScript(*ipxe.Script, helper.IPXETemplateValues{}); err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("script"), *ipxe.Script, err.Error()))
+		} else if !strings.HasPrefix(script, "#!ipxe") {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("script"), *ipxe.Script, "must start with #!ipxe"))
+		}
+	}
+
+	return allErrs
+}
@@ -0,0 +1,635 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package validation_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+
+	api "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal"
+	. "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal/validation"
+)
+
+var _ = Describe("WorkerConfig validation", func() {
+	Describe("#ValidateWorkerConfig", func() {
+		var (
+			workerConfig *api.WorkerConfig
+			machineImage *api.MachineImageVersion
+			fldPath      *field.Path
+		)
+
+		BeforeEach(func() {
+			workerConfig = &api.WorkerConfig{}
+			machineImage = &api.MachineImageVersion{
+				Version: "1.2.3",
+				ID:      "custom_ipxe",
+			}
+			fldPath = field.NewPath("providerConfig")
+		})
+
+		It("should allow an empty worker config", func() {
+			Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(BeEmpty())
+		})
+
+		Context("billing cycle", func() {
+			It("should allow a supported billing cycle", func() {
+				workerConfig.BillingCycle = ptr.To("monthly")
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(BeEmpty())
+			})
+
+			It("should forbid an unsupported billing cycle", func() {
+				workerConfig.BillingCycle = ptr.To("weekly")
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeNotSupported),
+					"Field": Equal("providerConfig.billingCycle"),
+				}))))
+			})
+		})
+
+		Context("reservation selector", func() {
+			It("should allow a reservation selector", func() {
+				workerConfig.ReservationSelector = &api.ReservationSelector{
+					Plans:  []string{"c3.small.x86"},
+					Metros: []string{"ny"},
+				}
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(BeEmpty())
+			})
+
+			It("should forbid empty values", func() {
+				workerConfig.ReservationSelector = &api.ReservationSelector{
+					Facilities: []string{""},
+					Tags:       []string{"team-a", ""},
+				}
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeRequired),
+						"Field": Equal("providerConfig.reservationSelector.facilities[0]"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeRequired),
+						"Field": Equal("providerConfig.reservationSelector.tags[1]"),
+					})),
+				))
+			})
+		})
+
+		Context("tags and custom data", func() {
+			It("should allow tags, tags from pool labels and custom data", func() {
+				workerConfig.Tags = []string{"cost-center=1234", "team-a"}
+				workerConfig.TagsFromPoolLabels = []string{"example.com/cost-center"}
+				workerConfig.CustomData = &runtime.RawExtension{Raw: []byte(`{"foo":{"bar":"baz"}}`)}
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(BeEmpty())
+			})
+
+			It("should forbid empty and duplicate tags", func() {
+				workerConfig.Tags = []string{"team-a", "", "team-a"}
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeRequired),
+						"Field": Equal("providerConfig.tags[1]"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeDuplicate),
+						"Field": Equal("providerConfig.tags[2]"),
+					})),
+				))
+			})
+
+			It("should forbid invalid label keys", func() {
+				workerConfig.TagsFromPoolLabels = []string{"invalid key"}
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("providerConfig.tagsFromPoolLabels[0]"),
+				}))))
+			})
+
+			It("should forbid custom data which is not a JSON object", func() {
+				workerConfig.CustomData = &runtime.RawExtension{Raw: []byte(`["foo"]`)}
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("providerConfig.customData"),
+				}))))
+			})
+		})
+
+		Context("network", func() {
+			It("should allow VLANs for hybrid devices", func() {
+				workerConfig.Network = &api.WorkerNetwork{
+					Type:  ptr.To("hybrid"),
+					VLANs: []api.VLAN{{Name: "managed"}, {Name: "existing", ID: ptr.To[int32](1000)}},
+				}
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(BeEmpty())
+			})
+
+			It("should forbid an unsupported network type", func() {
+				workerConfig.Network = &api.WorkerNetwork{Type: ptr.To("layer4")}
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeNotSupported),
+					"Field": Equal("providerConfig.network.type"),
+				}))))
+			})
+
+			It("should forbid VLANs for layer3 devices", func() {
+				workerConfig.Network = &api.WorkerNetwork{VLANs: []api.VLAN{{Name: "managed"}}}
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeForbidden),
+					"Field": Equal("providerConfig.network.vlans"),
+				}))))
+			})
+
+			It("should forbid invalid VLANs", func() {
+				workerConfig.Network = &api.WorkerNetwork{
+					Type:  ptr.To("layer2-bonded"),
+					VLANs: []api.VLAN{{Name: "foo"}, {Name: "foo"}, {ID: ptr.To[int32](4000)}},
+				}
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeDuplicate),
+						"Field": Equal("providerConfig.network.vlans[1].name"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeRequired),
+						"Field": Equal("providerConfig.network.vlans[2].name"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeInvalid),
+						"Field": Equal("providerConfig.network.vlans[2].id"),
+					})),
+				))
+			})
+
+			It("should forbid the reserved custom data key", func() {
+				workerConfig.CustomData = &runtime.RawExtension{Raw: []byte(`{"gardener":{}}`)}
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeForbidden),
+					"Field": Equal("providerConfig.customData"),
+				}))))
+			})
+		})
+
+		Context("storage", func() {
+			newStorage := func() *api.Storage {
+				return &api.Storage{
+					Disks: []api.StorageDisk{
+						{
+							Device:    "/dev/sda",
+							WipeTable: ptr.To(true),
+							Partitions: []api.StoragePartition{
+								{Label: "BIOS", Number: 1, Size: "4096"},
+								{Label: "ROOT", Number: 2, Size: "0"},
+							},
+						},
+						{
+							Device:     "/dev/nvme0n1",
+							Partitions: []api.StoragePartition{{Label: "ROOT", Number: 1, Size: "0"}},
+						},
+					},
+					RAID: []api.StorageRAID{{Name: "/dev/md/ROOT", Level: "raid1", Devices: []string{"/dev/sda2", "/dev/nvme0n1p1"}}},
+					Filesystems: []api.StorageFilesystem{
+						{Device: "/dev/md/ROOT", Format: "ext4", Point: ptr.To("/"), Options: []string{"-L", "ROOT"}},
+						{Device: "/dev/sda1", Format: "swap"},
+					},
+				}
+			}
+
+			It("should allow a valid storage layout", func() {
+				workerConfig.Storage = newStorage()
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(BeEmpty())
+			})
+
+			It("should forbid invalid disks and partitions", func() {
+				workerConfig.Storage = newStorage()
+				workerConfig.Storage.Disks[1].Device = "/dev/sda"
+				workerConfig.Storage.Disks[0].Partitions[1] = api.StoragePartition{Number: 1, Size: "1 GB"}
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeRequired),
+						"Field": Equal("providerConfig.storage.disks[0].partitions[1].label"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeDuplicate),
+						"Field": Equal("providerConfig.storage.disks[0].partitions[1].number"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeInvalid),
+						"Field": Equal("providerConfig.storage.disks[0].partitions[1].size"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeDuplicate),
+						"Field": Equal("providerConfig.storage.disks[1].device"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeInvalid),
+						"Field": Equal("providerConfig.storage.raid[0].devices[0]"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeInvalid),
+						"Field": Equal("providerConfig.storage.raid[0].devices[1]"),
+					})),
+				))
+			})
+
+			It("should forbid invalid RAID arrays", func() {
+				workerConfig.Storage = newStorage()
+				workerConfig.Storage.RAID[0].Level = "raid2"
+				workerConfig.Storage.RAID[0].Devices = []string{"/dev/sda2"}
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeNotSupported),
+						"Field": Equal("providerConfig.storage.raid[0].level"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeInvalid),
+						"Field": Equal("providerConfig.storage.raid[0].devices"),
+					})),
+				))
+			})
+
+			It("should forbid invalid filesystems", func() {
+				workerConfig.Storage = newStorage()
+				workerConfig.Storage.Filesystems = []api.StorageFilesystem{
+					{Device: "/dev/md/ROOT", Format: "btrfs", Point: ptr.To("/var/")},
+					{Device: "/dev/sdb1", Format: "ext4"},
+					{Device: "/dev/sda1", Format: "swap", Point: ptr.To("/swap")},
+				}
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeNotSupported),
+						"Field": Equal("providerConfig.storage.filesystems[0].format"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeInvalid),
+						"Field": Equal("providerConfig.storage.filesystems[0].point"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeInvalid),
+						"Field": Equal("providerConfig.storage.filesystems[1].device"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeRequired),
+						"Field": Equal("providerConfig.storage.filesystems[1].point"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeForbidden),
+						"Field": Equal("providerConfig.storage.filesystems[2].point"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeRequired),
+						"Field": Equal("providerConfig.storage.filesystems"),
+					})),
+				))
+			})
+		})
+
+		Context("volume layout", func() {
+			It("should allow a valid volume layout", func() {
+				workerConfig.VolumeLayout = &api.VolumeLayout{
+					Disks: &api.DiskSelector{
+						Types:   []string{"nvme", "ssd"},
+						MinSize: ptr.To(resource.MustParse("500Gi")),
+						MaxSize: ptr.To(resource.MustParse("4Ti")),
+					},
+					RAIDLevel:  ptr.To("raid1"),
+					Filesystem: ptr.To("xfs"),
+					Volumes: []api.LogicalVolume{
+						{Name: "log", Size: ptr.To(resource.MustParse("20Gi"))},
+						{Name: "containerd"},
+						{Name: "kubelet", Size: ptr.To(resource.MustParse("100Gi"))},
+					},
+				}
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(BeEmpty())
+			})
+
+			It("should forbid an invalid volume layout", func() {
+				workerConfig.VolumeLayout = &api.VolumeLayout{
+					Disks: &api.DiskSelector{
+						Types:   []string{"nvme", "tape", "nvme"},
+						MinSize: ptr.To(resource.MustParse("1Ti")),
+						MaxSize: ptr.To(resource.MustParse("500Gi")),
+					},
+					RAIDLevel:  ptr.To("raid5"),
+					Filesystem: ptr.To("btrfs"),
+					Volumes: []api.LogicalVolume{
+						{Name: "containerd"},
+						{Name: "containerd", Size: ptr.To(resource.MustParse("0"))},
+						{Name: "var"},
+					},
+				}
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeNotSupported),
+						"Field": Equal("providerConfig.volumeLayout.disks.types[1]"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeDuplicate),
+						"Field": Equal("providerConfig.volumeLayout.disks.types[2]"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeInvalid),
+						"Field": Equal("providerConfig.volumeLayout.disks.maxSize"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeNotSupported),
+						"Field": Equal("providerConfig.volumeLayout.raidLevel"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeNotSupported),
+						"Field": Equal("providerConfig.volumeLayout.filesystem"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeDuplicate),
+						"Field": Equal("providerConfig.volumeLayout.volumes[1].name"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeInvalid),
+						"Field": Equal("providerConfig.volumeLayout.volumes[1].size"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeNotSupported),
+						"Field": Equal("providerConfig.volumeLayout.volumes[2].name"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeForbidden),
+						"Field": Equal("providerConfig.volumeLayout.volumes[2].size"),
+					})),
+				))
+			})
+		})
+
+		Context("spot instances", func() {
+			It("should allow a maximum spot price for spot instances", func() {
+				workerConfig.SpotInstance = ptr.To(true)
+				workerConfig.SpotPriceMax = ptr.To("0.25")
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(BeEmpty())
+			})
+
+			It("should forbid a maximum spot price for on-demand instances", func() {
+				workerConfig.SpotPriceMax = ptr.To("0.25")
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeForbidden),
+					"Field": Equal("providerConfig.spotPriceMax"),
+				}))))
+			})
+
+			It("should forbid an invalid maximum spot price", func() {
+				workerConfig.SpotInstance = ptr.To(true)
+				workerConfig.SpotPriceMax = ptr.To("-1")
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("providerConfig.spotPriceMax"),
+				}))))
+			})
+		})
+
+		Context("on-demand overflow", func() {
+			It("should allow an on-demand overflow for worker pools with hardware reservations", func() {
+				workerConfig.ReservationIDs = []string{"reservation-1"}
+				workerConfig.OnDemandOverflow = ptr.To(true)
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(BeEmpty())
+			})
+
+			It("should forbid an on-demand overflow for worker pools without hardware reservations", func() {
+				workerConfig.OnDemandOverflow = ptr.To(true)
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeForbidden),
+					"Field": Equal("providerConfig.onDemandOverflow"),
+				}))))
+			})
+
+			It("should forbid an on-demand overflow for worker pools only using reserved devices", func() {
+				workerConfig.ReservationIDs = []string{"reservation-1"}
+				workerConfig.ReservedDevicesOnly = ptr.To(true)
+				workerConfig.OnDemandOverflow = ptr.To(true)
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":   Equal(field.ErrorTypeForbidden),
+					"Field":  Equal("providerConfig.onDemandOverflow"),
+					"Detail": ContainSubstring("reservedDevicesOnly"),
+				}))))
+			})
+		})
+
+		Context("fallback machine types", func() {
+			It("should allow fallback machine types", func() {
+				workerConfig.FallbackMachineTypes = []string{"m3.large.x86", "n3.xlarge.x86"}
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(BeEmpty())
+			})
+
+			It("should forbid empty and duplicate fallback machine types", func() {
+				workerConfig.FallbackMachineTypes = []string{"m3.large.x86", "", "m3.large.x86"}
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeRequired),
+					"Field": Equal("providerConfig.fallbackMachineTypes[1]"),
+				})), PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeDuplicate),
+					"Field": Equal("providerConfig.fallbackMachineTypes[2]"),
+				}))))
+			})
+
+			It("should forbid fallback machine types for worker pools with hardware reservations", func() {
+				workerConfig.FallbackMachineTypes = []string{"m3.large.x86"}
+				workerConfig.ReservationSelector = &api.ReservationSelector{Plans: []string{"c3.small.x86"}}
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeForbidden),
+					"Field": Equal("providerConfig.fallbackMachineTypes"),
+				}))))
+			})
+		})
+
+		Context("hibernation mode", func() {
+			It("should allow a supported hibernation mode", func() {
+				workerConfig.HibernationMode = ptr.To(api.HibernationModePowerOff)
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(BeEmpty())
+			})
+
+			It("should forbid an unsupported hibernation mode", func() {
+				workerConfig.HibernationMode = ptr.To("Suspend")
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeNotSupported),
+					"Field": Equal("providerConfig.hibernationMode"),
+				}))))
+			})
+		})
+
+		Context("device adoption", func() {
+			It("should allow to adopt devices by ID and tags", func() {
+				workerConfig.AdoptDevices = &api.DeviceAdoption{
+					DeviceIDs: []string{"device-1", "device-2"},
+					Tags:      []string{"team=infra"},
+					Bootstrap: ptr.To(api.AdoptionBootstrapUserData),
+				}
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(BeEmpty())
+			})
+
+			It("should require device IDs or tags", func() {
+				workerConfig.AdoptDevices = &api.DeviceAdoption{}
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeRequired),
+					"Field": Equal("providerConfig.adoptDevices"),
+				}))))
+			})
+
+			It("should forbid empty and duplicate device IDs and tags and an unsupported bootstrap", func() {
+				workerConfig.AdoptDevices = &api.DeviceAdoption{
+					DeviceIDs: []string{"device-1", "device-1"},
+					Tags:      []string{""},
+					Bootstrap: ptr.To("Rescue"),
+				}
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(ConsistOf(
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeDuplicate),
+						"Field": Equal("providerConfig.adoptDevices.deviceIDs[1]"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeRequired),
+						"Field": Equal("providerConfig.adoptDevices.tags[0]"),
+					})),
+					PointTo(MatchFields(IgnoreExtras, Fields{
+						"Type":  Equal(field.ErrorTypeNotSupported),
+						"Field": Equal("providerConfig.adoptDevices.bootstrap"),
+					})),
+				))
+			})
+
+			It("should forbid to reinstall adopted devices with an inline iPXE script", func() {
+				workerConfig.IPXE = &api.IPXE{Script: ptr.To("#!ipxe\nboot\n")}
+				workerConfig.AdoptDevices = &api.DeviceAdoption{DeviceIDs: []string{"device-1"}}
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeForbidden),
+					"Field": Equal("providerConfig.adoptDevices.bootstrap"),
+				}))))
+			})
+		})
+
+		Context("iPXE", func() {
+			It("should allow a templated script url", func() {
+				workerConfig.IPXE = &api.IPXE{
+					ScriptURL:  ptr.To("https://example.com/{{ .ShootName }}/{{ .Pool }}.ipxe?metro={{ .Metro }}&args={{ .KernelArgs }}"),
+					KernelArgs: []string{"console=ttyS1,115200n8"},
+					AlwaysPXE:  ptr.To(true),
+				}
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(BeEmpty())
+			})
+
+			It("should allow an inline script", func() {
+				workerConfig.IPXE = &api.IPXE{
+					Script: ptr.To("#!ipxe\nkernel https://example.com/vmlinuz {{ .KernelArgs }}\nboot\n"),
+				}
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(BeEmpty())
+			})
+
+			It("should allow to only set alwaysPXE if the machine image has a script url", func() {
+				machineImage = &api.MachineImageVersion{Version: "1.2.3", IPXEScriptURL: "https://example.com/boot.ipxe"}
+				workerConfig.IPXE = &api.IPXE{AlwaysPXE: ptr.To(true)}
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(BeEmpty())
+			})
+
+			It("should forbid an iPXE configuration for machine images not using custom iPXE", func() {
+				machineImage.ID = "flatcar_stable"
+				workerConfig.IPXE = &api.IPXE{ScriptURL: ptr.To("https://example.com/boot.ipxe")}
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeForbidden),
+					"Field": Equal("providerConfig.ipxe"),
+				}))))
+			})
+
+			It("should require a script (url) if the machine image does not have one", func() {
+				workerConfig.IPXE = &api.IPXE{AlwaysPXE: ptr.To(true)}
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeRequired),
+					"Field": Equal("providerConfig.ipxe"),
+				}))))
+			})
+
+			It("should forbid invalid script (url) configurations", func() {
+				workerConfig.IPXE = &api.IPXE{
+					ScriptURL: ptr.To("ftp://example.com/{{ .Unknown }}"),
+					Script:    ptr.To("kernel vmlinuz"),
+				}
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeForbidden),
+					"Field": Equal("providerConfig.ipxe.script"),
+				})), PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("providerConfig.ipxe.scriptUrl"),
+				})), PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeInvalid),
+					"Field": Equal("providerConfig.ipxe.script"),
+				}))))
+			})
+		})
+
+		Context("SSH", func() {
+			It("should allow additional SSH keys", func() {
+				workerConfig.SSH = &api.WorkerSSH{
+					ProjectKeyIDs:  []string{"project-key-1", "project-key-2"},
+					UserKeyIDs:     []string{"project-key-1"},
+					AllProjectKeys: ptr.To(true),
+				}
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(BeEmpty())
+			})
+
+			It("should forbid empty and duplicate key IDs", func() {
+				workerConfig.SSH = &api.WorkerSSH{
+					ProjectKeyIDs: []string{"project-key", "project-key"},
+					UserKeyIDs:    []string{""},
+				}
+
+				Expect(ValidateWorkerConfig(workerConfig, machineImage, fldPath)).To(ConsistOf(PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeDuplicate),
+					"Field": Equal("providerConfig.ssh.projectKeyIDs[1]"),
+				})), PointTo(MatchFields(IgnoreExtras, Fields{
+					"Type":  Equal(field.ErrorTypeRequired),
+					"Field": Equal("providerConfig.ssh.userKeyIDs[0]"),
+				}))))
+			})
+		})
+	})
+})
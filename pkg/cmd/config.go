@@ -12,6 +12,7 @@ import (
 
 	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/config"
 	configloader "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/config/loader"
+	configvalidation "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/config/validation"
 )
 
 // ConfigOptions are command line options that can be set for config.ControllerConfiguration.
@@ -42,6 +43,10 @@ func (c *ConfigOptions) Complete() error {
 		return err
 	}
 
+	if errs := configvalidation.ValidateControllerConfiguration(config); len(errs) > 0 {
+		return fmt.Errorf("invalid controller configuration: %w", errs.ToAggregate())
+	}
+
 	c.config = &Config{config}
 	return nil
 }
@@ -73,6 +78,13 @@ func (c *Config) Options() config.ControllerConfiguration {
 	return cfg
 }
 
+// ApplyCapacityCheck sets the given capacity check configuration to that of this Config.
+func (c *Config) ApplyCapacityCheck(capacityCheck *config.CapacityCheck) {
+	if c.Config.CapacityCheck != nil {
+		*capacityCheck = *c.Config.CapacityCheck
+	}
+}
+
 // ApplyHealthCheckConfig applies the HealthCheckConfig to the config
 func (c *Config) ApplyHealthCheckConfig(config *healthcheckconfig.HealthCheckConfig) {
 	if c.Config.HealthCheckConfig != nil {
@@ -6,37 +6,52 @@ package worker
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/equinix/equinix-sdk-go/services/metalv1"
+	extensionsconfig "github.com/gardener/gardener/extensions/pkg/apis/config/v1alpha1"
 	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
 	"github.com/gardener/gardener/extensions/pkg/controller/worker"
 	"github.com/gardener/gardener/extensions/pkg/controller/worker/genericactuator"
+	"github.com/gardener/gardener/extensions/pkg/util"
 	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
 	gardener "github.com/gardener/gardener/pkg/client/kubernetes"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/cluster"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/config"
 	api "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal"
 	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal/helper"
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal/validation"
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal"
+	eqxmclient "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal/client"
 )
 
 type delegateFactory struct {
-	client     client.Client
-	restConfig *rest.Config
-	scheme     *runtime.Scheme
+	client        client.Client
+	restConfig    *rest.Config
+	scheme        *runtime.Scheme
+	recorder      record.EventRecorder
+	capacityCheck config.CapacityCheck
 }
 
 // NewActuator creates a new Actuator that updates the status of the handled WorkerPoolConfigs.
-func NewActuator(mgr manager.Manager, gardenCluster cluster.Cluster) worker.Actuator {
+func NewActuator(mgr manager.Manager, gardenCluster cluster.Cluster, capacityCheck config.CapacityCheck) worker.Actuator {
 	var (
 		workerDelegate = &delegateFactory{
-			client:     mgr.GetClient(),
-			restConfig: mgr.GetConfig(),
-			scheme:     mgr.GetScheme(),
+			client:        mgr.GetClient(),
+			restConfig:    mgr.GetConfig(),
+			scheme:        mgr.GetScheme(),
+			recorder:      mgr.GetEventRecorderFor(equinixmetal.Name),
+			capacityCheck: capacityCheck,
 		}
 	)
 
@@ -67,57 +82,105 @@ func (d *delegateFactory) WorkerDelegate(ctx context.Context, worker *extensions
 	return NewWorkerDelegate(
 		d.client,
 		d.scheme,
+		eqxmclient.NewClient,
+		d.recorder,
 
 		seedChartApplier,
 		serverVersion.GitVersion,
 
 		worker,
 		cluster,
+		&d.capacityCheck,
 	)
 }
 
 type workerDelegate struct {
-	client  client.Client
-	decoder runtime.Decoder
-	scheme  *runtime.Scheme
+	client    client.Client
+	decoder   runtime.Decoder
+	scheme    *runtime.Scheme
+	newClient eqxmclient.NewClientFunc
+	recorder  record.EventRecorder
+
+	newShootClient func(ctx context.Context, namespace string) (client.Client, error)
 
 	seedChartApplier gardener.ChartApplier
 	serverVersion    string
 
 	cloudProfileConfig *api.CloudProfileConfig
+	controlPlaneConfig *api.ControlPlaneConfig
 	cluster            *extensionscontroller.Cluster
 	worker             *extensionsv1alpha1.Worker
-
-	machineClasses     []map[string]interface{}
-	machineDeployments worker.MachineDeployments
-	machineImages      []api.MachineImage
+	capacityCheck      *config.CapacityCheck
+
+	machineClasses       []map[string]interface{}
+	machineDeployments   worker.MachineDeployments
+	machineImages        []api.MachineImage
+	workerPools          []api.WorkerPoolStatus
+	poolReservations     map[string]poolReservations
+	poolNetworks         map[string]poolNetwork
+	poolAlwaysPXE        map[string]bool
+	deploymentCapacities []deploymentCapacity
+	capacityEvents       sets.Set[string]
+	adoptionTargets      []adoptionTarget
+
+	hardwareReservations []metalv1.HardwareReservation
+	vlans                []metalv1.VirtualNetwork
+	plans                []metalv1.Plan
+	projectSSHKeys       []metalv1.SSHKey
+	metroCapacity        map[string]metalv1.CapacityLevelPerBaremetal
+	facilityCapacity     map[string]map[string]metalv1.CapacityLevelPerBaremetal
+	devices              map[string]*metalv1.Device
 }
 
 // NewWorkerDelegate creates a new context for a worker reconciliation.
 func NewWorkerDelegate(
 	client client.Client,
 	scheme *runtime.Scheme,
+	newClient eqxmclient.NewClientFunc,
+	recorder record.EventRecorder,
 
 	seedChartApplier gardener.ChartApplier,
 	serverVersion string,
 
 	worker *extensionsv1alpha1.Worker,
 	cluster *extensionscontroller.Cluster,
+	capacityCheck *config.CapacityCheck,
 ) (genericactuator.WorkerDelegate, error) {
-	config, err := helper.CloudProfileConfigFromCluster(cluster)
+	cloudProfileConfig, err := helper.CloudProfileConfigFromCluster(cluster)
+	if err != nil {
+		return nil, err
+	}
+	controlPlaneConfig, err := helper.ControlPlaneConfigFromCluster(cluster)
 	if err != nil {
 		return nil, err
 	}
+	if errs := validation.ValidateControlPlaneConfig(controlPlaneConfig, field.NewPath("spec", "provider", "controlPlaneConfig")); len(errs) > 0 {
+		return nil, fmt.Errorf("invalid control plane config: %w", errs.ToAggregate())
+	}
 	return &workerDelegate{
-		client:  client,
-		decoder: serializer.NewCodecFactory(scheme, serializer.EnableStrict).UniversalDecoder(),
-		scheme:  scheme,
+		client:    client,
+		decoder:   serializer.NewCodecFactory(scheme, serializer.EnableStrict).UniversalDecoder(),
+		scheme:    scheme,
+		newClient: newClient,
+		recorder:  recorder,
+
+		newShootClient: shootClientFunc(client),
 
 		seedChartApplier: seedChartApplier,
 		serverVersion:    serverVersion,
 
-		cloudProfileConfig: config,
+		cloudProfileConfig: cloudProfileConfig,
+		controlPlaneConfig: controlPlaneConfig,
 		cluster:            cluster,
 		worker:             worker,
+		capacityCheck:      capacityCheck,
 	}, nil
 }
+
+// shootClientFunc returns a function which creates clients for the shoots in the given namespaces of the seed.
+func shootClientFunc(seedClient client.Client) func(ctx context.Context, namespace string) (client.Client, error) {
+	return func(ctx context.Context, namespace string) (client.Client, error) {
+		_, shootClient, err := util.NewClientForShoot(ctx, seedClient, namespace, client.Options{}, extensionsconfig.RESTOptions{})
+		return shootClient, err
+	}
+}
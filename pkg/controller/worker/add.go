@@ -15,6 +15,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/config"
 	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal"
 )
 
@@ -33,6 +34,8 @@ type AddOptions struct {
 	GardenCluster cluster.Cluster
 	// ExtensionClass defines the extension class this extension is responsible for.
 	ExtensionClass extensionsv1alpha1.ExtensionClass
+	// CapacityCheck is the configuration of the capacity checks of the plans of the worker pools.
+	CapacityCheck config.CapacityCheck
 }
 
 // AddToManagerWithOptions adds a controller with the given Options to the given manager.
@@ -49,13 +52,19 @@ func AddToManagerWithOptions(ctx context.Context, mgr manager.Manager, opts AddO
 		return err
 	}
 
-	return worker.Add(ctx, mgr, worker.AddArgs{
-		Actuator:          NewActuator(mgr, opts.GardenCluster),
+	if err := worker.Add(ctx, mgr, worker.AddArgs{
+		Actuator:          NewActuator(mgr, opts.GardenCluster, opts.CapacityCheck),
 		ControllerOptions: opts.Controller,
 		Predicates:        worker.DefaultPredicates(ctx, mgr, opts.IgnoreOperationAnnotation),
 		Type:              equinixmetal.Type,
 		ExtensionClass:    opts.ExtensionClass,
-	})
+	}); err != nil {
+		return err
+	}
+
+	// The devices are reinstalled by a separate controller as the worker controller waits for the machine deployments
+	// to be rolled out, which for in-place updates requires the update results of the nodes.
+	return addReinstallControllerToManager(mgr, opts)
 }
 
 // AddToManager adds a controller with the default Options.
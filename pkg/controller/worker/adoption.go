@@ -0,0 +1,430 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/equinix/equinix-sdk-go/services/metalv1"
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	"github.com/gardener/gardener/pkg/utils"
+	machinev1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal"
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal/helper"
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal"
+	eqxcmclient "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal/client"
+)
+
+// adoptionTarget describes a machine deployment of a worker pool which adopts existing devices.
+type adoptionTarget struct {
+	pool                string
+	name                string
+	className           string
+	classSpec           map[string]interface{}
+	userData            string
+	machineTypes        []string
+	facilities          []string
+	maximum             int32
+	reservedDevicesOnly bool
+	adoption            *api.DeviceAdoption
+}
+
+// adoptionProviderSpec contains the fields of the provider spec of a machine class which are used to bootstrap adopted
+// devices.
+type adoptionProviderSpec struct {
+	reinstallProviderSpec
+	CustomData string   `json:"customData,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+}
+
+// deviceAdoption is a device which is adopted by a machine deployment. If a previous adoption of the device failed
+// after its machine was created, the machine is set and the adoption is only completed.
+type deviceAdoption struct {
+	device            *metalv1.Device
+	target            *adoptionTarget
+	machineDeployment *machinev1alpha1.MachineDeployment
+	machineSet        *machinev1alpha1.MachineSet
+	machine           *machinev1alpha1.Machine
+}
+
+// adoptDevices adopts the existing devices selected by the worker pools. A device is adopted by a machine of the
+// current machine set of a machine deployment of its worker pool which matches the facility and hardware reservation
+// of the device and whose replicas are below its maximum. The device is bootstrapped before its machine is created,
+// i.e., it is either reinstalled with the machine image or rebooted with the user data of the worker pool, and its
+// hardware reservation is kept. Only once the machine exists the device gets the tags of the worker pool, as
+// machine-controller-manager would otherwise delete it as an orphan.
+//
+// Adopted devices are recorded in the worker provider status and never adopted again. While the machines are created
+// and the machine deployments are scaled up, the machine sets of the machine deployments are labeled with
+// node.machine.sapcloud.io/scale-up-disabled, so that machine-controller-manager does not create new devices for the
+// additional replicas. As every device is recorded and the replicas of its machine deployment are raised before the
+// next device is adopted, the label is removed again even if the adoption of a device fails.
+func (w *workerDelegate) adoptDevices(ctx context.Context) error {
+	if w.worker.DeletionTimestamp != nil || extensionscontroller.IsHibernationEnabled(w.cluster) {
+		return nil
+	}
+
+	// the machine config is only generated if devices are adopted, as it is not generated before the pre-reconcile hook
+	adopting := false
+	for _, pool := range w.worker.Spec.Pools {
+		workerConfig, err := helper.WorkerConfigFromRawExtension(pool.ProviderConfig)
+		if err != nil {
+			return err
+		}
+		adopting = adopting || workerConfig.AdoptDevices != nil
+	}
+	if !adopting {
+		return nil
+	}
+
+	if w.machineClasses == nil {
+		if err := w.generateMachineConfig(ctx); err != nil {
+			return err
+		}
+	}
+	if len(w.adoptionTargets) == 0 {
+		return nil
+	}
+
+	workerStatus, err := w.decodeWorkerProviderStatus()
+	if err != nil {
+		return err
+	}
+
+	credentials, err := equinixmetal.GetCredentialsFromSecretRef(ctx, w.client, w.worker.Spec.SecretRef)
+	if err != nil {
+		return fmt.Errorf("could not get credentials from secret: %w", err)
+	}
+
+	equinixClient, err := w.newClient(string(credentials.APIToken))
+	if err != nil {
+		return err
+	}
+
+	adoptions, err := w.planDeviceAdoptions(ctx, equinixClient, string(credentials.ProjectID), workerStatus)
+	if err != nil || len(adoptions) == 0 {
+		return err
+	}
+
+	// the machine sets must not create new devices for the raised replicas
+	machineDeployments := sets.New[string]()
+	for _, adoption := range adoptions {
+		if adoption.machineDeployment != nil {
+			machineDeployments.Insert(adoption.machineDeployment.Name)
+		}
+	}
+	if err := w.disableMachineSetScaleUp(ctx, sets.List(machineDeployments)...); err != nil {
+		return err
+	}
+
+	var adoptErr error
+	for _, adoption := range adoptions {
+		if err := w.adoptDevice(ctx, equinixClient, workerStatus, adoption); err != nil {
+			adoptErr = fmt.Errorf("could not adopt device %s: %w", adoption.device.GetId(), err)
+			break
+		}
+	}
+
+	if err := w.enableMachineSetScaleUp(ctx); err != nil {
+		return errors.Join(adoptErr, err)
+	}
+	return adoptErr
+}
+
+// planDeviceAdoptions returns the devices which are adopted by this reconciliation together with their machine
+// deployments. A warning event is emitted for devices which cannot be adopted.
+func (w *workerDelegate) planDeviceAdoptions(ctx context.Context, equinixClient eqxcmclient.ClientInterface, projectID string, workerStatus *api.WorkerStatus) ([]deviceAdoption, error) {
+	var (
+		adoptedDevices     = sets.New[string]()
+		machineDeployments = map[string]*machinev1alpha1.MachineDeployment{}
+		machineSets        = map[string]*machinev1alpha1.MachineSet{}
+		adoptions          []deviceAdoption
+	)
+	for _, adoptedDevice := range workerStatus.AdoptedDevices {
+		adoptedDevices.Insert(adoptedDevice.DeviceID)
+	}
+
+	machineList := &machinev1alpha1.MachineList{}
+	if err := w.client.List(ctx, machineList, client.InNamespace(w.worker.Namespace)); err != nil {
+		return nil, fmt.Errorf("could not list machines: %w", err)
+	}
+	deviceMachines := map[string]*machinev1alpha1.Machine{}
+	for i := range machineList.Items {
+		if deviceID, err := deviceIDFromProviderID(machineList.Items[i].Spec.ProviderID); deviceID != "" && err == nil {
+			deviceMachines[deviceID] = &machineList.Items[i]
+		}
+	}
+
+	for i := range w.adoptionTargets {
+		target := &w.adoptionTargets[i]
+		machineDeployment := &machinev1alpha1.MachineDeployment{}
+		if err := w.client.Get(ctx, client.ObjectKey{Namespace: w.worker.Namespace, Name: target.name}, machineDeployment); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("could not get machine deployment %s: %w", target.name, err)
+		}
+		// devices are only adopted by machine deployments which already use the current machine class
+		if machineDeployment.Spec.Template.Spec.Class.Name != target.className {
+			continue
+		}
+
+		machineSet, err := w.currentMachineSet(ctx, target.name)
+		if err != nil {
+			return nil, err
+		}
+		if machineSet == nil {
+			continue
+		}
+		machineDeployments[target.name], machineSets[target.name] = machineDeployment, machineSet
+	}
+
+	// the targets of a worker pool are consecutive and share the same device adoption configuration
+	for i := 0; i < len(w.adoptionTargets); {
+		var (
+			pool    = w.adoptionTargets[i].pool
+			targets []*adoptionTarget
+		)
+		for ; i < len(w.adoptionTargets) && w.adoptionTargets[i].pool == pool; i++ {
+			targets = append(targets, &w.adoptionTargets[i])
+		}
+
+		devices, err := w.listDevicesToAdopt(ctx, equinixClient, projectID, targets[0].adoption)
+		if err != nil {
+			return nil, fmt.Errorf("could not list devices to adopt for worker pool %q: %w", pool, err)
+		}
+
+		for _, device := range devices {
+			if adoptedDevices.Has(device.GetId()) {
+				continue
+			}
+			// a previous adoption of the device failed after its machine was created, it is completed without
+			// bootstrapping the device again
+			if machine := deviceMachines[device.GetId()]; machine != nil && strings.HasSuffix(machine.Name, "-"+adoptionMachineSuffix(device.GetId())) {
+				adoptedDevices.Insert(device.GetId())
+				adoptions = append(adoptions, deviceAdoption{
+					device:  device,
+					target:  adoptionTargetOfMachine(targets, machine),
+					machine: machine,
+				})
+				continue
+			}
+			if slices.Contains(device.GetTags(), w.clusterTag()) || slices.Contains(device.GetTags(), w.hibernationTag()) {
+				continue
+			}
+			if reason := adoptionMismatch(device, targets[0], w.worker.Spec.Region); reason != "" {
+				w.recorder.Eventf(w.worker, corev1.EventTypeWarning, "DeviceAdoptionSkipped",
+					"Device %s cannot be adopted by worker pool %q: %s", device.GetId(), pool, reason)
+				continue
+			}
+
+			target := selectAdoptionTarget(device, targets, machineDeployments, adoptions)
+			if target == nil {
+				w.recorder.Eventf(w.worker, corev1.EventTypeWarning, "DeviceAdoptionSkipped",
+					"Device %s cannot be adopted by worker pool %q: no up-to-date machine deployment of its facility is below its maximum", device.GetId(), pool)
+				continue
+			}
+
+			adoptedDevices.Insert(device.GetId())
+			adoptions = append(adoptions, deviceAdoption{
+				device:            device,
+				target:            target,
+				machineDeployment: machineDeployments[target.name],
+				machineSet:        machineSets[target.name],
+			})
+		}
+	}
+
+	return adoptions, nil
+}
+
+// listDevicesToAdopt returns the devices with the IDs of the given device adoption and the devices of the project which
+// have all of its tags.
+func (w *workerDelegate) listDevicesToAdopt(ctx context.Context, equinixClient eqxcmclient.ClientInterface, projectID string, adoption *api.DeviceAdoption) ([]*metalv1.Device, error) {
+	var devices []*metalv1.Device
+	for _, deviceID := range adoption.DeviceIDs {
+		device, err := equinixClient.GetDevice(ctx, deviceID)
+		if err != nil {
+			return nil, fmt.Errorf("could not get device %s: %w", deviceID, err)
+		}
+		devices = append(devices, device)
+	}
+
+	if len(adoption.Tags) == 0 {
+		return devices, nil
+	}
+
+	taggedDevices, err := equinixClient.ListDevices(ctx, projectID, adoption.Tags[0])
+	if err != nil {
+		return nil, err
+	}
+	for i := range taggedDevices {
+		device := &taggedDevices[i]
+		if slices.ContainsFunc(adoption.Tags, func(tag string) bool { return !slices.Contains(device.GetTags(), tag) }) ||
+			slices.ContainsFunc(devices, func(d *metalv1.Device) bool { return d.GetId() == device.GetId() }) {
+			continue
+		}
+		devices = append(devices, device)
+	}
+	return devices, nil
+}
+
+// adoptionMismatch returns the reason why the given device cannot be adopted by the machine deployments of a worker
+// pool, or an empty string if it can be adopted.
+func adoptionMismatch(device *metalv1.Device, target *adoptionTarget, metro string) string {
+	metroCode := device.GetMetro()
+	if metroCode.GetCode() != metro {
+		return fmt.Sprintf("it is located in metro %q instead of %q", metroCode.GetCode(), metro)
+	}
+	plan := device.GetPlan()
+	if !slices.Contains(target.machineTypes, plan.GetSlug()) {
+		return fmt.Sprintf("its plan %q is not a machine type of the worker pool", plan.GetSlug())
+	}
+	if device.GetState() != metalv1.DEVICESTATE_ACTIVE {
+		return fmt.Sprintf("it is %s instead of active", device.GetState())
+	}
+	return ""
+}
+
+// selectAdoptionTarget returns the first of the given machine deployments which can adopt the given device, i.e.,
+// which exists, whose facilities contain the facility of the device, which only uses reserved devices if the device
+// has a hardware reservation, and whose replicas including the devices it adopts are below its maximum.
+func selectAdoptionTarget(device *metalv1.Device, targets []*adoptionTarget, machineDeployments map[string]*machinev1alpha1.MachineDeployment, adoptions []deviceAdoption) *adoptionTarget {
+	facility := device.GetFacility()
+	for _, target := range targets {
+		machineDeployment, ok := machineDeployments[target.name]
+		if !ok {
+			continue
+		}
+		if len(target.facilities) > 0 && !slices.Contains(target.facilities, facility.GetCode()) {
+			continue
+		}
+		if target.reservedDevicesOnly && device.HardwareReservation == nil {
+			continue
+		}
+
+		replicas := machineDeployment.Spec.Replicas
+		for _, adoption := range adoptions {
+			if adoption.machine == nil && adoption.target.name == target.name {
+				replicas++
+			}
+		}
+		if replicas < target.maximum {
+			return target
+		}
+	}
+	return nil
+}
+
+// adoptionTargetOfMachine returns the target of the machine deployment of the given machine, or the first of the
+// given targets of its worker pool if the machine deployment is not a target anymore.
+func adoptionTargetOfMachine(targets []*adoptionTarget, machine *machinev1alpha1.Machine) *adoptionTarget {
+	for _, target := range targets {
+		if target.name == machine.Labels[machineDeploymentLabel] {
+			return target
+		}
+	}
+	return targets[0]
+}
+
+// adoptionMachineSuffix returns the suffix of the name of the machine which adopts the device with the given ID.
+func adoptionMachineSuffix(deviceID string) string {
+	return utils.ComputeSHA256Hex([]byte(deviceID))[:5]
+}
+
+// adoptDevice bootstraps the device of the given adoption, raises the replicas of its machine deployment, creates its
+// machine and records the device in the worker provider status. The replicas are raised before the machine is
+// created, so that machine-controller-manager never deletes the machine of an adopted device as a surplus replica. If
+// the machine already exists, the device is only tagged and recorded.
+func (w *workerDelegate) adoptDevice(ctx context.Context, equinixClient eqxcmclient.ClientInterface, workerStatus *api.WorkerStatus, adoption deviceAdoption) error {
+	device := adoption.device
+
+	data, err := json.Marshal(adoption.target.classSpec)
+	if err != nil {
+		return err
+	}
+	providerSpec := &adoptionProviderSpec{}
+	if err := json.Unmarshal(data, providerSpec); err != nil {
+		return fmt.Errorf("could not decode provider spec of machine class %s: %w", adoption.target.className, err)
+	}
+
+	var machineName, machineDeploymentName string
+	if adoption.machine != nil {
+		machineName, machineDeploymentName = adoption.machine.Name, adoption.machine.Labels[machineDeploymentLabel]
+	} else {
+		machineName, machineDeploymentName = fmt.Sprintf("%s-%s", adoption.machineSet.Name, adoptionMachineSuffix(device.GetId())), adoption.machineDeployment.Name
+
+		if err := bootstrapDevice(ctx, equinixClient, device.GetId(), machineName, ptr.Deref(adoption.target.adoption.Bootstrap, api.AdoptionBootstrapReinstall), providerSpec, adoption.target.userData); err != nil {
+			return fmt.Errorf("could not bootstrap device: %w", err)
+		}
+
+		// the machine deployment is shared by the adoptions of its devices, hence, its replicas accumulate
+		adoption.machineDeployment.Spec.Replicas++
+		if err := w.scaleMachineDeployment(ctx, machineDeploymentName, adoption.machineDeployment.Spec.Replicas); err != nil {
+			return err
+		}
+		if err := w.createMachineForDevice(ctx, adoption.machineSet, machineName, device.GetId()); err != nil {
+			return err
+		}
+	}
+
+	tags := slices.Clone(device.GetTags())
+	for _, tag := range providerSpec.Tags {
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	if !slices.Equal(tags, device.GetTags()) {
+		if _, err := equinixClient.UpdateDevice(ctx, device.GetId(), metalv1.DeviceUpdateInput{Tags: tags}); err != nil {
+			return fmt.Errorf("could not update tags of device: %w", err)
+		}
+	}
+
+	workerStatus.AdoptedDevices = append(workerStatus.AdoptedDevices, api.AdoptedDevice{
+		Name:     machineName,
+		Pool:     adoption.target.pool,
+		DeviceID: device.GetId(),
+	})
+	if err := w.updateWorkerProviderStatus(ctx, workerStatus); err != nil {
+		return fmt.Errorf("could not record adopted device: %w", err)
+	}
+
+	w.recorder.Eventf(w.worker, corev1.EventTypeNormal, "DeviceAdopted",
+		"Device %s adopted by machine deployment %q", device.GetId(), machineDeploymentName)
+	return nil
+}
+
+// bootstrapDevice sets the hostname and the custom data of the given device and lets it join the cluster, either by
+// reinstalling it with the operating system of the given provider spec, or by rebooting it with the given user data.
+func bootstrapDevice(ctx context.Context, equinixClient eqxcmclient.ClientInterface, deviceID, hostname, bootstrap string, providerSpec *adoptionProviderSpec, userData string) error {
+	input := metalv1.DeviceUpdateInput{Hostname: &hostname}
+	if providerSpec.CustomData != "" {
+		if err := json.Unmarshal([]byte(providerSpec.CustomData), &input.Customdata); err != nil {
+			return fmt.Errorf("could not decode custom data: %w", err)
+		}
+	}
+	if bootstrap == api.AdoptionBootstrapUserData {
+		input.Userdata = &userData
+	}
+	if _, err := equinixClient.UpdateDevice(ctx, deviceID, input); err != nil {
+		return fmt.Errorf("could not update device: %w", err)
+	}
+
+	if bootstrap == api.AdoptionBootstrapUserData {
+		return equinixClient.PerformDeviceAction(ctx, deviceID, metalv1.DeviceActionInput{Type: metalv1.DEVICEACTIONINPUTTYPE_REBOOT})
+	}
+	return reinstallDevice(ctx, equinixClient, deviceID, &providerSpec.reinstallProviderSpec, userData)
+}
@@ -0,0 +1,314 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	"context"
+	"errors"
+
+	"github.com/equinix/equinix-sdk-go/services/metalv1"
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"github.com/gardener/gardener/pkg/utils"
+	machinev1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	api "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal"
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal/install"
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal"
+	eqxcmclient "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal/client"
+	mockeqxcmclient "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal/client/mock"
+)
+
+var _ = Describe("Adoption", func() {
+	const (
+		namespace         = "shoot--foo--bar"
+		region            = "ny"
+		machineDeployment = namespace + "-pool-1-z1"
+		machineClass      = machineDeployment + "-abcde"
+		machineSetName    = machineDeployment + "-12345"
+	)
+
+	var (
+		ctx  = context.TODO()
+		ctrl *gomock.Controller
+
+		seedClient    client.Client
+		equinixClient *mockeqxcmclient.MockClientInterface
+		recorder      *record.FakeRecorder
+		w             *workerDelegate
+
+		clusterTag = "kubernetes.io/cluster/" + namespace
+
+		device = func(id string, tags ...string) *metalv1.Device {
+			return &metalv1.Device{
+				Id:       ptr.To(id),
+				State:    ptr.To(metalv1.DEVICESTATE_ACTIVE),
+				Plan:     &metalv1.Plan{Slug: ptr.To("c3.small.x86")},
+				Metro:    &metalv1.DeviceMetro{Code: ptr.To(region)},
+				Facility: &metalv1.Facility{Code: ptr.To("ny5")},
+				Tags:     tags,
+			}
+		}
+
+		machineName = func(deviceID string) string {
+			return machineSetName + "-" + utils.ComputeSHA256Hex([]byte(deviceID))[:5]
+		}
+
+		getMachineDeploymentReplicas = func() int32 {
+			md := &machinev1alpha1.MachineDeployment{}
+			ExpectWithOffset(1, seedClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: machineDeployment}, md)).To(Succeed())
+			return md.Spec.Replicas
+		}
+
+		getMachineSetLabels = func() map[string]string {
+			ms := &machinev1alpha1.MachineSet{}
+			ExpectWithOffset(1, seedClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: machineSetName}, ms)).To(Succeed())
+			return ms.Labels
+		}
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		equinixClient = mockeqxcmclient.NewMockClientInterface(ctrl)
+		recorder = record.NewFakeRecorder(10)
+
+		seedScheme := runtime.NewScheme()
+		Expect(scheme.AddToScheme(seedScheme)).To(Succeed())
+		Expect(machinev1alpha1.AddToScheme(seedScheme)).To(Succeed())
+		Expect(extensionsv1alpha1.AddToScheme(seedScheme)).To(Succeed())
+		Expect(install.AddToScheme(seedScheme)).To(Succeed())
+
+		worker := &extensionsv1alpha1.Worker{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: namespace},
+			Spec: extensionsv1alpha1.WorkerSpec{
+				Region:    region,
+				SecretRef: corev1.SecretReference{Name: "secret", Namespace: namespace},
+				Pools: []extensionsv1alpha1.WorkerPool{{
+					Name:           "pool-1",
+					ProviderConfig: &runtime.RawExtension{Raw: []byte(`{"apiVersion":"equinixmetal.provider.extensions.gardener.cloud/v1alpha1","kind":"WorkerConfig","adoptDevices":{"deviceIDs":["device-1"]}}`)},
+				}},
+			},
+		}
+
+		seedClient = fakeclient.NewClientBuilder().WithScheme(seedScheme).WithStatusSubresource(&extensionsv1alpha1.Worker{}).WithObjects(
+			worker,
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace},
+				Data:       map[string][]byte{equinixmetal.APIToken: []byte("token"), equinixmetal.ProjectID: []byte("project")},
+			},
+			&machinev1alpha1.MachineDeployment{
+				ObjectMeta: metav1.ObjectMeta{Name: machineDeployment, Namespace: namespace},
+				Spec: machinev1alpha1.MachineDeploymentSpec{
+					Replicas: 1,
+					Template: machinev1alpha1.MachineTemplateSpec{Spec: machinev1alpha1.MachineSpec{Class: machinev1alpha1.ClassSpec{Kind: "MachineClass", Name: machineClass}}},
+				},
+			},
+			&machinev1alpha1.MachineSet{
+				ObjectMeta: metav1.ObjectMeta{Name: machineSetName, Namespace: namespace, Labels: map[string]string{"name": machineDeployment}},
+				Spec: machinev1alpha1.MachineSetSpec{
+					Replicas: 1,
+					Template: machinev1alpha1.MachineTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"name": machineDeployment, "machine-template-hash": "12345"}},
+						Spec:       machinev1alpha1.MachineSpec{Class: machinev1alpha1.ClassSpec{Kind: "MachineClass", Name: machineClass}},
+					},
+				},
+			},
+		).Build()
+
+		w = &workerDelegate{
+			client:  seedClient,
+			decoder: serializer.NewCodecFactory(seedScheme, serializer.EnableStrict).UniversalDecoder(),
+			scheme:  seedScheme,
+			newClient: func(_ string) (eqxcmclient.ClientInterface, error) {
+				return equinixClient, nil
+			},
+			recorder:       recorder,
+			cluster:        &extensionscontroller.Cluster{Shoot: &gardencorev1beta1.Shoot{}},
+			worker:         worker,
+			machineClasses: []map[string]interface{}{},
+			adoptionTargets: []adoptionTarget{{
+				pool:      "pool-1",
+				name:      machineDeployment,
+				className: machineClass,
+				classSpec: map[string]interface{}{
+					"OS":            "flatcar_stable",
+					"ipxeScriptUrl": "",
+					"customData":    `{"foo":"bar"}`,
+					"tags":          []string{clusterTag, "kubernetes.io/role/node"},
+				},
+				userData:     "user-data",
+				machineTypes: []string{"c3.small.x86"},
+				facilities:   []string{"ny5"},
+				maximum:      3,
+				adoption:     &api.DeviceAdoption{DeviceIDs: []string{"device-1"}},
+			}},
+		}
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	It("should reinstall the adopted devices and create their machines", func() {
+		equinixClient.EXPECT().GetDevice(ctx, "device-1").Return(device("device-1", "hand-provisioned"), nil)
+		gomock.InOrder(
+			equinixClient.EXPECT().UpdateDevice(ctx, "device-1", metalv1.DeviceUpdateInput{
+				Hostname:   ptr.To(machineName("device-1")),
+				Customdata: map[string]interface{}{"foo": "bar"},
+			}),
+			equinixClient.EXPECT().UpdateDevice(ctx, "device-1", metalv1.DeviceUpdateInput{Userdata: ptr.To("user-data")}),
+			equinixClient.EXPECT().PerformDeviceAction(ctx, "device-1", metalv1.DeviceActionInput{
+				Type:            metalv1.DEVICEACTIONINPUTTYPE_REINSTALL,
+				OperatingSystem: ptr.To("flatcar_stable"),
+			}),
+			equinixClient.EXPECT().UpdateDevice(ctx, "device-1", metalv1.DeviceUpdateInput{Tags: []string{"hand-provisioned", clusterTag, "kubernetes.io/role/node"}}).DoAndReturn(
+				func(_ context.Context, _ string, _ metalv1.DeviceUpdateInput) (*metalv1.Device, error) {
+					Expect(getMachineSetLabels()).To(HaveKeyWithValue("node.machine.sapcloud.io/scale-up-disabled", "true"))
+					return nil, nil
+				}),
+		)
+
+		Expect(w.adoptDevices(ctx)).To(Succeed())
+		Expect(getMachineSetLabels()).NotTo(HaveKey("node.machine.sapcloud.io/scale-up-disabled"))
+
+		machine := &machinev1alpha1.Machine{}
+		Expect(seedClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: machineName("device-1")}, machine)).To(Succeed())
+		Expect(machine.Spec.ProviderID).To(Equal("equinixmetal://device-1"))
+		Expect(metav1.GetControllerOf(machine).Name).To(Equal(machineSetName))
+		Expect(getMachineDeploymentReplicas()).To(Equal(int32(2)))
+
+		workerStatus, err := w.decodeWorkerProviderStatus()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(workerStatus.AdoptedDevices).To(ConsistOf(api.AdoptedDevice{Name: machineName("device-1"), Pool: "pool-1", DeviceID: "device-1"}))
+		Expect(recorder.Events).To(Receive(ContainSubstring("DeviceAdopted")))
+	})
+
+	It("should reboot the devices with all selected tags with the user data", func() {
+		w.adoptionTargets[0].adoption = &api.DeviceAdoption{Tags: []string{"gardener", "pool-1"}, Bootstrap: ptr.To(api.AdoptionBootstrapUserData)}
+		w.worker.Status.ProviderStatus = &runtime.RawExtension{Raw: []byte(`{"apiVersion":"equinixmetal.provider.extensions.gardener.cloud/v1alpha1","kind":"WorkerStatus","adoptedDevices":[{"name":"machine","pool":"pool-1","deviceID":"device-4"}]}`)}
+		Expect(seedClient.Status().Update(ctx, w.worker)).To(Succeed())
+
+		equinixClient.EXPECT().ListDevices(ctx, "project", "gardener").Return([]metalv1.Device{
+			*device("device-1", "gardener", "pool-1"),
+			*device("device-2", "gardener"),
+			*device("device-3", "gardener", "pool-1", clusterTag),
+			*device("device-4", "gardener", "pool-1"),
+		}, nil)
+		gomock.InOrder(
+			equinixClient.EXPECT().UpdateDevice(ctx, "device-1", metalv1.DeviceUpdateInput{
+				Hostname:   ptr.To(machineName("device-1")),
+				Customdata: map[string]interface{}{"foo": "bar"},
+				Userdata:   ptr.To("user-data"),
+			}),
+			equinixClient.EXPECT().PerformDeviceAction(ctx, "device-1", metalv1.DeviceActionInput{Type: metalv1.DEVICEACTIONINPUTTYPE_REBOOT}),
+			equinixClient.EXPECT().UpdateDevice(ctx, "device-1", metalv1.DeviceUpdateInput{Tags: []string{"gardener", "pool-1", clusterTag, "kubernetes.io/role/node"}}),
+		)
+
+		Expect(w.adoptDevices(ctx)).To(Succeed())
+
+		Expect(seedClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: machineName("device-1")}, &machinev1alpha1.Machine{})).To(Succeed())
+		Expect(getMachineDeploymentReplicas()).To(Equal(int32(2)))
+
+		workerStatus, err := w.decodeWorkerProviderStatus()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(workerStatus.AdoptedDevices).To(ConsistOf(
+			api.AdoptedDevice{Name: "machine", Pool: "pool-1", DeviceID: "device-4"},
+			api.AdoptedDevice{Name: machineName("device-1"), Pool: "pool-1", DeviceID: "device-1"},
+		))
+	})
+
+	It("should record the adopted devices and enable the scale-up of the machine sets if the adoption of a device fails", func() {
+		w.adoptionTargets[0].adoption = &api.DeviceAdoption{DeviceIDs: []string{"device-1", "device-2"}, Bootstrap: ptr.To(api.AdoptionBootstrapUserData)}
+
+		equinixClient.EXPECT().GetDevice(ctx, "device-1").Return(device("device-1"), nil)
+		equinixClient.EXPECT().GetDevice(ctx, "device-2").Return(device("device-2"), nil)
+		gomock.InOrder(
+			equinixClient.EXPECT().UpdateDevice(ctx, "device-1", gomock.Any()),
+			equinixClient.EXPECT().PerformDeviceAction(ctx, "device-1", metalv1.DeviceActionInput{Type: metalv1.DEVICEACTIONINPUTTYPE_REBOOT}),
+			equinixClient.EXPECT().UpdateDevice(ctx, "device-1", metalv1.DeviceUpdateInput{Tags: []string{clusterTag, "kubernetes.io/role/node"}}),
+			equinixClient.EXPECT().UpdateDevice(ctx, "device-2", gomock.Any()).Return(nil, errors.New("fake")),
+		)
+
+		Expect(w.adoptDevices(ctx)).To(MatchError(ContainSubstring("could not adopt device device-2")))
+
+		Expect(seedClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: machineName("device-1")}, &machinev1alpha1.Machine{})).To(Succeed())
+		Expect(seedClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: machineName("device-2")}, &machinev1alpha1.Machine{})).To(WithTransform(apierrors.IsNotFound, BeTrue()))
+		Expect(getMachineDeploymentReplicas()).To(Equal(int32(2)))
+
+		workerStatus, err := w.decodeWorkerProviderStatus()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(workerStatus.AdoptedDevices).To(ConsistOf(api.AdoptedDevice{Name: machineName("device-1"), Pool: "pool-1", DeviceID: "device-1"}))
+		Expect(getMachineSetLabels()).NotTo(HaveKey("node.machine.sapcloud.io/scale-up-disabled"))
+	})
+
+	It("should complete the adoption of devices whose machine already exists", func() {
+		Expect(seedClient.Create(ctx, &machinev1alpha1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: machineName("device-1"), Namespace: namespace, Labels: map[string]string{"name": machineDeployment}},
+			Spec:       machinev1alpha1.MachineSpec{ProviderID: "equinixmetal://device-1"},
+		})).To(Succeed())
+
+		equinixClient.EXPECT().GetDevice(ctx, "device-1").Return(device("device-1", clusterTag), nil)
+		equinixClient.EXPECT().UpdateDevice(ctx, "device-1", metalv1.DeviceUpdateInput{Tags: []string{clusterTag, "kubernetes.io/role/node"}})
+
+		Expect(w.adoptDevices(ctx)).To(Succeed())
+
+		Expect(getMachineDeploymentReplicas()).To(Equal(int32(1)))
+
+		workerStatus, err := w.decodeWorkerProviderStatus()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(workerStatus.AdoptedDevices).To(ConsistOf(api.AdoptedDevice{Name: machineName("device-1"), Pool: "pool-1", DeviceID: "device-1"}))
+	})
+
+	It("should not adopt devices with a different plan", func() {
+		d := device("device-1")
+		d.Plan.Slug = ptr.To("m3.large.x86")
+		equinixClient.EXPECT().GetDevice(ctx, "device-1").Return(d, nil)
+
+		Expect(w.adoptDevices(ctx)).To(Succeed())
+
+		Expect(getMachineDeploymentReplicas()).To(Equal(int32(1)))
+		Expect(recorder.Events).To(Receive(ContainSubstring(`its plan "m3.large.x86" is not a machine type of the worker pool`)))
+	})
+
+	It("should not adopt devices if the machine deployment is at its maximum", func() {
+		w.adoptionTargets[0].maximum = 1
+		equinixClient.EXPECT().GetDevice(ctx, "device-1").Return(device("device-1"), nil)
+
+		Expect(w.adoptDevices(ctx)).To(Succeed())
+
+		Expect(getMachineDeploymentReplicas()).To(Equal(int32(1)))
+		Expect(recorder.Events).To(Receive(ContainSubstring("no up-to-date machine deployment of its facility is below its maximum")))
+	})
+
+	It("should not adopt devices without hardware reservation by machine deployments only using reserved devices", func() {
+		w.adoptionTargets[0].reservedDevicesOnly = true
+		equinixClient.EXPECT().GetDevice(ctx, "device-1").Return(device("device-1"), nil)
+
+		Expect(w.adoptDevices(ctx)).To(Succeed())
+
+		Expect(getMachineDeploymentReplicas()).To(Equal(int32(1)))
+		Expect(recorder.Events).To(Receive(ContainSubstring("DeviceAdoptionSkipped")))
+	})
+
+	It("should not adopt devices while the shoot is hibernated", func() {
+		w.cluster.Shoot.Spec.Hibernation = &gardencorev1beta1.Hibernation{Enabled: ptr.To(true)}
+
+		Expect(w.adoptDevices(ctx)).To(Succeed())
+	})
+})
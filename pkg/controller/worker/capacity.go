@@ -0,0 +1,312 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/equinix/equinix-sdk-go/services/metalv1"
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	"github.com/gardener/gardener/extensions/pkg/controller/worker"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	gardencorev1beta1helper "github.com/gardener/gardener/pkg/apis/core/v1beta1/helper"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	machinev1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal"
+)
+
+const (
+	// ConditionTypeCapacityAvailable is the type of the condition of a Worker which reports whether the plans of its
+	// worker pools have capacity for new machines.
+	ConditionTypeCapacityAvailable gardencorev1beta1.ConditionType = "CapacityAvailable"
+
+	// capacityLevelUnavailable is the capacity level of plans which cannot be provisioned.
+	capacityLevelUnavailable = "unavailable"
+	// capacityLevelLimited is the capacity level of plans of which only few devices can be provisioned.
+	capacityLevelLimited = "limited"
+	// capacityLevelNormal is the capacity level of plans which can be provisioned without restrictions.
+	capacityLevelNormal = "normal"
+)
+
+// capacityLevels are the capacity levels of the Equinix Metal API ordered from the lowest to the highest capacity.
+var capacityLevels = []string{capacityLevelUnavailable, capacityLevelLimited, capacityLevelNormal}
+
+// deploymentCapacity describes the capacity which a machine deployment requires to provision new machines.
+type deploymentCapacity struct {
+	name        string
+	className   string
+	machineType string
+	facilities  []string
+}
+
+// selectMachineType returns the machine type which is used for new machines of a worker pool with the given machine
+// type and fallback machine types. It is the first of them whose capacity is not unavailable in the metro of the
+// shoot. Machine types without a reported capacity level are considered to be available. If none of them is available,
+// the machine type of the worker pool is returned.
+func (w *workerDelegate) selectMachineType(ctx context.Context, credentials *equinixmetal.Credentials, machineType string, fallbackMachineTypes []string) (string, error) {
+	if len(fallbackMachineTypes) == 0 {
+		return machineType, nil
+	}
+
+	capacity, err := w.listMetroCapacity(ctx, credentials)
+	if err != nil {
+		return "", err
+	}
+
+	for _, candidate := range append([]string{machineType}, fallbackMachineTypes...) {
+		level, ok := capacity[candidate]
+		if !ok || level.GetLevel() != capacityLevelUnavailable {
+			return candidate, nil
+		}
+	}
+	return machineType, nil
+}
+
+// requiresOnDemandCapacity checks whether the machine class with the given spec creates on-demand devices. Machine
+// classes which only use hardware reservations or create spot market devices do not depend on the on-demand capacity.
+func requiresOnDemandCapacity(classSpec map[string]interface{}) bool {
+	return classSpec["reservedDevicesOnly"] != true && classSpec["spotInstance"] != true
+}
+
+// checkCapacity checks the capacity of the plans of the machine deployments which provision new on-demand devices, i.e.,
+// which do not exist yet or whose machine class changes. The result is reported by the CapacityAvailable condition of
+// the Worker with the ERR_INFRA_RESOURCES_DEPLETED code if the capacity of a plan is unavailable, and a warning event
+// is emitted for plans whose capacity is at most the configured warning level. If the check is blocking, the machine
+// deployments whose plan is unavailable are blocked, so that the machine-controller-manager does not repeatedly fail
+// to create devices, while the other machine deployments are reconciled as usual.
+func (w *workerDelegate) checkCapacity(ctx context.Context) error {
+	if w.capacityCheck == nil || !ptr.Deref(w.capacityCheck.Enabled, true) ||
+		w.worker.DeletionTimestamp != nil || extensionscontroller.IsHibernationEnabled(w.cluster) {
+		return nil
+	}
+
+	warningLevel := slices.Index(capacityLevels, ptr.Deref(w.capacityCheck.WarningLevel, capacityLevelLimited))
+	if warningLevel < 0 {
+		return fmt.Errorf("unsupported capacity warning level %q", *w.capacityCheck.WarningLevel)
+	}
+
+	var (
+		credentials *equinixmetal.Credentials
+		limited     []string
+		unavailable []string
+		blocked     = map[string]*machinev1alpha1.MachineDeployment{}
+	)
+
+	for _, deployment := range w.deploymentCapacities {
+		existing, err := w.existingMachineDeployment(ctx, deployment.name)
+		if err != nil {
+			return err
+		}
+		if existing != nil && existing.Spec.Template.Spec.Class.Name == deployment.className {
+			continue
+		}
+
+		if credentials == nil {
+			if credentials, err = equinixmetal.GetCredentialsFromSecretRef(ctx, w.client, w.worker.Spec.SecretRef); err != nil {
+				return fmt.Errorf("could not get credentials from secret: %w", err)
+			}
+		}
+
+		level, err := w.capacityLevel(ctx, credentials, deployment.machineType, deployment.facilities)
+		if err != nil {
+			return err
+		}
+
+		message := fmt.Sprintf("capacity of plan %q for machine deployment %q is %s", deployment.machineType, deployment.name, level)
+		switch level {
+		case capacityLevelUnavailable:
+			unavailable = append(unavailable, message)
+			blocked[deployment.name] = existing
+		case capacityLevelLimited:
+			limited = append(limited, message)
+		}
+
+		// The machine configuration is generated more than once per reconciliation, e.g., after devices are adopted, hence
+		// the event for a machine deployment is only emitted once per worker delegate.
+		if rank := slices.Index(capacityLevels, level); rank >= 0 && rank <= warningLevel && !w.capacityEvents.Has(deployment.name) {
+			if w.capacityEvents == nil {
+				w.capacityEvents = sets.New[string]()
+			}
+			w.capacityEvents.Insert(deployment.name)
+			w.recorder.Eventf(w.worker, corev1.EventTypeWarning, "InsufficientCapacity",
+				"Capacity of plan %q for machine deployment %q is %s", deployment.machineType, deployment.name, level)
+		}
+	}
+
+	var (
+		status  = gardencorev1beta1.ConditionTrue
+		reason  = "CapacityAvailable"
+		message = "The plans of all worker pools have capacity for new machines."
+		codes   []gardencorev1beta1.ErrorCode
+	)
+	switch {
+	case len(unavailable) > 0:
+		status, reason, message = gardencorev1beta1.ConditionFalse, "CapacityUnavailable", strings.Join(append(unavailable, limited...), "; ")
+		codes = []gardencorev1beta1.ErrorCode{gardencorev1beta1.ErrorInfraResourcesDepleted}
+	case len(limited) > 0:
+		reason, message = "CapacityLimited", strings.Join(limited, "; ")
+	}
+
+	if err := w.updateCapacityCondition(ctx, status, reason, message, codes...); err != nil {
+		return fmt.Errorf("could not update condition %q: %w", ConditionTypeCapacityAvailable, err)
+	}
+
+	if ptr.Deref(w.capacityCheck.Blocking, true) {
+		for _, name := range sets.List(sets.KeySet(blocked)) {
+			w.blockMachineDeployment(name, blocked[name])
+		}
+	}
+	return nil
+}
+
+// existingMachineDeployment returns the machine deployment with the given name. Only machine deployments recorded in
+// the status of the worker are returned, nil is returned for the other ones.
+func (w *workerDelegate) existingMachineDeployment(ctx context.Context, name string) (*machinev1alpha1.MachineDeployment, error) {
+	if !slices.ContainsFunc(w.worker.Status.MachineDeployments, func(machineDeployment extensionsv1alpha1.MachineDeployment) bool {
+		return machineDeployment.Name == name
+	}) {
+		return nil, nil
+	}
+
+	machineDeployment := &machinev1alpha1.MachineDeployment{}
+	if err := w.client.Get(ctx, client.ObjectKey{Namespace: w.worker.Namespace, Name: name}, machineDeployment); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("could not get machine deployment %s: %w", name, err)
+	}
+	return machineDeployment, nil
+}
+
+// blockMachineDeployment prevents the machine deployment with the given name from provisioning new machines. An
+// existing machine deployment keeps its previous machine class and replicas, so that its machines are neither rolled
+// nor scaled up, and the new machine class is not deployed. A new machine deployment is created without replicas.
+func (w *workerDelegate) blockMachineDeployment(name string, existing *machinev1alpha1.MachineDeployment) {
+	index := slices.IndexFunc(w.machineDeployments, func(deployment worker.MachineDeployment) bool {
+		return deployment.Name == name
+	})
+	if index < 0 {
+		return
+	}
+	deployment := &w.machineDeployments[index]
+
+	var replicas int32
+	if existing != nil {
+		className := deployment.ClassName
+		w.machineClasses = slices.DeleteFunc(w.machineClasses, func(machineClass map[string]interface{}) bool {
+			return machineClass["name"] == className
+		})
+
+		deployment.ClassName = existing.Spec.Template.Spec.Class.Name
+		deployment.SecretName = deployment.ClassName
+		replicas = existing.Spec.Replicas
+	}
+	deployment.Minimum, deployment.Maximum = replicas, replicas
+}
+
+// capacityLevel returns the capacity level of the given plan in the given facilities, or in the metro of the shoot if
+// no facilities are given. As machines can be created in any of the facilities, the highest of their levels is
+// returned. An empty level is returned if the capacity of the plan is not reported.
+func (w *workerDelegate) capacityLevel(ctx context.Context, credentials *equinixmetal.Credentials, machineType string, facilities []string) (string, error) {
+	if len(facilities) == 0 {
+		capacity, err := w.listMetroCapacity(ctx, credentials)
+		if err != nil {
+			return "", err
+		}
+		level := capacity[machineType]
+		return level.GetLevel(), nil
+	}
+
+	capacity, err := w.listFacilityCapacity(ctx, credentials)
+	if err != nil {
+		return "", err
+	}
+
+	var level string
+	for _, facility := range facilities {
+		facilityLevel := capacity[facility][machineType]
+		if slices.Index(capacityLevels, facilityLevel.GetLevel()) > slices.Index(capacityLevels, level) {
+			level = facilityLevel.GetLevel()
+		}
+	}
+	return level, nil
+}
+
+// updateCapacityCondition updates the CapacityAvailable condition of the Worker if it changed.
+func (w *workerDelegate) updateCapacityCondition(ctx context.Context, status gardencorev1beta1.ConditionStatus, reason, message string, codes ...gardencorev1beta1.ErrorCode) error {
+	builder, err := gardencorev1beta1helper.NewConditionBuilder(ConditionTypeCapacityAvailable)
+	if err != nil {
+		return err
+	}
+	if oldCondition := gardencorev1beta1helper.GetCondition(w.worker.Status.Conditions, ConditionTypeCapacityAvailable); oldCondition != nil {
+		builder.WithOldCondition(*oldCondition)
+	}
+
+	condition, updated := builder.WithStatus(status).WithReason(reason).WithMessage(message).WithCodes(codes...).Build()
+	if !updated {
+		return nil
+	}
+
+	patch := client.MergeFrom(w.worker.DeepCopy())
+	w.worker.Status.Conditions = gardencorev1beta1helper.MergeConditions(w.worker.Status.Conditions, condition)
+	return w.client.Status().Patch(ctx, w.worker, patch)
+}
+
+// listMetroCapacity returns the capacity levels of the plans in the metro of the shoot. They are only fetched once per
+// reconciliation.
+func (w *workerDelegate) listMetroCapacity(ctx context.Context, credentials *equinixmetal.Credentials) (map[string]metalv1.CapacityLevelPerBaremetal, error) {
+	if w.metroCapacity != nil {
+		return w.metroCapacity, nil
+	}
+
+	equinixClient, err := w.newClient(string(credentials.APIToken))
+	if err != nil {
+		return nil, err
+	}
+
+	capacity, err := equinixClient.ListMetroCapacity(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list capacity of metros: %w", err)
+	}
+
+	w.metroCapacity = capacity[w.worker.Spec.Region]
+	if w.metroCapacity == nil {
+		w.metroCapacity = map[string]metalv1.CapacityLevelPerBaremetal{}
+	}
+	return w.metroCapacity, nil
+}
+
+// listFacilityCapacity returns the capacity levels of the plans per facility. They are only fetched once per
+// reconciliation.
+func (w *workerDelegate) listFacilityCapacity(ctx context.Context, credentials *equinixmetal.Credentials) (map[string]map[string]metalv1.CapacityLevelPerBaremetal, error) {
+	if w.facilityCapacity != nil {
+		return w.facilityCapacity, nil
+	}
+
+	equinixClient, err := w.newClient(string(credentials.APIToken))
+	if err != nil {
+		return nil, err
+	}
+
+	capacity, err := equinixClient.ListFacilityCapacity(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not list capacity of facilities: %w", err)
+	}
+
+	w.facilityCapacity = capacity
+	if w.facilityCapacity == nil {
+		w.facilityCapacity = map[string]map[string]metalv1.CapacityLevelPerBaremetal{}
+	}
+	return w.facilityCapacity, nil
+}
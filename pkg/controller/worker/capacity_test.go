@@ -0,0 +1,280 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	"context"
+
+	"github.com/equinix/equinix-sdk-go/services/metalv1"
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	"github.com/gardener/gardener/extensions/pkg/controller/worker"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	gardencorev1beta1helper "github.com/gardener/gardener/pkg/apis/core/v1beta1/helper"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	mockclient "github.com/gardener/gardener/third_party/mock/controller-runtime/client"
+	machinev1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/config"
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal"
+	eqxcmclient "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal/client"
+	mockeqxcmclient "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal/client/mock"
+)
+
+var _ = Describe("Capacity", func() {
+	const (
+		namespace = "shoot--foo--bar"
+		region    = "ny"
+		facility  = "ny5"
+	)
+
+	var (
+		ctx  = context.TODO()
+		ctrl *gomock.Controller
+
+		c             *mockclient.MockClient
+		statusWriter  *mockclient.MockStatusWriter
+		equinixClient *mockeqxcmclient.MockClientInterface
+		recorder      *record.FakeRecorder
+		w             *workerDelegate
+
+		capacity = func(level string) metalv1.CapacityLevelPerBaremetal {
+			return metalv1.CapacityLevelPerBaremetal{Level: ptr.To(level)}
+		}
+
+		expectGetSecret = func() {
+			c.EXPECT().Get(ctx, client.ObjectKey{Namespace: namespace, Name: "secret"}, gomock.AssignableToTypeOf(&corev1.Secret{})).DoAndReturn(
+				func(_ context.Context, _ client.ObjectKey, secret *corev1.Secret, _ ...client.GetOption) error {
+					secret.Data = map[string][]byte{
+						equinixmetal.APIToken:  []byte("token"),
+						equinixmetal.ProjectID: []byte("project"),
+					}
+					return nil
+				})
+		}
+
+		expectCondition = func(status gardencorev1beta1.ConditionStatus, reason string) {
+			c.EXPECT().Status().Return(statusWriter)
+			statusWriter.EXPECT().Patch(ctx, gomock.AssignableToTypeOf(&extensionsv1alpha1.Worker{}), gomock.Any()).DoAndReturn(
+				func(_ context.Context, worker *extensionsv1alpha1.Worker, _ client.Patch, _ ...client.SubResourcePatchOption) error {
+					condition := gardencorev1beta1helper.GetCondition(worker.Status.Conditions, ConditionTypeCapacityAvailable)
+					Expect(condition).NotTo(BeNil())
+					Expect(condition.Status).To(Equal(status))
+					Expect(condition.Reason).To(Equal(reason))
+					return nil
+				})
+		}
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		c = mockclient.NewMockClient(ctrl)
+		statusWriter = mockclient.NewMockStatusWriter(ctrl)
+		equinixClient = mockeqxcmclient.NewMockClientInterface(ctrl)
+		recorder = record.NewFakeRecorder(10)
+
+		w = &workerDelegate{
+			client: c,
+			newClient: func(_ string) (eqxcmclient.ClientInterface, error) {
+				return equinixClient, nil
+			},
+			recorder:      recorder,
+			capacityCheck: &config.CapacityCheck{},
+			cluster:       &extensionscontroller.Cluster{Shoot: &gardencorev1beta1.Shoot{}},
+			worker: &extensionsv1alpha1.Worker{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: namespace},
+				Spec: extensionsv1alpha1.WorkerSpec{
+					Region:    region,
+					SecretRef: corev1.SecretReference{Name: "secret", Namespace: namespace},
+				},
+			},
+			machineDeployments: worker.MachineDeployments{
+				{Name: namespace + "-pool-1", ClassName: namespace + "-pool-1-abcde", SecretName: namespace + "-pool-1-abcde", Minimum: 1, Maximum: 3},
+				{Name: namespace + "-pool-2-z1", ClassName: namespace + "-pool-2-z1-abcde", SecretName: namespace + "-pool-2-z1-abcde", Minimum: 1, Maximum: 2},
+			},
+			machineClasses: []map[string]interface{}{
+				{"name": namespace + "-pool-1-abcde"},
+				{"name": namespace + "-pool-2-z1-abcde"},
+			},
+			deploymentCapacities: []deploymentCapacity{
+				{name: namespace + "-pool-1", className: namespace + "-pool-1-abcde", machineType: "c3.small.x86"},
+				{name: namespace + "-pool-2-z1", className: namespace + "-pool-2-z1-abcde", machineType: "m3.large.x86", facilities: []string{facility}},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	It("should do nothing if the capacity check is disabled", func() {
+		w.capacityCheck.Enabled = ptr.To(false)
+
+		Expect(w.checkCapacity(ctx)).To(Succeed())
+	})
+
+	It("should not check the capacity of up-to-date machine deployments", func() {
+		w.deploymentCapacities = w.deploymentCapacities[:1]
+		w.worker.Status.MachineDeployments = []extensionsv1alpha1.MachineDeployment{{Name: namespace + "-pool-1"}}
+
+		c.EXPECT().Get(ctx, client.ObjectKey{Namespace: namespace, Name: namespace + "-pool-1"}, gomock.AssignableToTypeOf(&machinev1alpha1.MachineDeployment{})).DoAndReturn(
+			func(_ context.Context, _ client.ObjectKey, machineDeployment *machinev1alpha1.MachineDeployment, _ ...client.GetOption) error {
+				machineDeployment.Spec.Template.Spec.Class.Name = namespace + "-pool-1-abcde"
+				return nil
+			})
+		expectCondition(gardencorev1beta1.ConditionTrue, "CapacityAvailable")
+
+		Expect(w.checkCapacity(ctx)).To(Succeed())
+	})
+
+	It("should emit a warning for limited capacity", func() {
+		expectGetSecret()
+		equinixClient.EXPECT().ListMetroCapacity(ctx).Return(map[string]map[string]metalv1.CapacityLevelPerBaremetal{
+			region: {"c3.small.x86": capacity("normal")},
+		}, nil)
+		equinixClient.EXPECT().ListFacilityCapacity(ctx).Return(map[string]map[string]metalv1.CapacityLevelPerBaremetal{
+			facility: {"m3.large.x86": capacity("limited")},
+			"da11":   {"m3.large.x86": capacity("unavailable")},
+		}, nil)
+		expectCondition(gardencorev1beta1.ConditionTrue, "CapacityLimited")
+
+		Expect(w.checkCapacity(ctx)).To(Succeed())
+		Expect(recorder.Events).To(Receive(ContainSubstring(`Capacity of plan "m3.large.x86" for machine deployment "shoot--foo--bar-pool-2-z1" is limited`)))
+		Expect(recorder.Events).NotTo(Receive())
+	})
+
+	It("should not emit a warning for limited capacity if the warning level is unavailable", func() {
+		w.capacityCheck.WarningLevel = ptr.To("unavailable")
+
+		expectGetSecret()
+		equinixClient.EXPECT().ListMetroCapacity(ctx).Return(nil, nil)
+		equinixClient.EXPECT().ListFacilityCapacity(ctx).Return(map[string]map[string]metalv1.CapacityLevelPerBaremetal{
+			facility: {"m3.large.x86": capacity("limited")},
+		}, nil)
+		expectCondition(gardencorev1beta1.ConditionTrue, "CapacityLimited")
+
+		Expect(w.checkCapacity(ctx)).To(Succeed())
+		Expect(recorder.Events).NotTo(Receive())
+	})
+
+	It("should emit a warning only once if the capacity is checked again", func() {
+		expectGetSecret()
+		expectGetSecret()
+		equinixClient.EXPECT().ListMetroCapacity(ctx).Return(nil, nil)
+		equinixClient.EXPECT().ListFacilityCapacity(ctx).Return(map[string]map[string]metalv1.CapacityLevelPerBaremetal{
+			facility: {"m3.large.x86": capacity("limited")},
+		}, nil)
+		expectCondition(gardencorev1beta1.ConditionTrue, "CapacityLimited")
+
+		Expect(w.checkCapacity(ctx)).To(Succeed())
+		Expect(w.checkCapacity(ctx)).To(Succeed())
+		Expect(recorder.Events).To(Receive(ContainSubstring("InsufficientCapacity")))
+		Expect(recorder.Events).NotTo(Receive())
+	})
+
+	It("should fail if the warning level is not supported", func() {
+		w.capacityCheck.WarningLevel = ptr.To("Limited")
+
+		Expect(w.checkCapacity(ctx)).To(MatchError(ContainSubstring(`unsupported capacity warning level "Limited"`)))
+		Expect(recorder.Events).NotTo(Receive())
+	})
+
+	It("should keep the machine class and replicas of an existing machine deployment if the capacity is unavailable", func() {
+		w.worker.Status.MachineDeployments = []extensionsv1alpha1.MachineDeployment{{Name: namespace + "-pool-1"}}
+
+		c.EXPECT().Get(ctx, client.ObjectKey{Namespace: namespace, Name: namespace + "-pool-1"}, gomock.AssignableToTypeOf(&machinev1alpha1.MachineDeployment{})).DoAndReturn(
+			func(_ context.Context, _ client.ObjectKey, machineDeployment *machinev1alpha1.MachineDeployment, _ ...client.GetOption) error {
+				machineDeployment.Spec.Replicas = 2
+				machineDeployment.Spec.Template.Spec.Class.Name = namespace + "-pool-1-old"
+				return nil
+			})
+		expectGetSecret()
+		equinixClient.EXPECT().ListMetroCapacity(ctx).Return(map[string]map[string]metalv1.CapacityLevelPerBaremetal{
+			region: {"c3.small.x86": capacity("unavailable")},
+		}, nil)
+		equinixClient.EXPECT().ListFacilityCapacity(ctx).Return(nil, nil)
+		c.EXPECT().Status().Return(statusWriter)
+		statusWriter.EXPECT().Patch(ctx, gomock.AssignableToTypeOf(&extensionsv1alpha1.Worker{}), gomock.Any()).DoAndReturn(
+			func(_ context.Context, worker *extensionsv1alpha1.Worker, _ client.Patch, _ ...client.SubResourcePatchOption) error {
+				condition := gardencorev1beta1helper.GetCondition(worker.Status.Conditions, ConditionTypeCapacityAvailable)
+				Expect(condition.Status).To(Equal(gardencorev1beta1.ConditionFalse))
+				Expect(condition.Reason).To(Equal("CapacityUnavailable"))
+				Expect(condition.Message).To(ContainSubstring(`capacity of plan "c3.small.x86" for machine deployment "shoot--foo--bar-pool-1" is unavailable`))
+				Expect(condition.Codes).To(ConsistOf(gardencorev1beta1.ErrorInfraResourcesDepleted))
+				return nil
+			})
+
+		Expect(w.checkCapacity(ctx)).To(Succeed())
+		Expect(recorder.Events).To(Receive(ContainSubstring("InsufficientCapacity")))
+
+		Expect(w.machineDeployments[0]).To(MatchFields(IgnoreExtras, Fields{
+			"ClassName":  Equal(namespace + "-pool-1-old"),
+			"SecretName": Equal(namespace + "-pool-1-old"),
+			"Minimum":    Equal(int32(2)),
+			"Maximum":    Equal(int32(2)),
+		}))
+		Expect(w.machineDeployments[1]).To(MatchFields(IgnoreExtras, Fields{
+			"ClassName": Equal(namespace + "-pool-2-z1-abcde"),
+			"Minimum":   Equal(int32(1)),
+			"Maximum":   Equal(int32(2)),
+		}))
+		Expect(w.machineClasses).To(ConsistOf(HaveKeyWithValue("name", namespace+"-pool-2-z1-abcde")))
+	})
+
+	It("should create a new machine deployment without replicas if the capacity is unavailable", func() {
+		expectGetSecret()
+		equinixClient.EXPECT().ListMetroCapacity(ctx).Return(nil, nil)
+		equinixClient.EXPECT().ListFacilityCapacity(ctx).Return(map[string]map[string]metalv1.CapacityLevelPerBaremetal{
+			facility: {"m3.large.x86": capacity("unavailable")},
+		}, nil)
+		expectCondition(gardencorev1beta1.ConditionFalse, "CapacityUnavailable")
+
+		Expect(w.checkCapacity(ctx)).To(Succeed())
+
+		Expect(w.machineDeployments[0]).To(MatchFields(IgnoreExtras, Fields{
+			"Minimum": Equal(int32(1)),
+			"Maximum": Equal(int32(3)),
+		}))
+		Expect(w.machineDeployments[1]).To(MatchFields(IgnoreExtras, Fields{
+			"ClassName": Equal(namespace + "-pool-2-z1-abcde"),
+			"Minimum":   BeZero(),
+			"Maximum":   BeZero(),
+		}))
+		Expect(w.machineClasses).To(HaveLen(2))
+	})
+
+	It("should only report unavailable capacity if the check is not blocking", func() {
+		w.capacityCheck.Blocking = ptr.To(false)
+
+		expectGetSecret()
+		equinixClient.EXPECT().ListMetroCapacity(ctx).Return(map[string]map[string]metalv1.CapacityLevelPerBaremetal{
+			region: {"c3.small.x86": capacity("unavailable")},
+		}, nil)
+		equinixClient.EXPECT().ListFacilityCapacity(ctx).Return(nil, nil)
+		expectCondition(gardencorev1beta1.ConditionFalse, "CapacityUnavailable")
+
+		Expect(w.checkCapacity(ctx)).To(Succeed())
+		Expect(w.machineDeployments[0].Minimum).To(Equal(int32(1)))
+		Expect(w.machineDeployments[0].Maximum).To(Equal(int32(3)))
+		Expect(w.machineClasses).To(HaveLen(2))
+	})
+
+	DescribeTable("#requiresOnDemandCapacity",
+		func(classSpec map[string]interface{}, expected bool) {
+			Expect(requiresOnDemandCapacity(classSpec)).To(Equal(expected))
+		},
+		Entry("on-demand devices", map[string]interface{}{"reservationIDs": []string{"foo"}}, true),
+		Entry("reserved devices only", map[string]interface{}{"reservedDevicesOnly": true}, false),
+		Entry("spot instances", map[string]interface{}{"spotInstance": true}, false),
+	)
+})
@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/equinix/equinix-sdk-go/services/metalv1"
+	"github.com/gardener/gardener/pkg/utils/flow"
+	corev1 "k8s.io/api/core/v1"
+
+	eqxcmclient "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal/client"
+)
+
+// maxParallelDeviceUpdates is the maximum number of nodes and their devices which are updated in parallel.
+const maxParallelDeviceUpdates = 10
+
+// listDevices lists the devices of the cluster. They are only listed once per reconciliation.
+func (w *workerDelegate) listDevices(ctx context.Context, equinixClient eqxcmclient.ClientInterface, projectID string) error {
+	if w.devices != nil {
+		return nil
+	}
+
+	devices, err := equinixClient.ListDevices(ctx, projectID, w.clusterTag())
+	if err != nil {
+		return fmt.Errorf("could not list devices: %w", err)
+	}
+
+	w.devices = make(map[string]*metalv1.Device, len(devices))
+	for i := range devices {
+		w.devices[devices[i].GetId()] = &devices[i]
+	}
+	return nil
+}
+
+// getDevice returns the device with the given ID from the listed devices of the cluster. Devices which are not part
+// of the list, e.g. because they have been created after the devices were listed, are fetched individually.
+func (w *workerDelegate) getDevice(ctx context.Context, equinixClient eqxcmclient.ClientInterface, deviceID string) (*metalv1.Device, error) {
+	if device, ok := w.devices[deviceID]; ok {
+		return device, nil
+	}
+	return equinixClient.GetDevice(ctx, deviceID)
+}
+
+// forEachNodeDevice calls the given function for each of the given nodes and its device. The nodes are processed in
+// parallel, an error for one node does not stop the processing of the other nodes, all errors are returned combined.
+// Nodes without a device are skipped.
+func (w *workerDelegate) forEachNodeDevice(ctx context.Context, equinixClient eqxcmclient.ClientInterface, nodes []corev1.Node, fn func(ctx context.Context, node *corev1.Node, device *metalv1.Device) error) error {
+	var tasks []flow.TaskFn
+	for i := range nodes {
+		node := &nodes[i]
+
+		deviceID, err := deviceIDFromProviderID(node.Spec.ProviderID)
+		if deviceID == "" || err != nil {
+			continue
+		}
+
+		tasks = append(tasks, func(ctx context.Context) error {
+			device, err := w.getDevice(ctx, equinixClient, deviceID)
+			if err != nil {
+				return fmt.Errorf("could not get device of node %s: %w", node.Name, err)
+			}
+			return fn(ctx, node, device)
+		})
+	}
+
+	return flow.ParallelN(maxParallelDeviceUpdates, tasks...)(ctx)
+}
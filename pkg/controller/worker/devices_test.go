@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/equinix/equinix-sdk-go/services/metalv1"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	mockeqxcmclient "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal/client/mock"
+)
+
+var _ = Describe("Devices", func() {
+	var (
+		ctx           = context.TODO()
+		ctrl          *gomock.Controller
+		equinixClient *mockeqxcmclient.MockClientInterface
+		w             *workerDelegate
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		equinixClient = mockeqxcmclient.NewMockClientInterface(ctrl)
+		w = &workerDelegate{
+			worker: &extensionsv1alpha1.Worker{ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "shoot--foo--bar"}},
+		}
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	newNode := func(name, deviceID string) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       corev1.NodeSpec{ProviderID: "equinixmetal://" + deviceID},
+		}
+	}
+
+	Describe("#getDevice", func() {
+		It("should list the devices of the cluster only once and fetch unknown devices individually", func() {
+			equinixClient.EXPECT().ListDevices(ctx, "project-id", "kubernetes.io/cluster/shoot--foo--bar").Return([]metalv1.Device{
+				{Id: ptr.To("device-1"), Hostname: ptr.To("listed")},
+			}, nil)
+			equinixClient.EXPECT().GetDevice(ctx, "device-2").Return(&metalv1.Device{Id: ptr.To("device-2"), Hostname: ptr.To("fetched")}, nil)
+
+			Expect(w.listDevices(ctx, equinixClient, "project-id")).To(Succeed())
+			Expect(w.listDevices(ctx, equinixClient, "project-id")).To(Succeed())
+
+			Expect(w.getDevice(ctx, equinixClient, "device-1")).To(HaveField("Hostname", ptr.To("listed")))
+			Expect(w.getDevice(ctx, equinixClient, "device-2")).To(HaveField("Hostname", ptr.To("fetched")))
+		})
+	})
+
+	Describe("#forEachNodeDevice", func() {
+		It("should process all nodes with devices and return the combined errors", func() {
+			w.devices = map[string]*metalv1.Device{
+				"device-1": {Id: ptr.To("device-1")},
+				"device-3": {Id: ptr.To("device-3")},
+			}
+			equinixClient.EXPECT().GetDevice(ctx, "device-2").Return(nil, errors.New("not found"))
+
+			var (
+				mutex     sync.Mutex
+				processed []string
+			)
+			err := w.forEachNodeDevice(ctx, equinixClient, []corev1.Node{
+				newNode("node-1", "device-1"),
+				newNode("node-2", "device-2"),
+				newNode("node-3", "device-3"),
+				{ObjectMeta: metav1.ObjectMeta{Name: "node-4"}},
+			}, func(_ context.Context, node *corev1.Node, device *metalv1.Device) error {
+				mutex.Lock()
+				defer mutex.Unlock()
+				processed = append(processed, node.Name)
+				if device.GetId() == "device-3" {
+					return errors.New("could not configure device")
+				}
+				return nil
+			})
+
+			Expect(err).To(MatchError(And(
+				ContainSubstring("could not get device of node node-2: not found"),
+				ContainSubstring("could not configure device"),
+			)))
+			Expect(processed).To(ConsistOf("node-1", "node-3"))
+		})
+	})
+})
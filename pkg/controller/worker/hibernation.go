@@ -0,0 +1,557 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/equinix/equinix-sdk-go/services/metalv1"
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	"github.com/gardener/gardener/pkg/controllerutils"
+	"github.com/gardener/gardener/pkg/utils/flow"
+	machinev1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	"github.com/gardener/machine-controller-manager/pkg/util/provider/machineutils"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal"
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal/helper"
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal"
+	eqxcmclient "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal/client"
+)
+
+const (
+	// machineControllerManagerFinalizer is the finalizer of machine-controller-manager on machines.
+	machineControllerManagerFinalizer = "machine.sapcloud.io/machine-controller-manager"
+	// machineDeploymentLabel is the label of machines and machine sets which contains the name of their machine
+	// deployment.
+	machineDeploymentLabel = "name"
+	// scaleUpDisabledAnnotation is the annotation of machine sets whose scale-up was disabled by the extension.
+	scaleUpDisabledAnnotation = "equinixmetal.provider.extensions.gardener.cloud/scale-up-disabled"
+)
+
+// hibernationTag returns the tag of the devices which are powered off while the shoot is hibernated. It replaces the
+// cluster tag of the devices, so that machine-controller-manager does not consider them to be orphaned.
+func (w *workerDelegate) hibernationTag() string {
+	return fmt.Sprintf("gardener.cloud/hibernated/%s", w.worker.Namespace)
+}
+
+// reconcileHibernation powers off the devices of the worker pools with the PowerOff hibernation mode when the shoot is
+// hibernated and powers them on again when it wakes up. It runs before the machine deployments are reconciled, as
+// machine-controller-manager would otherwise delete the devices when the machine deployments are scaled down, and
+// create new devices when they are scaled up again.
+//
+// Machine-controller-manager keeps running, as it is managed by gardenlet. While the machines are detached from or
+// attached to their devices, the machine sets of their machine deployments are labeled with
+// node.machine.sapcloud.io/scale-up-disabled, so that they do not create machines for missing replicas. If a step
+// fails, the label is kept until the next reconciliation completed the hibernation or wake-up of the devices.
+func (w *workerDelegate) reconcileHibernation(ctx context.Context) error {
+	if w.worker.DeletionTimestamp != nil {
+		return nil
+	}
+
+	workerStatus, err := w.decodeWorkerProviderStatus()
+	if err != nil {
+		return err
+	}
+
+	hibernated := extensionscontroller.IsHibernationEnabled(w.cluster)
+	if !hibernated && len(workerStatus.HibernatedDevices) == 0 {
+		return nil
+	}
+
+	var machines []machinev1alpha1.Machine
+	if hibernated {
+		if machines, err = w.listMachinesToHibernate(ctx); err != nil {
+			return err
+		}
+		if len(machines) == 0 {
+			// a previous hibernation which failed after the machines were deleted is resumed
+			machineSets, err := w.listScaleUpDisabledMachineSets(ctx)
+			if err != nil || len(machineSets) == 0 {
+				return err
+			}
+		}
+	}
+
+	credentials, err := equinixmetal.GetCredentialsFromSecretRef(ctx, w.client, w.worker.Spec.SecretRef)
+	if err != nil {
+		return fmt.Errorf("could not get credentials from secret: %w", err)
+	}
+
+	equinixClient, err := w.newClient(string(credentials.APIToken))
+	if err != nil {
+		return err
+	}
+
+	if hibernated {
+		return w.hibernateMachines(ctx, equinixClient, workerStatus, machines)
+	}
+	return w.wakeUpDevices(ctx, equinixClient, workerStatus)
+}
+
+// listMachinesToHibernate returns the machines of the worker pools with the PowerOff hibernation mode which have a
+// device.
+func (w *workerDelegate) listMachinesToHibernate(ctx context.Context) ([]machinev1alpha1.Machine, error) {
+	pools := sets.New[string]()
+	for _, pool := range w.worker.Spec.Pools {
+		workerConfig, err := helper.WorkerConfigFromRawExtension(pool.ProviderConfig)
+		if err != nil {
+			return nil, err
+		}
+		if helper.HibernationMode(w.controlPlaneConfig, workerConfig) == api.HibernationModePowerOff {
+			pools.Insert(pool.Name)
+		}
+	}
+	if pools.Len() == 0 {
+		return nil, nil
+	}
+
+	machineList := &machinev1alpha1.MachineList{}
+	if err := w.client.List(ctx, machineList, client.InNamespace(w.worker.Namespace)); err != nil {
+		return nil, fmt.Errorf("could not list machines: %w", err)
+	}
+
+	var machines []machinev1alpha1.Machine
+	for _, machine := range machineList.Items {
+		deviceID, err := deviceIDFromProviderID(machine.Spec.ProviderID)
+		if deviceID == "" || err != nil || machine.DeletionTimestamp != nil {
+			continue
+		}
+		if pools.Has(machine.Spec.NodeTemplateSpec.Labels[v1beta1constants.LabelWorkerPool]) {
+			machines = append(machines, machine)
+		}
+	}
+	return machines, nil
+}
+
+// hibernateMachines replaces the cluster tag of the devices of the given machines with the hibernation tag and records
+// them in the worker provider status. Afterwards, the machines are deleted without deleting the devices and their
+// machine deployments are scaled down, before the devices are powered off and their nodes are deleted. The devices are
+// only powered off once they are not managed by machine-controller-manager anymore, as it would otherwise replace the
+// machines of the unhealthy nodes.
+func (w *workerDelegate) hibernateMachines(ctx context.Context, equinixClient eqxcmclient.ClientInterface, workerStatus *api.WorkerStatus, machines []machinev1alpha1.Machine) error {
+	var (
+		devices = make([]*metalv1.Device, len(machines))
+		tasks   []flow.TaskFn
+	)
+	for i, machine := range machines {
+		deviceID, _ := deviceIDFromProviderID(machine.Spec.ProviderID)
+		tasks = append(tasks, func(ctx context.Context) error {
+			device, err := w.hibernateDevice(ctx, equinixClient, deviceID)
+			if err != nil {
+				return fmt.Errorf("could not hibernate device of machine %s: %w", machine.Name, err)
+			}
+			devices[i] = device
+			return nil
+		})
+	}
+	if err := flow.ParallelN(maxParallelDeviceUpdates, tasks...)(ctx); err != nil {
+		return err
+	}
+
+	for _, machine := range machines {
+		if slices.ContainsFunc(workerStatus.HibernatedDevices, func(device api.HibernatedDevice) bool { return device.Name == machine.Name }) {
+			continue
+		}
+		deviceID, _ := deviceIDFromProviderID(machine.Spec.ProviderID)
+		workerStatus.HibernatedDevices = append(workerStatus.HibernatedDevices, api.HibernatedDevice{
+			Name:              machine.Name,
+			Node:              machine.Labels[machinev1alpha1.NodeLabelKey],
+			Pool:              machine.Spec.NodeTemplateSpec.Labels[v1beta1constants.LabelWorkerPool],
+			MachineDeployment: machine.Labels[machineDeploymentLabel],
+			DeviceID:          deviceID,
+		})
+	}
+	if err := w.updateWorkerProviderStatus(ctx, workerStatus); err != nil {
+		return fmt.Errorf("could not record hibernated devices: %w", err)
+	}
+
+	// the machine sets must not replace the deleted machines until their machine deployments are scaled down, which
+	// must not happen before, as machine-controller-manager would delete the devices of the surplus machines
+	machineDeployments := sets.New[string]()
+	for _, hibernatedDevice := range workerStatus.HibernatedDevices {
+		machineDeployments.Insert(hibernatedDevice.MachineDeployment)
+	}
+	if err := w.disableMachineSetScaleUp(ctx, sets.List(machineDeployments)...); err != nil {
+		return err
+	}
+
+	for i := range machines {
+		if err := w.deleteMachineWithoutDevice(ctx, &machines[i]); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range sets.List(machineDeployments) {
+		if err := w.scaleMachineDeployment(ctx, name, 0); err != nil {
+			return err
+		}
+	}
+
+	tasks = nil
+	for _, hibernatedDevice := range workerStatus.HibernatedDevices {
+		var device *metalv1.Device
+		if i := slices.IndexFunc(devices, func(device *metalv1.Device) bool { return device.GetId() == hibernatedDevice.DeviceID }); i >= 0 {
+			device = devices[i]
+		}
+		tasks = append(tasks, func(ctx context.Context) error {
+			if err := powerOffDevice(ctx, equinixClient, hibernatedDevice.DeviceID, device); err != nil {
+				return fmt.Errorf("could not power off device of machine %s: %w", hibernatedDevice.Name, err)
+			}
+			return nil
+		})
+	}
+	if err := flow.ParallelN(maxParallelDeviceUpdates, tasks...)(ctx); err != nil {
+		return err
+	}
+
+	// the nodes are not deleted by machine-controller-manager as the machines are deleted without their devices
+	shootClient, err := w.newShootClient(ctx, w.worker.Namespace)
+	if err != nil {
+		return fmt.Errorf("could not create shoot client: %w", err)
+	}
+	for _, hibernatedDevice := range workerStatus.HibernatedDevices {
+		if hibernatedDevice.Node != "" {
+			if err := client.IgnoreNotFound(shootClient.Delete(ctx, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: hibernatedDevice.Node}})); err != nil {
+				return fmt.Errorf("could not delete node %s: %w", hibernatedDevice.Node, err)
+			}
+		}
+	}
+
+	return w.enableMachineSetScaleUp(ctx)
+}
+
+// hibernateDevice replaces the cluster tag of the given device with the hibernation tag and returns the device.
+func (w *workerDelegate) hibernateDevice(ctx context.Context, equinixClient eqxcmclient.ClientInterface, deviceID string) (*metalv1.Device, error) {
+	device, err := equinixClient.GetDevice(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	return device, replaceDeviceTag(ctx, equinixClient, device, w.clusterTag(), w.hibernationTag())
+}
+
+// powerOffDevice powers off the device with the given ID if it is active. The device is only read if it is not given.
+func powerOffDevice(ctx context.Context, equinixClient eqxcmclient.ClientInterface, deviceID string, device *metalv1.Device) error {
+	if device == nil {
+		var err error
+		if device, err = equinixClient.GetDevice(ctx, deviceID); err != nil {
+			return err
+		}
+	}
+
+	if device.GetState() != metalv1.DEVICESTATE_ACTIVE {
+		return nil
+	}
+	return equinixClient.PerformDeviceAction(ctx, deviceID, metalv1.DeviceActionInput{Type: metalv1.DEVICEACTIONINPUTTYPE_POWER_OFF})
+}
+
+// deleteMachineWithoutDevice removes the finalizer of machine-controller-manager from the given machine and deletes it,
+// so that its device is not deleted. The deletion is conditional on the machine being unchanged, as
+// machine-controller-manager adds its finalizer again to machines which it reconciles in the meantime and would then
+// delete the device.
+func (w *workerDelegate) deleteMachineWithoutDevice(ctx context.Context, machine *machinev1alpha1.Machine) error {
+	if err := controllerutils.RemoveFinalizers(ctx, w.client, machine, machineControllerManagerFinalizer); err != nil {
+		return fmt.Errorf("could not remove finalizer of machine %s: %w", machine.Name, err)
+	}
+	if err := w.client.Delete(ctx, machine, client.Preconditions{UID: &machine.UID, ResourceVersion: &machine.ResourceVersion}); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("could not delete machine %s: %w", machine.Name, err)
+	}
+	return nil
+}
+
+// wakeUpDevices powers on the hibernated devices of the worker provider status and creates machines for them, which
+// adopt the devices. The machines are created with the template of the current machine set of their machine
+// deployment, whose replicas are raised before, so that machine-controller-manager does not delete them as surplus
+// replicas. Only once the machines exist, the hibernation tag of the devices is replaced with the cluster tag, as
+// machine-controller-manager would otherwise delete them as orphans. Devices whose machine deployment does not exist
+// anymore get their cluster tag back without a machine, so that machine-controller-manager deletes them.
+func (w *workerDelegate) wakeUpDevices(ctx context.Context, equinixClient eqxcmclient.ClientInterface, workerStatus *api.WorkerStatus) error {
+	var (
+		devices = make([]*metalv1.Device, len(workerStatus.HibernatedDevices))
+		tasks   []flow.TaskFn
+	)
+	for i, hibernatedDevice := range workerStatus.HibernatedDevices {
+		tasks = append(tasks, func(ctx context.Context) error {
+			device, err := wakeUpDevice(ctx, equinixClient, hibernatedDevice.DeviceID)
+			if err != nil {
+				return fmt.Errorf("could not wake up device of machine %s: %w", hibernatedDevice.Name, err)
+			}
+			devices[i] = device
+			return nil
+		})
+	}
+	if err := flow.ParallelN(maxParallelDeviceUpdates, tasks...)(ctx); err != nil {
+		return err
+	}
+
+	var (
+		machineSets = map[string]*machinev1alpha1.MachineSet{}
+		adopted     = map[string]int32{}
+	)
+	for _, hibernatedDevice := range workerStatus.HibernatedDevices {
+		machineSet, err := w.currentMachineSet(ctx, hibernatedDevice.MachineDeployment)
+		if err != nil {
+			return err
+		}
+		if machineSet == nil {
+			continue
+		}
+		machineSets[hibernatedDevice.MachineDeployment] = machineSet
+		adopted[hibernatedDevice.MachineDeployment]++
+	}
+
+	// the machine sets must not create new machines for the raised replicas
+	machineDeployments := sets.List(sets.KeySet(adopted))
+	if err := w.disableMachineSetScaleUp(ctx, machineDeployments...); err != nil {
+		return err
+	}
+	for _, name := range machineDeployments {
+		if err := w.scaleMachineDeployment(ctx, name, adopted[name]); err != nil {
+			return err
+		}
+	}
+
+	for _, hibernatedDevice := range workerStatus.HibernatedDevices {
+		if machineSet := machineSets[hibernatedDevice.MachineDeployment]; machineSet != nil {
+			if err := w.createMachineForDevice(ctx, machineSet, hibernatedDevice.Name, hibernatedDevice.DeviceID); err != nil {
+				return err
+			}
+		}
+	}
+
+	tasks = nil
+	for i, hibernatedDevice := range workerStatus.HibernatedDevices {
+		tasks = append(tasks, func(ctx context.Context) error {
+			if err := replaceDeviceTag(ctx, equinixClient, devices[i], w.hibernationTag(), w.clusterTag()); err != nil {
+				return fmt.Errorf("could not tag device of machine %s: %w", hibernatedDevice.Name, err)
+			}
+			return nil
+		})
+	}
+	if err := flow.ParallelN(maxParallelDeviceUpdates, tasks...)(ctx); err != nil {
+		return err
+	}
+
+	workerStatus.HibernatedDevices = nil
+	if err := w.updateWorkerProviderStatus(ctx, workerStatus); err != nil {
+		return fmt.Errorf("could not remove hibernated devices: %w", err)
+	}
+
+	return w.enableMachineSetScaleUp(ctx)
+}
+
+// wakeUpDevice powers on the given device if it is inactive and returns the device.
+func wakeUpDevice(ctx context.Context, equinixClient eqxcmclient.ClientInterface, deviceID string) (*metalv1.Device, error) {
+	device, err := equinixClient.GetDevice(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if device.GetState() == metalv1.DEVICESTATE_INACTIVE {
+		if err := equinixClient.PerformDeviceAction(ctx, deviceID, metalv1.DeviceActionInput{Type: metalv1.DEVICEACTIONINPUTTYPE_POWER_ON}); err != nil {
+			return nil, fmt.Errorf("could not power on device: %w", err)
+		}
+	}
+	return device, nil
+}
+
+// currentMachineSet returns the machine set of the given machine deployment which uses its current machine class.
+// Nil is returned if the machine deployment or such a machine set does not exist.
+func (w *workerDelegate) currentMachineSet(ctx context.Context, machineDeploymentName string) (*machinev1alpha1.MachineSet, error) {
+	machineDeployment := &machinev1alpha1.MachineDeployment{}
+	if err := w.client.Get(ctx, client.ObjectKey{Namespace: w.worker.Namespace, Name: machineDeploymentName}, machineDeployment); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("could not get machine deployment %s: %w", machineDeploymentName, err)
+	}
+
+	machineSetList := &machinev1alpha1.MachineSetList{}
+	if err := w.client.List(ctx, machineSetList, client.InNamespace(w.worker.Namespace), client.MatchingLabels{machineDeploymentLabel: machineDeploymentName}); err != nil {
+		return nil, fmt.Errorf("could not list machine sets of machine deployment %s: %w", machineDeploymentName, err)
+	}
+
+	for i := range machineSetList.Items {
+		if machineSetList.Items[i].Spec.Template.Spec.Class.Name == machineDeployment.Spec.Template.Spec.Class.Name {
+			return &machineSetList.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// createMachineForDevice creates a machine of the given machine set for the device with the given ID.
+// Machine-controller-manager finds the existing device by the provider ID of the machine instead of creating a new one.
+func (w *workerDelegate) createMachineForDevice(ctx context.Context, machineSet *machinev1alpha1.MachineSet, name, deviceID string) error {
+	machine := &machinev1alpha1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   machineSet.Namespace,
+			Labels:      machineSet.Spec.Template.Labels,
+			Annotations: machineSet.Spec.Template.Annotations,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(machineSet, machinev1alpha1.SchemeGroupVersion.WithKind("MachineSet")),
+			},
+		},
+		Spec: *machineSet.Spec.Template.Spec.DeepCopy(),
+	}
+	machine.Spec.ProviderID = "equinixmetal://" + deviceID
+
+	if err := w.client.Create(ctx, machine); client.IgnoreAlreadyExists(err) != nil {
+		return fmt.Errorf("could not create machine %s: %w", name, err)
+	}
+	return nil
+}
+
+// scaleMachineDeployment sets the replicas of the given machine deployment and of its machine sets which use its
+// current machine class. Older machine sets are scaled down.
+func (w *workerDelegate) scaleMachineDeployment(ctx context.Context, name string, replicas int32) error {
+	machineDeployment := &machinev1alpha1.MachineDeployment{}
+	if err := w.client.Get(ctx, client.ObjectKey{Namespace: w.worker.Namespace, Name: name}, machineDeployment); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+
+	patch := client.MergeFrom(machineDeployment.DeepCopy())
+	machineDeployment.Spec.Replicas = replicas
+	if err := w.client.Patch(ctx, machineDeployment, patch); err != nil {
+		return fmt.Errorf("could not scale machine deployment %s: %w", name, err)
+	}
+
+	machineSetList := &machinev1alpha1.MachineSetList{}
+	if err := w.client.List(ctx, machineSetList, client.InNamespace(w.worker.Namespace), client.MatchingLabels{machineDeploymentLabel: name}); err != nil {
+		return fmt.Errorf("could not list machine sets of machine deployment %s: %w", name, err)
+	}
+
+	for i := range machineSetList.Items {
+		machineSet := &machineSetList.Items[i]
+
+		machineSetReplicas := replicas
+		if machineSet.Spec.Template.Spec.Class.Name != machineDeployment.Spec.Template.Spec.Class.Name {
+			machineSetReplicas = 0
+		}
+
+		patch := client.MergeFrom(machineSet.DeepCopy())
+		machineSet.Spec.Replicas = machineSetReplicas
+		if err := w.client.Patch(ctx, machineSet, patch); err != nil {
+			return fmt.Errorf("could not scale machine set %s: %w", machineSet.Name, err)
+		}
+	}
+	return nil
+}
+
+// disableMachineSetScaleUp labels the machine sets of the given machine deployments with
+// node.machine.sapcloud.io/scale-up-disabled, so that machine-controller-manager does not create machines for them.
+// Machine sets which are labeled by the extension are annotated, so that only their label is removed again.
+func (w *workerDelegate) disableMachineSetScaleUp(ctx context.Context, machineDeploymentNames ...string) error {
+	for _, name := range machineDeploymentNames {
+		machineSetList := &machinev1alpha1.MachineSetList{}
+		if err := w.client.List(ctx, machineSetList, client.InNamespace(w.worker.Namespace), client.MatchingLabels{machineDeploymentLabel: name}); err != nil {
+			return fmt.Errorf("could not list machine sets of machine deployment %s: %w", name, err)
+		}
+
+		for i := range machineSetList.Items {
+			machineSet := &machineSetList.Items[i]
+			if _, ok := machineSet.Labels[machineutils.LabelKeyMachineSetScaleUpDisabled]; ok {
+				continue
+			}
+
+			patch := client.MergeFrom(machineSet.DeepCopy())
+			metav1.SetMetaDataLabel(&machineSet.ObjectMeta, machineutils.LabelKeyMachineSetScaleUpDisabled, "true")
+			metav1.SetMetaDataAnnotation(&machineSet.ObjectMeta, scaleUpDisabledAnnotation, "true")
+			if err := w.client.Patch(ctx, machineSet, patch); err != nil {
+				return fmt.Errorf("could not disable scale-up of machine set %s: %w", machineSet.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// enableMachineSetScaleUp removes the node.machine.sapcloud.io/scale-up-disabled label from the machine sets which were
+// labeled by the extension.
+func (w *workerDelegate) enableMachineSetScaleUp(ctx context.Context) error {
+	machineSets, err := w.listScaleUpDisabledMachineSets(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := range machineSets {
+		machineSet := &machineSets[i]
+		patch := client.MergeFrom(machineSet.DeepCopy())
+		delete(machineSet.Labels, machineutils.LabelKeyMachineSetScaleUpDisabled)
+		delete(machineSet.Annotations, scaleUpDisabledAnnotation)
+		if err := w.client.Patch(ctx, machineSet, patch); err != nil {
+			return fmt.Errorf("could not enable scale-up of machine set %s: %w", machineSet.Name, err)
+		}
+	}
+	return nil
+}
+
+// listScaleUpDisabledMachineSets returns the machine sets whose scale-up was disabled by the extension.
+func (w *workerDelegate) listScaleUpDisabledMachineSets(ctx context.Context) ([]machinev1alpha1.MachineSet, error) {
+	machineSetList := &machinev1alpha1.MachineSetList{}
+	if err := w.client.List(ctx, machineSetList, client.InNamespace(w.worker.Namespace), client.HasLabels{machineutils.LabelKeyMachineSetScaleUpDisabled}); err != nil {
+		return nil, fmt.Errorf("could not list machine sets: %w", err)
+	}
+
+	return slices.DeleteFunc(machineSetList.Items, func(machineSet machinev1alpha1.MachineSet) bool {
+		return machineSet.Annotations[scaleUpDisabledAnnotation] != "true"
+	}), nil
+}
+
+// deleteHibernatedDevices deletes the devices which are powered off while the shoot is hibernated and removes them from
+// the worker provider status.
+func (w *workerDelegate) deleteHibernatedDevices(ctx context.Context) error {
+	workerStatus, err := w.decodeWorkerProviderStatus()
+	if err != nil {
+		return err
+	}
+	if len(workerStatus.HibernatedDevices) == 0 {
+		return nil
+	}
+
+	credentials, err := equinixmetal.GetCredentialsFromSecretRef(ctx, w.client, w.worker.Spec.SecretRef)
+	if err != nil {
+		return fmt.Errorf("could not get credentials from secret: %w", err)
+	}
+
+	equinixClient, err := w.newClient(string(credentials.APIToken))
+	if err != nil {
+		return err
+	}
+
+	for _, hibernatedDevice := range workerStatus.HibernatedDevices {
+		if err := equinixClient.DeleteDevice(ctx, hibernatedDevice.DeviceID); err != nil {
+			return fmt.Errorf("could not delete device of machine %s: %w", hibernatedDevice.Name, err)
+		}
+	}
+
+	workerStatus.HibernatedDevices = nil
+	if err := w.updateWorkerProviderStatus(ctx, workerStatus); err != nil {
+		return fmt.Errorf("could not remove hibernated devices: %w", err)
+	}
+	return nil
+}
+
+// replaceDeviceTag replaces the given tag of the device with the new tag.
+func replaceDeviceTag(ctx context.Context, equinixClient eqxcmclient.ClientInterface, device *metalv1.Device, oldTag, newTag string) error {
+	tags := slices.DeleteFunc(slices.Clone(device.GetTags()), func(tag string) bool { return tag == oldTag })
+	if !slices.Contains(tags, newTag) {
+		tags = append(tags, newTag)
+	}
+	if slices.Equal(tags, device.GetTags()) {
+		return nil
+	}
+
+	if _, err := equinixClient.UpdateDevice(ctx, device.GetId(), metalv1.DeviceUpdateInput{Tags: tags}); err != nil {
+		return fmt.Errorf("could not update tags of device: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,377 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/equinix/equinix-sdk-go/services/metalv1"
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	machinev1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	api "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal"
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal/install"
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal"
+	eqxcmclient "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal/client"
+	mockeqxcmclient "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal/client/mock"
+)
+
+var _ = Describe("Hibernation", func() {
+	const (
+		namespace         = "shoot--foo--bar"
+		machineDeployment = namespace + "-pool-1-z1"
+		machineClass      = machineDeployment + "-abcde"
+	)
+
+	var (
+		ctx  = context.TODO()
+		ctrl *gomock.Controller
+
+		seedScheme    *runtime.Scheme
+		seedClient    client.Client
+		shootClient   client.Client
+		equinixClient *mockeqxcmclient.MockClientInterface
+		w             *workerDelegate
+
+		clusterTag     = "kubernetes.io/cluster/" + namespace
+		hibernationTag = "gardener.cloud/hibernated/" + namespace
+
+		machineSet = func(replicas int32) *machinev1alpha1.MachineSet {
+			return &machinev1alpha1.MachineSet{
+				ObjectMeta: metav1.ObjectMeta{Name: machineDeployment + "-12345", Namespace: namespace, Labels: map[string]string{"name": machineDeployment}},
+				Spec: machinev1alpha1.MachineSetSpec{
+					Replicas: replicas,
+					Template: machinev1alpha1.MachineTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"name": machineDeployment, "machine-template-hash": "12345"}},
+						Spec: machinev1alpha1.MachineSpec{
+							Class:            machinev1alpha1.ClassSpec{Kind: "MachineClass", Name: machineClass},
+							NodeTemplateSpec: machinev1alpha1.NodeTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1constants.LabelWorkerPool: "pool-1"}}},
+						},
+					},
+				},
+			}
+		}
+
+		getReplicas = func(obj client.Object) int32 {
+			ExpectWithOffset(1, seedClient.Get(ctx, client.ObjectKeyFromObject(obj), obj)).To(Succeed())
+			switch o := obj.(type) {
+			case *machinev1alpha1.MachineDeployment:
+				return o.Spec.Replicas
+			case *machinev1alpha1.MachineSet:
+				return o.Spec.Replicas
+			}
+			return -1
+		}
+
+		getMachineSet = func() *machinev1alpha1.MachineSet {
+			ms := &machinev1alpha1.MachineSet{}
+			ExpectWithOffset(1, seedClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: machineDeployment + "-12345"}, ms)).To(Succeed())
+			return ms
+		}
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		equinixClient = mockeqxcmclient.NewMockClientInterface(ctrl)
+
+		seedScheme = runtime.NewScheme()
+		Expect(scheme.AddToScheme(seedScheme)).To(Succeed())
+		Expect(machinev1alpha1.AddToScheme(seedScheme)).To(Succeed())
+		Expect(extensionsv1alpha1.AddToScheme(seedScheme)).To(Succeed())
+		Expect(install.AddToScheme(seedScheme)).To(Succeed())
+
+		worker := &extensionsv1alpha1.Worker{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: namespace},
+			Spec: extensionsv1alpha1.WorkerSpec{
+				SecretRef: corev1.SecretReference{Name: "secret", Namespace: namespace},
+				Pools:     []extensionsv1alpha1.WorkerPool{{Name: "pool-1"}, {Name: "pool-2"}},
+			},
+		}
+
+		seedClient = fakeclient.NewClientBuilder().WithScheme(seedScheme).WithStatusSubresource(&extensionsv1alpha1.Worker{}).WithObjects(
+			worker,
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace},
+				Data:       map[string][]byte{equinixmetal.APIToken: []byte("token"), equinixmetal.ProjectID: []byte("project")},
+			},
+		).Build()
+		shootClient = fakeclient.NewClientBuilder().Build()
+
+		w = &workerDelegate{
+			client:  seedClient,
+			decoder: serializer.NewCodecFactory(seedScheme, serializer.EnableStrict).UniversalDecoder(),
+			scheme:  seedScheme,
+			newClient: func(_ string) (eqxcmclient.ClientInterface, error) {
+				return equinixClient, nil
+			},
+			newShootClient: func(_ context.Context, _ string) (client.Client, error) {
+				return shootClient, nil
+			},
+			controlPlaneConfig: &api.ControlPlaneConfig{HibernationMode: ptr.To(api.HibernationModePowerOff)},
+			cluster:            &extensionscontroller.Cluster{Shoot: &gardencorev1beta1.Shoot{}},
+			worker:             worker,
+		}
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	Describe("#PreReconcileHook", func() {
+		Context("shoot is hibernated", func() {
+			var machine *machinev1alpha1.Machine
+
+			BeforeEach(func() {
+				w.cluster.Shoot.Spec.Hibernation = &gardencorev1beta1.Hibernation{Enabled: ptr.To(true)}
+
+				machine = &machinev1alpha1.Machine{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:       machineDeployment + "-12345-abcde",
+						Namespace:  namespace,
+						Labels:     map[string]string{"name": machineDeployment, machinev1alpha1.NodeLabelKey: "node"},
+						Finalizers: []string{machineControllerManagerFinalizer},
+					},
+					Spec: machinev1alpha1.MachineSpec{
+						ProviderID:       "equinixmetal://device-id",
+						NodeTemplateSpec: machinev1alpha1.NodeTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{v1beta1constants.LabelWorkerPool: "pool-1"}}},
+					},
+				}
+				Expect(seedClient.Create(ctx, machine)).To(Succeed())
+				Expect(seedClient.Create(ctx, &machinev1alpha1.MachineDeployment{
+					ObjectMeta: metav1.ObjectMeta{Name: machineDeployment, Namespace: namespace},
+					Spec: machinev1alpha1.MachineDeploymentSpec{
+						Replicas: 1,
+						Template: machinev1alpha1.MachineTemplateSpec{Spec: machinev1alpha1.MachineSpec{Class: machinev1alpha1.ClassSpec{Kind: "MachineClass", Name: machineClass}}},
+					},
+				})).To(Succeed())
+				Expect(seedClient.Create(ctx, machineSet(1))).To(Succeed())
+				Expect(shootClient.Create(ctx, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node"}})).To(Succeed())
+			})
+
+			It("should power off the devices once their machines are deleted and delete their nodes", func() {
+				gomock.InOrder(
+					equinixClient.EXPECT().GetDevice(ctx, "device-id").Return(&metalv1.Device{
+						Id:    ptr.To("device-id"),
+						State: ptr.To(metalv1.DEVICESTATE_ACTIVE),
+						Tags:  []string{clusterTag, "kubernetes.io/role/node"},
+					}, nil),
+					equinixClient.EXPECT().UpdateDevice(ctx, "device-id", metalv1.DeviceUpdateInput{Tags: []string{"kubernetes.io/role/node", hibernationTag}}).DoAndReturn(
+						func(_ context.Context, _ string, _ metalv1.DeviceUpdateInput) (*metalv1.Device, error) {
+							Expect(seedClient.Get(ctx, client.ObjectKeyFromObject(machine), &machinev1alpha1.Machine{})).To(Succeed())
+							return nil, nil
+						}),
+					equinixClient.EXPECT().PerformDeviceAction(ctx, "device-id", metalv1.DeviceActionInput{Type: metalv1.DEVICEACTIONINPUTTYPE_POWER_OFF}).DoAndReturn(
+						func(_ context.Context, _ string, _ metalv1.DeviceActionInput) error {
+							Expect(seedClient.Get(ctx, client.ObjectKeyFromObject(machine), &machinev1alpha1.Machine{})).To(WithTransform(apierrors.IsNotFound, BeTrue()))
+							Expect(getMachineSet().Labels).To(HaveKeyWithValue("node.machine.sapcloud.io/scale-up-disabled", "true"))
+							Expect(getReplicas(&machinev1alpha1.MachineDeployment{ObjectMeta: metav1.ObjectMeta{Name: machineDeployment, Namespace: namespace}})).To(BeZero())
+							Expect(shootClient.Get(ctx, client.ObjectKey{Name: "node"}, &corev1.Node{})).To(Succeed())
+							return nil
+						}),
+				)
+
+				Expect(w.PreReconcileHook(ctx)).To(Succeed())
+
+				workerStatus, err := w.decodeWorkerProviderStatus()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(workerStatus.HibernatedDevices).To(ConsistOf(api.HibernatedDevice{
+					Name:              machine.Name,
+					Node:              "node",
+					Pool:              "pool-1",
+					MachineDeployment: machineDeployment,
+					DeviceID:          "device-id",
+				}))
+
+				Expect(seedClient.Get(ctx, client.ObjectKeyFromObject(machine), machine)).To(WithTransform(apierrors.IsNotFound, BeTrue()))
+				Expect(shootClient.Get(ctx, client.ObjectKey{Name: "node"}, &corev1.Node{})).To(WithTransform(apierrors.IsNotFound, BeTrue()))
+				Expect(getReplicas(&machinev1alpha1.MachineDeployment{ObjectMeta: metav1.ObjectMeta{Name: machineDeployment, Namespace: namespace}})).To(BeZero())
+				Expect(getReplicas(machineSet(0))).To(BeZero())
+				Expect(getMachineSet().Labels).NotTo(HaveKey("node.machine.sapcloud.io/scale-up-disabled"))
+				Expect(getMachineSet().Annotations).To(BeEmpty())
+			})
+
+			It("should not delete machines which changed after their finalizer was removed", func() {
+				// machine-controller-manager adds its finalizer again while the machine is reconciled
+				w.client = interceptor.NewClient(seedClient.(client.WithWatch), interceptor.Funcs{
+					Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+						if err := c.Patch(ctx, obj, patch, opts...); err != nil {
+							return err
+						}
+						if m, ok := obj.(*machinev1alpha1.Machine); ok {
+							latest := m.DeepCopy()
+							latest.Finalizers = []string{machineControllerManagerFinalizer}
+							return c.Update(ctx, latest)
+						}
+						return nil
+					},
+				})
+
+				equinixClient.EXPECT().GetDevice(ctx, "device-id").Return(&metalv1.Device{
+					Id:    ptr.To("device-id"),
+					State: ptr.To(metalv1.DEVICESTATE_ACTIVE),
+					Tags:  []string{clusterTag},
+				}, nil)
+				equinixClient.EXPECT().UpdateDevice(ctx, "device-id", metalv1.DeviceUpdateInput{Tags: []string{hibernationTag}})
+
+				Expect(w.PreReconcileHook(ctx)).To(MatchError(ContainSubstring("could not delete machine")))
+
+				Expect(seedClient.Get(ctx, client.ObjectKeyFromObject(machine), machine)).To(Succeed())
+				Expect(machine.DeletionTimestamp).To(BeNil())
+				Expect(getMachineSet().Labels).To(HaveKeyWithValue("node.machine.sapcloud.io/scale-up-disabled", "true"))
+				Expect(shootClient.Get(ctx, client.ObjectKey{Name: "node"}, &corev1.Node{})).To(Succeed())
+			})
+
+			It("should resume the hibernation if it failed after the machines were deleted", func() {
+				Expect(seedClient.Delete(ctx, machine)).To(Succeed())
+				Expect(w.updateWorkerProviderStatus(ctx, &api.WorkerStatus{HibernatedDevices: []api.HibernatedDevice{
+					{Name: machine.Name, Node: "node", Pool: "pool-1", MachineDeployment: machineDeployment, DeviceID: "device-id"},
+				}})).To(Succeed())
+				Expect(w.disableMachineSetScaleUp(ctx, machineDeployment)).To(Succeed())
+
+				equinixClient.EXPECT().GetDevice(ctx, "device-id").Return(&metalv1.Device{
+					Id:    ptr.To("device-id"),
+					State: ptr.To(metalv1.DEVICESTATE_ACTIVE),
+					Tags:  []string{hibernationTag},
+				}, nil)
+				equinixClient.EXPECT().PerformDeviceAction(ctx, "device-id", metalv1.DeviceActionInput{Type: metalv1.DEVICEACTIONINPUTTYPE_POWER_OFF})
+
+				Expect(w.PreReconcileHook(ctx)).To(Succeed())
+
+				Expect(shootClient.Get(ctx, client.ObjectKey{Name: "node"}, &corev1.Node{})).To(WithTransform(apierrors.IsNotFound, BeTrue()))
+				Expect(getReplicas(&machinev1alpha1.MachineDeployment{ObjectMeta: metav1.ObjectMeta{Name: machineDeployment, Namespace: namespace}})).To(BeZero())
+				Expect(getMachineSet().Labels).NotTo(HaveKey("node.machine.sapcloud.io/scale-up-disabled"))
+			})
+
+			It("should not hibernate the machines of worker pools with the Delete hibernation mode", func() {
+				w.worker.Spec.Pools[0].ProviderConfig = &runtime.RawExtension{Raw: encodeHibernationMode(api.HibernationModeDelete)}
+
+				Expect(w.PreReconcileHook(ctx)).To(Succeed())
+
+				Expect(seedClient.Get(ctx, client.ObjectKeyFromObject(machine), machine)).To(Succeed())
+				Expect(shootClient.Get(ctx, client.ObjectKey{Name: "node"}, &corev1.Node{})).To(Succeed())
+			})
+		})
+
+		Context("shoot wakes up", func() {
+			BeforeEach(func() {
+				Expect(w.updateWorkerProviderStatus(ctx, &api.WorkerStatus{HibernatedDevices: []api.HibernatedDevice{
+					{Name: machineDeployment + "-12345-abcde", Node: "node", Pool: "pool-1", MachineDeployment: machineDeployment, DeviceID: "device-id"},
+					{Name: namespace + "-removed-z1-12345-abcde", Node: "other-node", Pool: "removed", MachineDeployment: namespace + "-removed-z1", DeviceID: "other-device-id"},
+				}})).To(Succeed())
+
+				Expect(seedClient.Create(ctx, &machinev1alpha1.MachineDeployment{
+					ObjectMeta: metav1.ObjectMeta{Name: machineDeployment, Namespace: namespace},
+					Spec: machinev1alpha1.MachineDeploymentSpec{
+						Template: machinev1alpha1.MachineTemplateSpec{Spec: machinev1alpha1.MachineSpec{Class: machinev1alpha1.ClassSpec{Kind: "MachineClass", Name: machineClass}}},
+					},
+				})).To(Succeed())
+				Expect(seedClient.Create(ctx, machineSet(0))).To(Succeed())
+			})
+
+			It("should power on the devices and create machines for them", func() {
+				for _, deviceID := range []string{"device-id", "other-device-id"} {
+					equinixClient.EXPECT().GetDevice(ctx, deviceID).Return(&metalv1.Device{
+						Id:    ptr.To(deviceID),
+						State: ptr.To(metalv1.DEVICESTATE_INACTIVE),
+						Tags:  []string{"kubernetes.io/role/node", hibernationTag},
+					}, nil)
+					equinixClient.EXPECT().PerformDeviceAction(ctx, deviceID, metalv1.DeviceActionInput{Type: metalv1.DEVICEACTIONINPUTTYPE_POWER_ON})
+				}
+				equinixClient.EXPECT().UpdateDevice(ctx, "device-id", metalv1.DeviceUpdateInput{Tags: []string{"kubernetes.io/role/node", clusterTag}}).DoAndReturn(
+					func(_ context.Context, _ string, _ metalv1.DeviceUpdateInput) (*metalv1.Device, error) {
+						Expect(seedClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: machineDeployment + "-12345-abcde"}, &machinev1alpha1.Machine{})).To(Succeed())
+						Expect(getMachineSet().Labels).To(HaveKeyWithValue("node.machine.sapcloud.io/scale-up-disabled", "true"))
+						return nil, nil
+					})
+				equinixClient.EXPECT().UpdateDevice(ctx, "other-device-id", metalv1.DeviceUpdateInput{Tags: []string{"kubernetes.io/role/node", clusterTag}})
+
+				Expect(w.PreReconcileHook(ctx)).To(Succeed())
+
+				machine := &machinev1alpha1.Machine{}
+				Expect(seedClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: machineDeployment + "-12345-abcde"}, machine)).To(Succeed())
+				Expect(machine.Labels).To(Equal(map[string]string{"name": machineDeployment, "machine-template-hash": "12345"}))
+				Expect(machine.OwnerReferences).To(ConsistOf(MatchFields(IgnoreExtras, Fields{
+					"Kind":       Equal("MachineSet"),
+					"Name":       Equal(machineDeployment + "-12345"),
+					"Controller": PointTo(BeTrue()),
+				})))
+				Expect(machine.Spec.ProviderID).To(Equal("equinixmetal://device-id"))
+				Expect(machine.Spec.Class.Name).To(Equal(machineClass))
+				Expect(machine.Spec.NodeTemplateSpec.Labels).To(HaveKeyWithValue(v1beta1constants.LabelWorkerPool, "pool-1"))
+
+				Expect(seedClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: namespace + "-removed-z1-12345-abcde"}, &machinev1alpha1.Machine{})).To(WithTransform(apierrors.IsNotFound, BeTrue()))
+				Expect(getReplicas(&machinev1alpha1.MachineDeployment{ObjectMeta: metav1.ObjectMeta{Name: machineDeployment, Namespace: namespace}})).To(Equal(int32(1)))
+				Expect(getReplicas(machineSet(0))).To(Equal(int32(1)))
+				Expect(getMachineSet().Labels).NotTo(HaveKey("node.machine.sapcloud.io/scale-up-disabled"))
+
+				workerStatus, err := w.decodeWorkerProviderStatus()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(workerStatus.HibernatedDevices).To(BeEmpty())
+			})
+
+			It("should keep the scale-up-disabled label of machine sets which was not added by the extension", func() {
+				ms := getMachineSet()
+				ms.Labels["node.machine.sapcloud.io/scale-up-disabled"] = "true"
+				Expect(seedClient.Update(ctx, ms)).To(Succeed())
+
+				for _, deviceID := range []string{"device-id", "other-device-id"} {
+					equinixClient.EXPECT().GetDevice(ctx, deviceID).Return(&metalv1.Device{
+						Id:    ptr.To(deviceID),
+						State: ptr.To(metalv1.DEVICESTATE_ACTIVE),
+						Tags:  []string{hibernationTag},
+					}, nil)
+					equinixClient.EXPECT().UpdateDevice(ctx, deviceID, metalv1.DeviceUpdateInput{Tags: []string{clusterTag}})
+				}
+
+				Expect(w.PreReconcileHook(ctx)).To(Succeed())
+
+				Expect(getMachineSet().Labels).To(HaveKeyWithValue("node.machine.sapcloud.io/scale-up-disabled", "true"))
+			})
+		})
+	})
+
+	Describe("#PreDeleteHook", func() {
+		It("should delete the hibernated devices", func() {
+			Expect(w.updateWorkerProviderStatus(ctx, &api.WorkerStatus{HibernatedDevices: []api.HibernatedDevice{
+				{Name: "machine", Pool: "pool-1", MachineDeployment: machineDeployment, DeviceID: "device-id"},
+			}})).To(Succeed())
+
+			equinixClient.EXPECT().DeleteDevice(ctx, "device-id")
+
+			Expect(w.PreDeleteHook(ctx)).To(Succeed())
+
+			workerStatus, err := w.decodeWorkerProviderStatus()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(workerStatus.HibernatedDevices).To(BeEmpty())
+		})
+	})
+})
+
+func encodeHibernationMode(hibernationMode string) []byte {
+	data, _ := json.Marshal(map[string]interface{}{
+		"apiVersion":      "equinixmetal.provider.extensions.gardener.cloud/v1alpha1",
+		"kind":            "WorkerConfig",
+		"hibernationMode": hibernationMode,
+	})
+	return data
+}
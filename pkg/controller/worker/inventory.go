@@ -0,0 +1,113 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/equinix/equinix-sdk-go/services/metalv1"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	machinev1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal"
+	eqxcmclient "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal/client"
+)
+
+// reportMachines reports the devices of the machines of the worker in the worker provider status. Machines which are
+// being deleted or whose devices have not been created yet are not reported. Machines whose devices cannot be fetched
+// are left out of the report, the errors are returned after the report has been updated.
+func (w *workerDelegate) reportMachines(ctx context.Context, equinixClient eqxcmclient.ClientInterface) error {
+	machineList := &machinev1alpha1.MachineList{}
+	if err := w.client.List(ctx, machineList, client.InNamespace(w.worker.Namespace)); err != nil {
+		return fmt.Errorf("could not list machines: %w", err)
+	}
+
+	var (
+		machines []api.MachineStatus
+		errs     []error
+	)
+	for _, machine := range machineList.Items {
+		if machine.DeletionTimestamp != nil {
+			continue
+		}
+
+		deviceID, err := deviceIDFromProviderID(machine.Spec.ProviderID)
+		if deviceID == "" || err != nil {
+			continue
+		}
+
+		device, err := w.getDevice(ctx, equinixClient, deviceID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("could not get device of machine %s: %w", machine.Name, err))
+			continue
+		}
+
+		machines = append(machines, newMachineStatus(machine, deviceID, device))
+	}
+
+	slices.SortFunc(machines, func(a, b api.MachineStatus) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	workerStatus, err := w.decodeWorkerProviderStatus()
+	if err != nil {
+		return fmt.Errorf("unable to decode the worker provider status: %w", err)
+	}
+
+	workerStatus.Machines = machines
+	if err := w.updateWorkerProviderStatus(ctx, workerStatus); err != nil {
+		return err
+	}
+
+	return errors.Join(errs...)
+}
+
+// newMachineStatus returns the status of the given machine and its device.
+func newMachineStatus(machine machinev1alpha1.Machine, deviceID string, device *metalv1.Device) api.MachineStatus {
+	var (
+		facility = device.GetFacility()
+		plan     = device.GetPlan()
+		status   = api.MachineStatus{
+			Name:     machine.Name,
+			Node:     machine.Labels[machinev1alpha1.NodeLabelKey],
+			DeviceID: deviceID,
+			Hostname: device.GetHostname(),
+			Facility: facility.GetCode(),
+			Plan:     plan.GetSlug(),
+			State:    string(device.GetState()),
+		}
+	)
+
+	if machine.Spec.NodeTemplateSpec.Labels != nil {
+		status.Pool = machine.Spec.NodeTemplateSpec.Labels[v1beta1constants.LabelWorkerPool]
+	}
+
+	if reservation := device.GetHardwareReservation(); reservation.GetId() != "" {
+		status.ReservationID = reservation.Id
+	}
+
+	for _, ip := range device.GetIpAddresses() {
+		if ip.GetAddress() == "" {
+			continue
+		}
+		if ip.GetPublic() {
+			status.PublicIPs = append(status.PublicIPs, ip.GetAddress())
+		} else {
+			status.PrivateIPs = append(status.PrivateIPs, ip.GetAddress())
+		}
+	}
+
+	if createdAt, ok := device.GetCreatedAtOk(); ok {
+		status.CreatedAt = &metav1.Time{Time: *createdAt}
+	}
+
+	return status
+}
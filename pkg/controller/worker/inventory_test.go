@@ -0,0 +1,156 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/equinix/equinix-sdk-go/services/metalv1"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	mockclient "github.com/gardener/gardener/third_party/mock/controller-runtime/client"
+	machinev1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal"
+	apiv1alpha1 "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal/v1alpha1"
+	mockeqxcmclient "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal/client/mock"
+)
+
+var _ = Describe("Inventory", func() {
+	createdAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	newMachine := func(name, pool, deviceID string) machinev1alpha1.Machine {
+		return machinev1alpha1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{machinev1alpha1.NodeLabelKey: "node-" + name}},
+			Spec: machinev1alpha1.MachineSpec{
+				ProviderID: "equinixmetal://" + deviceID,
+				NodeTemplateSpec: machinev1alpha1.NodeTemplateSpec{
+					ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"worker.gardener.cloud/pool": pool}},
+				},
+			},
+		}
+	}
+
+	device := &metalv1.Device{
+		Hostname:            ptr.To("shoot--foo--bar-pool-1-abc"),
+		Facility:            &metalv1.Facility{Code: ptr.To("ny5")},
+		Plan:                &metalv1.Plan{Slug: ptr.To("c3.small.x86")},
+		HardwareReservation: &metalv1.HardwareReservation{Id: ptr.To("reservation-id")},
+		State:               ptr.To(metalv1.DEVICESTATE_ACTIVE),
+		CreatedAt:           &createdAt,
+		IpAddresses: []metalv1.IPAssignment{
+			{Address: ptr.To("147.75.1.1"), Public: ptr.To(true), AddressFamily: ptr.To[int32](4)},
+			{Address: ptr.To("2604:1380::1"), Public: ptr.To(true), AddressFamily: ptr.To[int32](6)},
+			{Address: ptr.To("10.0.0.1"), Public: ptr.To(false), AddressFamily: ptr.To[int32](4)},
+		},
+	}
+
+	Describe("#newMachineStatus", func() {
+		It("should return the status of the machine and its device", func() {
+			Expect(newMachineStatus(newMachine("machine-1", "pool-1", "device-1"), "device-1", device)).To(Equal(api.MachineStatus{
+				Name:          "machine-1",
+				Node:          "node-machine-1",
+				Pool:          "pool-1",
+				DeviceID:      "device-1",
+				Hostname:      "shoot--foo--bar-pool-1-abc",
+				Facility:      "ny5",
+				Plan:          "c3.small.x86",
+				ReservationID: ptr.To("reservation-id"),
+				PublicIPs:     []string{"147.75.1.1", "2604:1380::1"},
+				PrivateIPs:    []string{"10.0.0.1"},
+				State:         "active",
+				CreatedAt:     &metav1.Time{Time: createdAt},
+			}))
+		})
+
+		It("should leave out the details which the device does not provide yet", func() {
+			Expect(newMachineStatus(newMachine("machine-1", "pool-1", "device-1"), "device-1", &metalv1.Device{})).To(Equal(api.MachineStatus{
+				Name:     "machine-1",
+				Node:     "node-machine-1",
+				Pool:     "pool-1",
+				DeviceID: "device-1",
+			}))
+		})
+	})
+
+	Describe("#reportMachines", func() {
+		var (
+			ctx  = context.TODO()
+			ctrl *gomock.Controller
+
+			c             *mockclient.MockClient
+			statusWriter  *mockclient.MockStatusWriter
+			equinixClient *mockeqxcmclient.MockClientInterface
+			w             *workerDelegate
+		)
+
+		BeforeEach(func() {
+			ctrl = gomock.NewController(GinkgoT())
+			c = mockclient.NewMockClient(ctrl)
+			statusWriter = mockclient.NewMockStatusWriter(ctrl)
+			equinixClient = mockeqxcmclient.NewMockClientInterface(ctrl)
+
+			scheme := runtime.NewScheme()
+			Expect(api.AddToScheme(scheme)).To(Succeed())
+			Expect(apiv1alpha1.AddToScheme(scheme)).To(Succeed())
+
+			w = &workerDelegate{
+				client:  c,
+				decoder: serializer.NewCodecFactory(scheme, serializer.EnableStrict).UniversalDecoder(),
+				scheme:  scheme,
+				worker:  &extensionsv1alpha1.Worker{ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "shoot--foo--bar"}},
+			}
+		})
+
+		AfterEach(func() {
+			ctrl.Finish()
+		})
+
+		It("should report the machines with devices sorted by name in the worker status", func() {
+			deletedMachine := newMachine("machine-0", "pool-1", "device-0")
+			deletedMachine.DeletionTimestamp = &metav1.Time{Time: createdAt}
+
+			c.EXPECT().List(ctx, gomock.AssignableToTypeOf(&machinev1alpha1.MachineList{}), client.InNamespace("shoot--foo--bar")).DoAndReturn(
+				func(_ context.Context, list *machinev1alpha1.MachineList, _ ...client.ListOption) error {
+					list.Items = []machinev1alpha1.Machine{
+						newMachine("machine-2", "pool-2", "device-2"),
+						newMachine("machine-1", "pool-1", "device-1"),
+						newMachine("machine-3", "pool-2", ""),
+						deletedMachine,
+					}
+					return nil
+				})
+			equinixClient.EXPECT().GetDevice(ctx, "device-2").Return(&metalv1.Device{State: ptr.To(metalv1.DEVICESTATE_PROVISIONING)}, nil)
+			equinixClient.EXPECT().GetDevice(ctx, "device-1").Return(device, nil)
+
+			c.EXPECT().Status().Return(statusWriter)
+			statusWriter.EXPECT().Patch(ctx, gomock.AssignableToTypeOf(&extensionsv1alpha1.Worker{}), gomock.Any()).DoAndReturn(
+				func(_ context.Context, worker *extensionsv1alpha1.Worker, _ client.Patch, _ ...client.SubResourcePatchOption) error {
+					workerStatus := worker.Status.ProviderStatus.Object.(*apiv1alpha1.WorkerStatus)
+					Expect(workerStatus.Machines).To(HaveLen(2))
+					Expect(workerStatus.Machines[0].Name).To(Equal("machine-1"))
+					Expect(workerStatus.Machines[0].ReservationID).To(Equal(ptr.To("reservation-id")))
+					Expect(workerStatus.Machines[1]).To(Equal(apiv1alpha1.MachineStatus{
+						Name:     "machine-2",
+						Node:     "node-machine-2",
+						Pool:     "pool-2",
+						DeviceID: "device-2",
+						State:    "provisioning",
+					}))
+					return nil
+				})
+
+			Expect(w.reportMachines(ctx, equinixClient)).To(Succeed())
+		})
+	})
+})
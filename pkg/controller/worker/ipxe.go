@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/equinix/equinix-sdk-go/services/metalv1"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	api "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal"
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal/helper"
+	eqxcmclient "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal/client"
+)
+
+// addIPXEToMachineClassSpec renders the iPXE script url or the inline iPXE script of a worker pool into the given
+// machine class spec. It overrides the iPXE script url of the machine image.
+func (w *workerDelegate) addIPXEToMachineClassSpec(machineClassSpec map[string]interface{}, ipxe *api.IPXE, pool extensionsv1alpha1.WorkerPool) error {
+	values := helper.IPXETemplateValues{
+		Pool:       pool.Name,
+		Metro:      w.worker.Spec.Region,
+		KernelArgs: strings.Join(ipxe.KernelArgs, " "),
+	}
+	if w.cluster != nil && w.cluster.Shoot != nil {
+		values.ShootName = w.cluster.Shoot.Name
+	}
+
+	switch {
+	case ipxe.ScriptURL != nil:
+		scriptURL, err := helper.RenderIPXEScriptURL(*ipxe.ScriptURL, values)
+		if err != nil {
+			return err
+		}
+		machineClassSpec["ipxeScriptUrl"] = scriptURL
+
+	case ipxe.Script != nil:
+		script, err := helper.RenderIPXEScript(*ipxe.Script, values)
+		if err != nil {
+			return err
+		}
+		machineClassSpec["ipxeScriptUrl"] = ""
+		machineClassSpec["ipxeScript"] = script
+	}
+
+	return nil
+}
+
+// ensureDevicesAlwaysPXE updates the devices of the worker pools configuring `alwaysPXE` to boot via iPXE on every
+// boot or only during provisioning. machine-controller-manager does not support this setting, hence, it is applied to
+// the devices once their nodes joined the cluster, i.e., it does not apply to their first boot. The devices of worker
+// pools which no longer configure `alwaysPXE` are reset to only boot via iPXE during provisioning.
+func (w *workerDelegate) ensureDevicesAlwaysPXE(ctx context.Context, equinixClient eqxcmclient.ClientInterface, nodes []corev1.Node) error {
+	if len(w.poolAlwaysPXE) == 0 {
+		return nil
+	}
+
+	var poolNodes []corev1.Node
+	for _, node := range nodes {
+		if _, ok := w.poolAlwaysPXE[node.Labels[v1beta1constants.LabelWorkerPool]]; ok {
+			poolNodes = append(poolNodes, node)
+		}
+	}
+
+	if err := w.forEachNodeDevice(ctx, equinixClient, poolNodes, func(ctx context.Context, node *corev1.Node, device *metalv1.Device) error {
+		alwaysPXE := w.poolAlwaysPXE[node.Labels[v1beta1constants.LabelWorkerPool]]
+		if device.GetAlwaysPxe() == alwaysPXE {
+			return nil
+		}
+		if _, err := equinixClient.UpdateDevice(ctx, device.GetId(), metalv1.DeviceUpdateInput{AlwaysPxe: &alwaysPXE}); err != nil {
+			return fmt.Errorf("could not update iPXE boot of node %s: %w", node.Name, err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return w.removeResetAlwaysPXEPools(ctx)
+}
+
+// alwaysPXEPools returns the names of the worker pools whose devices have been configured to boot via iPXE on every
+// boot according to the worker status.
+func (w *workerDelegate) alwaysPXEPools() (sets.Set[string], error) {
+	workerStatus, err := w.decodeWorkerProviderStatus()
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode the worker provider status: %w", err)
+	}
+
+	pools := sets.New[string]()
+	for _, workerPool := range workerStatus.WorkerPools {
+		if workerPool.AlwaysPXE {
+			pools.Insert(workerPool.Name)
+		}
+	}
+	return pools, nil
+}
+
+// removeResetAlwaysPXEPools removes the worker pools whose devices have been reset to only boot via iPXE during
+// provisioning from the worker pools booting via iPXE on every boot in the worker status.
+func (w *workerDelegate) removeResetAlwaysPXEPools(ctx context.Context) error {
+	reset := false
+	for i := range w.workerPools {
+		workerPool := &w.workerPools[i]
+		if workerPool.AlwaysPXE && !w.poolAlwaysPXE[workerPool.Name] {
+			workerPool.AlwaysPXE = false
+			reset = true
+		}
+	}
+	if !reset {
+		return nil
+	}
+
+	workerStatus, err := w.decodeWorkerProviderStatus()
+	if err != nil {
+		return fmt.Errorf("unable to decode the worker provider status: %w", err)
+	}
+
+	workerStatus.WorkerPools = mergeReservationReports(w.workerPools, workerStatus.WorkerPools)
+	return w.updateWorkerProviderStatus(ctx, workerStatus)
+}
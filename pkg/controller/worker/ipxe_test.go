@@ -0,0 +1,114 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	"context"
+	"errors"
+
+	"github.com/equinix/equinix-sdk-go/services/metalv1"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	mockclient "github.com/gardener/gardener/third_party/mock/controller-runtime/client"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal"
+	apiv1alpha1 "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal/v1alpha1"
+	mockeqxcmclient "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal/client/mock"
+)
+
+var _ = Describe("IPXE", func() {
+	Describe("#ensureDevicesAlwaysPXE", func() {
+		var (
+			ctx           = context.TODO()
+			ctrl          *gomock.Controller
+			equinixClient *mockeqxcmclient.MockClientInterface
+			w             *workerDelegate
+		)
+
+		BeforeEach(func() {
+			ctrl = gomock.NewController(GinkgoT())
+			equinixClient = mockeqxcmclient.NewMockClientInterface(ctrl)
+			w = &workerDelegate{poolAlwaysPXE: map[string]bool{"ipxe": true}}
+		})
+
+		AfterEach(func() {
+			ctrl.Finish()
+		})
+
+		newNode := func(name, pool string) corev1.Node {
+			return corev1.Node{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"worker.gardener.cloud/pool": pool}},
+				Spec:       corev1.NodeSpec{ProviderID: "equinixmetal://" + name},
+			}
+		}
+
+		It("should only update the devices of the worker pool which do not boot via iPXE yet", func() {
+			nodes := []corev1.Node{newNode("device-1", "ipxe"), newNode("device-2", "ipxe"), newNode("device-3", "other")}
+
+			equinixClient.EXPECT().GetDevice(ctx, "device-1").Return(&metalv1.Device{Id: ptr.To("device-1")}, nil)
+			equinixClient.EXPECT().GetDevice(ctx, "device-2").Return(&metalv1.Device{Id: ptr.To("device-2"), AlwaysPxe: ptr.To(true)}, nil)
+			equinixClient.EXPECT().UpdateDevice(ctx, "device-1", metalv1.DeviceUpdateInput{AlwaysPxe: ptr.To(true)})
+
+			Expect(w.ensureDevicesAlwaysPXE(ctx, equinixClient, nodes)).To(Succeed())
+		})
+
+		It("should reset the devices of worker pools which no longer boot via iPXE on every boot", func() {
+			c := mockclient.NewMockClient(ctrl)
+			statusWriter := mockclient.NewMockStatusWriter(ctrl)
+
+			scheme := runtime.NewScheme()
+			Expect(api.AddToScheme(scheme)).To(Succeed())
+			Expect(apiv1alpha1.AddToScheme(scheme)).To(Succeed())
+
+			w.client = c
+			w.scheme = scheme
+			w.decoder = serializer.NewCodecFactory(scheme, serializer.EnableStrict).UniversalDecoder()
+			w.worker = &extensionsv1alpha1.Worker{
+				ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "shoot--foo--bar"},
+				Status: extensionsv1alpha1.WorkerStatus{
+					DefaultStatus: extensionsv1alpha1.DefaultStatus{
+						ProviderStatus: &runtime.RawExtension{Raw: []byte(`{"apiVersion":"equinixmetal.provider.extensions.gardener.cloud/v1alpha1","kind":"WorkerStatus","workerPools":[{"name":"ipxe","alwaysPXE":true}]}`)},
+					},
+				},
+			}
+			w.poolAlwaysPXE = map[string]bool{"ipxe": false}
+			w.workerPools = []api.WorkerPoolStatus{{Name: "ipxe", AlwaysPXE: true}}
+
+			Expect(w.alwaysPXEPools()).To(Equal(sets.New("ipxe")))
+
+			equinixClient.EXPECT().GetDevice(ctx, "device-1").Return(&metalv1.Device{Id: ptr.To("device-1"), AlwaysPxe: ptr.To(true)}, nil)
+			equinixClient.EXPECT().UpdateDevice(ctx, "device-1", metalv1.DeviceUpdateInput{AlwaysPxe: ptr.To(false)})
+			c.EXPECT().Status().Return(statusWriter)
+			statusWriter.EXPECT().Patch(ctx, gomock.AssignableToTypeOf(&extensionsv1alpha1.Worker{}), gomock.Any()).DoAndReturn(
+				func(_ context.Context, worker *extensionsv1alpha1.Worker, _ client.Patch, _ ...client.SubResourcePatchOption) error {
+					Expect(worker.Status.ProviderStatus.Object).To(HaveField("WorkerPools", ConsistOf(apiv1alpha1.WorkerPoolStatus{Name: "ipxe"})))
+					return nil
+				})
+
+			Expect(w.ensureDevicesAlwaysPXE(ctx, equinixClient, []corev1.Node{newNode("device-1", "ipxe")})).To(Succeed())
+			Expect(w.workerPools).To(ConsistOf(api.WorkerPoolStatus{Name: "ipxe"}))
+		})
+
+		It("should keep reporting the worker pool if its devices could not be reset", func() {
+			w.poolAlwaysPXE = map[string]bool{"ipxe": false}
+			w.workerPools = []api.WorkerPoolStatus{{Name: "ipxe", AlwaysPXE: true}}
+
+			equinixClient.EXPECT().GetDevice(ctx, "device-1").Return(&metalv1.Device{Id: ptr.To("device-1"), AlwaysPxe: ptr.To(true)}, nil)
+			equinixClient.EXPECT().UpdateDevice(ctx, "device-1", metalv1.DeviceUpdateInput{AlwaysPxe: ptr.To(false)}).Return(nil, errors.New("fake"))
+
+			Expect(w.ensureDevicesAlwaysPXE(ctx, equinixClient, []corev1.Node{newNode("device-1", "ipxe")})).To(MatchError(ContainSubstring("fake")))
+			Expect(w.workerPools).To(ConsistOf(api.WorkerPoolStatus{Name: "ipxe", AlwaysPXE: true}))
+		})
+	})
+})
@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/equinix/equinix-sdk-go/services/metalv1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal"
+	eqxcmclient "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal/client"
+)
+
+const (
+	capacityTypeSpot     = "spot"
+	capacityTypeOnDemand = "on-demand"
+)
+
+// ensureNodeLabels labels the nodes with the topology and hardware information of their devices. The labels are
+// updated if the devices change, e.g. the hardware reservation label is removed once a device no longer uses one.
+func (w *workerDelegate) ensureNodeLabels(ctx context.Context, shootClient client.Client, equinixClient eqxcmclient.ClientInterface, nodes []corev1.Node) error {
+	return w.forEachNodeDevice(ctx, equinixClient, nodes, func(ctx context.Context, node *corev1.Node, device *metalv1.Device) error {
+		patch := client.MergeFrom(node.DeepCopy())
+		if !setDeviceNodeLabels(node, device) {
+			return nil
+		}
+		if err := shootClient.Patch(ctx, node, patch); err != nil {
+			return fmt.Errorf("could not label node %s: %w", node.Name, err)
+		}
+		return nil
+	})
+}
+
+// setDeviceNodeLabels sets the labels of the given node to the topology and hardware information of the given device.
+// Labels whose information is unknown are left as they are, except for the hardware reservation label which is removed
+// if the device does not use a hardware reservation. It returns true if the labels of the node have been changed.
+func setDeviceNodeLabels(node *corev1.Node, device *metalv1.Device) bool {
+	var (
+		facility     = device.GetFacility()
+		metro        = device.GetMetro()
+		plan         = device.GetPlan()
+		reservation  = device.GetHardwareReservation()
+		capacityType = capacityTypeOnDemand
+	)
+
+	if device.GetSpotInstance() {
+		capacityType = capacityTypeSpot
+	}
+
+	labels := map[string]string{
+		corev1.LabelTopologyZone:              facility.GetCode(),
+		corev1.LabelTopologyRegion:            metro.GetCode(),
+		corev1.LabelInstanceTypeStable:        plan.GetSlug(),
+		equinixmetal.FacilityLabel:            facility.GetCode(),
+		equinixmetal.MetroLabel:               metro.GetCode(),
+		equinixmetal.PlanLabel:                plan.GetSlug(),
+		equinixmetal.CapacityTypeLabel:        capacityType,
+		equinixmetal.HardwareReservationLabel: reservation.GetId(),
+	}
+
+	changed := false
+	for key, value := range labels {
+		current, ok := node.Labels[key]
+		switch {
+		case value == "" && key == equinixmetal.HardwareReservationLabel && ok:
+			delete(node.Labels, key)
+			changed = true
+		case value != "" && current != value:
+			metav1.SetMetaDataLabel(&node.ObjectMeta, key, value)
+			changed = true
+		}
+	}
+
+	return changed
+}
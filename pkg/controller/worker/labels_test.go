@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	"context"
+
+	"github.com/equinix/equinix-sdk-go/services/metalv1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	mockeqxcmclient "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal/client/mock"
+)
+
+var _ = Describe("Labels", func() {
+	newDevice := func(reservationID string, spot bool) *metalv1.Device {
+		device := &metalv1.Device{
+			Facility:     &metalv1.Facility{Code: ptr.To("ny5")},
+			Metro:        &metalv1.DeviceMetro{Code: ptr.To("ny")},
+			Plan:         &metalv1.Plan{Slug: ptr.To("c3.small.x86")},
+			SpotInstance: ptr.To(spot),
+		}
+		if reservationID != "" {
+			device.HardwareReservation = &metalv1.HardwareReservation{Id: ptr.To(reservationID)}
+		}
+		return device
+	}
+
+	Describe("#setDeviceNodeLabels", func() {
+		It("should set the topology and hardware labels", func() {
+			node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"foo": "bar"}}}
+
+			Expect(setDeviceNodeLabels(node, newDevice("reservation-id", false))).To(BeTrue())
+			Expect(node.Labels).To(Equal(map[string]string{
+				"foo":                                    "bar",
+				"topology.kubernetes.io/zone":            "ny5",
+				"topology.kubernetes.io/region":          "ny",
+				"node.kubernetes.io/instance-type":       "c3.small.x86",
+				"metal.equinix.com/facility":             "ny5",
+				"metal.equinix.com/metro":                "ny",
+				"metal.equinix.com/plan":                 "c3.small.x86",
+				"metal.equinix.com/capacity-type":        "on-demand",
+				"metal.equinix.com/hardware-reservation": "reservation-id",
+			}))
+		})
+
+		It("should update changed labels and remove the hardware reservation label", func() {
+			node := &corev1.Node{}
+			setDeviceNodeLabels(node, newDevice("reservation-id", false))
+
+			Expect(setDeviceNodeLabels(node, newDevice("", true))).To(BeTrue())
+			Expect(node.Labels).To(HaveKeyWithValue("metal.equinix.com/capacity-type", "spot"))
+			Expect(node.Labels).NotTo(HaveKey("metal.equinix.com/hardware-reservation"))
+		})
+
+		It("should not change up-to-date labels", func() {
+			node := &corev1.Node{}
+			setDeviceNodeLabels(node, newDevice("reservation-id", false))
+
+			Expect(setDeviceNodeLabels(node, newDevice("reservation-id", false))).To(BeFalse())
+		})
+
+		It("should keep the labels whose information is unknown", func() {
+			node := &corev1.Node{}
+			setDeviceNodeLabels(node, newDevice("", false))
+
+			Expect(setDeviceNodeLabels(node, &metalv1.Device{})).To(BeFalse())
+			Expect(node.Labels).To(HaveKeyWithValue("topology.kubernetes.io/zone", "ny5"))
+		})
+	})
+
+	Describe("#ensureNodeLabels", func() {
+		var (
+			ctx           = context.TODO()
+			ctrl          *gomock.Controller
+			equinixClient *mockeqxcmclient.MockClientInterface
+			w             *workerDelegate
+		)
+
+		BeforeEach(func() {
+			ctrl = gomock.NewController(GinkgoT())
+			equinixClient = mockeqxcmclient.NewMockClientInterface(ctrl)
+			w = &workerDelegate{}
+		})
+
+		AfterEach(func() {
+			ctrl.Finish()
+		})
+
+		It("should label the nodes with devices", func() {
+			nodes := []corev1.Node{
+				{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}, Spec: corev1.NodeSpec{ProviderID: "equinixmetal://device-1"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "node-2"}},
+			}
+			shootClient := fakeclient.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(&nodes[0], &nodes[1]).Build()
+
+			equinixClient.EXPECT().GetDevice(ctx, "device-1").Return(newDevice("", false), nil)
+
+			Expect(w.ensureNodeLabels(ctx, shootClient, equinixClient, nodes)).To(Succeed())
+
+			node := &corev1.Node{}
+			Expect(shootClient.Get(ctx, client.ObjectKey{Name: "node-1"}, node)).To(Succeed())
+			Expect(node.Labels).To(HaveKeyWithValue("metal.equinix.com/plan", "c3.small.x86"))
+			Expect(shootClient.Get(ctx, client.ObjectKey{Name: "node-2"}, node)).To(Succeed())
+			Expect(node.Labels).To(BeEmpty())
+		})
+	})
+})
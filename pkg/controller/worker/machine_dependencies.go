@@ -6,13 +6,15 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 
+	"github.com/equinix/equinix-sdk-go/services/metalv1"
 	extensionsconfig "github.com/gardener/gardener/extensions/pkg/apis/config/v1alpha1"
 	"github.com/gardener/gardener/extensions/pkg/util"
 	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
-	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -24,10 +26,6 @@ import (
 )
 
 func (w *workerDelegate) PostReconcileHook(ctx context.Context) error {
-	const (
-		equinixMetalPrivateNetworkAnnotations = "metal.equinix.com/network-4-private"
-	)
-
 	// get the private IPs and providerIDs from the shoot nodes
 	_, shootClient, err := util.NewClientForShoot(ctx, w.client, w.worker.Namespace, client.Options{}, extensionsconfig.RESTOptions{})
 	if err != nil {
@@ -39,7 +37,7 @@ func (w *workerDelegate) PostReconcileHook(ctx context.Context) error {
 		return fmt.Errorf("could not get credentials from secret: %v", err)
 	}
 
-	equinixClient, err := eqxcmclient.NewClient(string(credentials.APIToken))
+	equinixClient, err := w.newClient(string(credentials.APIToken))
 	if err != nil {
 		return err
 	}
@@ -49,44 +47,64 @@ func (w *workerDelegate) PostReconcileHook(ctx context.Context) error {
 		return fmt.Errorf("failed to get shoot nodes: %v", err)
 	}
 
-	// go through each node, for each one without the right annotation, get the private network
-	targetCIDRs := sets.New[string]()
-	for _, n := range shootNodes.Items {
-		if n.Annotations[equinixMetalPrivateNetworkAnnotations] == "" {
-			// we didn't have it, so get it from the Equinix Metal API, and save it
-			deviceID, err := deviceIDFromProviderID(n.Spec.ProviderID)
-			if deviceID == "" || err != nil {
-				continue
-			}
-
-			nodePrivateNetwork, err := GetNodePrivateNetwork(ctx, equinixClient, deviceID)
-			if err != nil {
-				return fmt.Errorf("error getting private network from Equinix Metal API for %s: %v", n.Spec.ProviderID, err)
-			}
-
-			if nodePrivateNetwork == "" {
-				continue
-			}
-
-			if n.Annotations[equinixMetalPrivateNetworkAnnotations] == nodePrivateNetwork {
-				continue
-			}
-
-			// if it was not set already, set it and save it
-			patch := client.StrategicMergeFrom(n.DeepCopy())
-			metav1.SetMetaDataAnnotation(&n.ObjectMeta, equinixMetalPrivateNetworkAnnotations, nodePrivateNetwork)
-			if err := shootClient.Patch(ctx, &n, patch); err != nil {
-				return fmt.Errorf("unable to patch node %s with private network cidr: %v", n.Name, err)
-			}
+	if err := w.listDevices(ctx, equinixClient, string(credentials.ProjectID)); err != nil {
+		return err
+	}
+
+	if w.machineClasses == nil {
+		if err := w.generateMachineConfig(ctx); err != nil {
+			return err
 		}
+	}
 
-		targetCIDRs.Insert(n.Annotations[equinixMetalPrivateNetworkAnnotations])
+	// The nodes and devices are processed independently of each other, i.e., a failure for a single node does not
+	// prevent the other steps. All errors are returned once all steps have been performed.
+	var errs []error
+
+	targetCIDRs, privateNetworksErr := w.ensureNodePrivateNetworks(ctx, shootClient, equinixClient, shootNodes.Items)
+	if privateNetworksErr != nil {
+		errs = append(errs, fmt.Errorf("failed to get private networks of nodes: %w", privateNetworksErr))
+	}
+
+	if err := w.ensureNodeLabels(ctx, shootClient, equinixClient, shootNodes.Items); err != nil {
+		errs = append(errs, fmt.Errorf("failed to label nodes: %w", err))
+	}
+
+	if err := w.ensureDeviceNetworks(ctx, equinixClient, shootNodes.Items); err != nil {
+		errs = append(errs, fmt.Errorf("failed to configure device networks: %w", err))
+	}
+
+	// the machines have been rolled at this point, hence, VLANs removed from the worker pools are no longer assigned
+	if err := w.deleteObsoleteVLANs(ctx, equinixClient, string(credentials.ProjectID)); err != nil {
+		errs = append(errs, fmt.Errorf("failed to delete obsolete VLANs: %w", err))
+	}
+
+	if err := w.ensureDevicesAlwaysPXE(ctx, equinixClient, shootNodes.Items); err != nil {
+		errs = append(errs, fmt.Errorf("failed to configure iPXE boot of devices: %w", err))
+	}
+
+	if err := w.reportReservations(ctx, equinixClient, string(credentials.ProjectID), shootNodes.Items); err != nil {
+		errs = append(errs, fmt.Errorf("failed to report hardware reservations: %w", err))
+	}
+
+	if err := w.reportMachines(ctx, equinixClient); err != nil {
+		errs = append(errs, fmt.Errorf("failed to report machines: %w", err))
+	}
+
+	// machine deployments which have been created by this reconciliation adopt their devices now
+	if err := w.adoptDevices(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to adopt devices: %w", err))
+	}
+
+	// the node network must not be updated with the private networks of only some of the nodes
+	if privateNetworksErr != nil {
+		return errors.Join(errs...)
 	}
 
 	infra := &extensionsv1alpha1.Infrastructure{}
 	if err := w.client.Get(ctx, client.ObjectKey{Namespace: w.worker.Namespace,
 		Name: w.worker.Name}, infra); err != nil {
-		return fmt.Errorf("failed to get %s infrastructure: %v", w.worker.Name, err)
+		return errors.Join(append(errs, fmt.Errorf("failed to get %s infrastructure: %v", w.worker.Name, err))...)
 	}
 
 	if infra.Status.NodesCIDR == nil ||
@@ -99,35 +117,104 @@ func (w *workerDelegate) PostReconcileHook(ctx context.Context) error {
 
 		infra.Status.NodesCIDR = &joinedNetwork
 		if err := w.client.Patch(ctx, infra, patch); err != nil {
-			return err
+			return errors.Join(append(errs, err)...)
 		}
 	}
 
-	return controlplane.EnsureNodeNetworkOfVpnSeed(ctx, w.client, w.worker.Namespace, targetCIDRs)
+	if err := controlplane.EnsureNodeNetworkOfVpnSeed(ctx, w.client, w.worker.Namespace, targetCIDRs); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// ensureNodePrivateNetworks annotates the nodes with the private networks of their devices and returns the private
+// networks of all nodes.
+func (w *workerDelegate) ensureNodePrivateNetworks(ctx context.Context, shootClient client.Client, equinixClient eqxcmclient.ClientInterface, nodes []corev1.Node) (sets.Set[string], error) {
+	const (
+		equinixMetalPrivateNetworkAnnotations = "metal.equinix.com/network-4-private"
+	)
+
+	var (
+		targetCIDRs   = sets.New[string]()
+		mutex         sync.Mutex
+		nodesToUpdate []corev1.Node
+	)
+
+	// only the nodes without the right annotation need to be updated
+	for _, n := range nodes {
+		if n.Annotations[equinixMetalPrivateNetworkAnnotations] == "" {
+			nodesToUpdate = append(nodesToUpdate, n)
+			continue
+		}
+		targetCIDRs.Insert(n.Annotations[equinixMetalPrivateNetworkAnnotations])
+	}
+
+	err := w.forEachNodeDevice(ctx, equinixClient, nodesToUpdate, func(ctx context.Context, n *corev1.Node, device *metalv1.Device) error {
+		// we didn't have it, so get it from the Equinix Metal API, and save it
+		nodePrivateNetwork, err := devicePrivateNetwork(device)
+		if err != nil {
+			return fmt.Errorf("error getting private network from Equinix Metal API for %s: %v", n.Spec.ProviderID, err)
+		}
+
+		if nodePrivateNetwork == "" {
+			return nil
+		}
+
+		patch := client.StrategicMergeFrom(n.DeepCopy())
+		metav1.SetMetaDataAnnotation(&n.ObjectMeta, equinixMetalPrivateNetworkAnnotations, nodePrivateNetwork)
+		if err := shootClient.Patch(ctx, n, patch); err != nil {
+			return fmt.Errorf("unable to patch node %s with private network cidr: %v", n.Name, err)
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		targetCIDRs.Insert(nodePrivateNetwork)
+		return nil
+	})
+
+	return targetCIDRs, err
 }
 
 // PreReconcileHook implements genericactuator.WorkerDelegate.
-func (w *workerDelegate) PreReconcileHook(_ context.Context) error {
-	return nil
+func (w *workerDelegate) PreReconcileHook(ctx context.Context) error {
+	if err := w.reconcileHibernation(ctx); err != nil {
+		return err
+	}
+	// the VLANs are created before the machine classes are generated, as those reference their IDs
+	if err := w.ensureManagedVLANs(ctx); err != nil {
+		return err
+	}
+	// devices are adopted before the replicas of the machine deployments are reconciled, so that raising the minimum
+	// of a worker pool together with its adopted devices does not provision new devices
+	return w.adoptDevices(ctx)
 }
 
 // PreDeleteHook implements genericactuator.WorkerDelegate.
-func (w *workerDelegate) PreDeleteHook(_ context.Context) error {
-	return nil
+func (w *workerDelegate) PreDeleteHook(ctx context.Context) error {
+	// the devices which are powered off while the shoot is hibernated have no machines, hence, they are not deleted by
+	// machine-controller-manager
+	return w.deleteHibernatedDevices(ctx)
 }
 
 // PostDeleteHook implements genericactuator.WorkerDelegate.
-func (w *workerDelegate) PostDeleteHook(_ context.Context) error {
-	return nil
-}
+func (w *workerDelegate) PostDeleteHook(ctx context.Context) error {
+	credentials, err := equinixmetal.GetCredentialsFromSecretRef(ctx, w.client, w.worker.Spec.SecretRef)
+	if err != nil {
+		return fmt.Errorf("could not get credentials from secret: %v", err)
+	}
 
-// GetNodePrivateNetwork use the Equinix Metal API to get the CIDR of the private network given a providerID.
-func GetNodePrivateNetwork(ctx context.Context, equinixClient eqxcmclient.ClientInterface, deviceID string) (string, error) {
-	device, err := equinixClient.GetDevice(ctx, deviceID)
+	equinixClient, err := w.newClient(string(credentials.APIToken))
 	if err != nil {
-		return "", err
+		return err
 	}
 
+	// the VLANs can only be deleted after all devices have been deleted
+	return w.deleteManagedVLANs(ctx, equinixClient, string(credentials.ProjectID))
+}
+
+// devicePrivateNetwork returns the CIDR of the private network of the given device.
+func devicePrivateNetwork(device *metalv1.Device) (string, error) {
 	for _, net := range device.IpAddresses {
 		// we only want the private, management, ipv4 network
 		if net.GetPublic() || !net.GetManagement() || net.GetAddressFamily() != 4 {
@@ -175,7 +262,7 @@ func deviceIDFromProviderID(providerID string) (string, error) {
 		deviceID = providerID
 
 	default:
-		return "", errors.Errorf("unexpected providerID format: %s, format should be: 'device-id' or 'equinixmetal://device-id'", providerID)
+		return "", fmt.Errorf("unexpected providerID format: %s, format should be: 'device-id' or 'equinixmetal://device-id'", providerID)
 	}
 
 	return deviceID, nil
@@ -8,7 +8,9 @@ import (
 	"context"
 
 	"github.com/gardener/gardener/extensions/pkg/controller/worker"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
 	"github.com/pkg/errors"
+	"k8s.io/utils/ptr"
 
 	api "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal"
 	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal/helper"
@@ -28,6 +30,7 @@ func (w *workerDelegate) UpdateMachineImagesStatus(ctx context.Context) error {
 	}
 
 	workerStatus.MachineImages = w.machineImages
+	workerStatus.WorkerPools = mergeReservationReports(w.workerPools, workerStatus.WorkerPools)
 	if err := w.updateWorkerProviderStatus(ctx, workerStatus); err != nil {
 		return errors.Wrapf(err, "unable to update worker provider status")
 	}
@@ -35,8 +38,8 @@ func (w *workerDelegate) UpdateMachineImagesStatus(ctx context.Context) error {
 	return nil
 }
 
-func (w *workerDelegate) findMachineImage(name, version string) (*api.MachineImageVersion, error) {
-	machineImage, err := helper.FindImageFromCloudProfile(w.cloudProfileConfig, name, version)
+func (w *workerDelegate) findMachineImage(name, version string, architecture *string) (*api.MachineImageVersion, error) {
+	machineImage, err := helper.FindImageFromCloudProfile(w.cloudProfileConfig, name, version, architecture)
 	if err == nil {
 		return machineImage, nil
 	}
@@ -49,22 +52,24 @@ func (w *workerDelegate) findMachineImage(name, version string) (*api.MachineIma
 				"could not decode worker status of worker '%s'", w.worker.Name)
 		}
 
-		machineImage, err := helper.FindMachineImage(workerStatus.MachineImages, name, version)
+		machineImage, err := helper.FindMachineImage(workerStatus.MachineImages, name, version, architecture)
 		if err != nil {
-			return nil, worker.ErrorMachineImageNotFound(name, version)
+			return nil, worker.ErrorMachineImageNotFound(name, version, ptr.Deref(architecture, v1beta1constants.ArchitectureAMD64))
 		}
 
 		return &api.MachineImageVersion{
-			Version: machineImage.Version,
-			ID:      machineImage.ID,
+			Version:       machineImage.Version,
+			ID:            machineImage.ID,
+			IPXEScriptURL: machineImage.IPXEScriptURL,
+			Architecture:  machineImage.Architecture,
 		}, nil
 	}
 
-	return nil, worker.ErrorMachineImageNotFound(name, version)
+	return nil, worker.ErrorMachineImageNotFound(name, version, ptr.Deref(architecture, v1beta1constants.ArchitectureAMD64))
 }
 
 func appendMachineImage(machineImages []api.MachineImage, machineImage api.MachineImage) []api.MachineImage {
-	if _, err := helper.FindMachineImage(machineImages, machineImage.Name, machineImage.Version); err != nil {
+	if _, err := helper.FindMachineImage(machineImages, machineImage.Name, machineImage.Version, machineImage.Architecture); err != nil {
 		return append(machineImages, machineImage)
 	}
 	return machineImages
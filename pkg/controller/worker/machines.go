@@ -6,21 +6,33 @@ package worker
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"maps"
 	"path/filepath"
+	"slices"
+	"strconv"
 
+	"github.com/equinix/equinix-sdk-go/services/metalv1"
 	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
 	"github.com/gardener/gardener/extensions/pkg/controller/worker"
 	genericworkeractuator "github.com/gardener/gardener/extensions/pkg/controller/worker/genericactuator"
 	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
 	gardencorev1beta1helper "github.com/gardener/gardener/pkg/apis/core/v1beta1/helper"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
 	extensionsv1alpha1helper "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1/helper"
 	"github.com/gardener/gardener/pkg/client/kubernetes"
+	"github.com/gardener/gardener/pkg/utils"
 	machinev1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/utils/ptr"
 
 	"github.com/gardener/gardener-extension-provider-equinix-metal/charts"
 	api "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal"
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal/helper"
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal/validation"
 	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal"
 )
 
@@ -50,8 +62,19 @@ func (w *workerDelegate) generateMachineConfig(ctx context.Context) error {
 		machineDeployments = worker.MachineDeployments{}
 		machineClasses     []map[string]interface{}
 		machineImages      []api.MachineImage
+		workerPools        []api.WorkerPoolStatus
+		reservations       = map[string]poolReservations{}
+		networks           = map[string]poolNetwork{}
+		alwaysPXE          = map[string]bool{}
+		capacities         []deploymentCapacity
+		adoptions          []adoptionTarget
 	)
 
+	previousAlwaysPXE, err := w.alwaysPXEPools()
+	if err != nil {
+		return err
+	}
+
 	infrastructureStatus := &api.InfrastructureStatus{}
 	if _, _, err := w.decoder.Decode(w.worker.Spec.InfrastructureProviderStatus.Raw, nil, infrastructureStatus); err != nil {
 		return err
@@ -66,7 +89,7 @@ func (w *workerDelegate) generateMachineConfig(ctx context.Context) error {
 		return err
 	}
 
-	for _, pool := range w.worker.Spec.Pools {
+	for i, pool := range w.worker.Spec.Pools {
 		workerConfig := &api.WorkerConfig{}
 		if pool.ProviderConfig != nil && pool.ProviderConfig.Raw != nil {
 			if _, _, err := w.decoder.Decode(pool.ProviderConfig.Raw, nil, workerConfig); err != nil {
@@ -74,20 +97,59 @@ func (w *workerDelegate) generateMachineConfig(ctx context.Context) error {
 			}
 		}
 
-		workerPoolHash, err := worker.WorkerPoolHash(pool, w.cluster, []string{}, []string{}, []string{})
+		storage, err := machineStorage(workerConfig.Storage)
+		if err != nil {
+			return fmt.Errorf("could not generate storage layout of worker pool %q: %w", pool.Name, err)
+		}
+		volumeLayout := newPoolVolumeLayout(workerConfig, pool)
+
+		// The billing cycle, the tags, the SSH keys, the fallback machine types and the on-demand overflow are
+		// deliberately not part of the additional hash data: changing them only affects devices created afterwards and
+		// must not roll the existing machines of the worker pool. This only holds for the hash version V2, the hash
+		// version V1 of worker pools without a node agent secret contains the whole provider config, i.e., any change of
+		// it rolls the machines. The custom data is consumed by the devices during boot and the storage and volume
+		// layouts are applied when the devices are provisioned, hence, changing them requires new machines.
+		additionalHashDataV2 := []string{}
+		if workerConfig.CustomData != nil {
+			additionalHashDataV2 = append(additionalHashDataV2, string(workerConfig.CustomData.Raw))
+		}
+		if storage != nil {
+			additionalHashDataV2 = append(additionalHashDataV2, string(storage))
+		}
+		if workerConfig.VolumeLayout != nil {
+			data, err := json.Marshal(volumeLayout)
+			if err != nil {
+				return err
+			}
+			additionalHashDataV2 = append(additionalHashDataV2, string(data))
+		}
+		if network := workerConfig.Network; network != nil {
+			additionalHashDataV2 = append(additionalHashDataV2, ptr.Deref(network.Type, api.NetworkTypeLayer3))
+			for _, vlan := range network.VLANs {
+				additionalHashDataV2 = append(additionalHashDataV2, vlan.Name)
+			}
+		}
+
+		workerPoolHash, err := worker.WorkerPoolHash(pool, w.cluster, []string{}, additionalHashDataV2, []string{})
 		if err != nil {
 			return err
 		}
 
-		machineImage, err := w.findMachineImage(pool.MachineImage.Name, pool.MachineImage.Version)
+		arch := ptr.To(ptr.Deref(pool.Architecture, v1beta1constants.ArchitectureAMD64))
+
+		machineImage, err := w.findMachineImage(pool.MachineImage.Name, pool.MachineImage.Version, arch)
 		if err != nil {
 			return err
 		}
+		if errs := validation.ValidateWorkerConfig(workerConfig, machineImage, field.NewPath("spec", "pools").Index(i).Child("providerConfig")); len(errs) > 0 {
+			return fmt.Errorf("invalid provider config of worker pool %q: %w", pool.Name, errs.ToAggregate())
+		}
 		machineImages = appendMachineImage(machineImages, api.MachineImage{
 			Name:          pool.MachineImage.Name,
 			Version:       pool.MachineImage.Version,
 			ID:            machineImage.ID,
 			IPXEScriptURL: machineImage.IPXEScriptURL,
+			Architecture:  arch,
 		})
 
 		userData, err := worker.FetchUserData(ctx, w.client, w.worker.Namespace, pool)
@@ -95,18 +157,30 @@ func (w *workerDelegate) generateMachineConfig(ctx context.Context) error {
 			return err
 		}
 
+		// the additional SSH keys must not be added to the devices if SSH access to the nodes is disabled
+		sshConfig := workerConfig.SSH
+		if !gardencorev1beta1helper.ShootEnablesSSHAccess(w.cluster.Shoot) {
+			sshConfig = nil
+		}
+		sshKeys, err := w.machineSSHKeys(ctx, credentials, infrastructureStatus.SSHKeyID, sshConfig)
+		if err != nil {
+			return fmt.Errorf("could not determine SSH keys of worker pool %q: %w", pool.Name, err)
+		}
+
+		machineType, err := w.selectMachineType(ctx, credentials, pool.MachineType, workerConfig.FallbackMachineTypes)
+		if err != nil {
+			return fmt.Errorf("could not select machine type of worker pool %q: %w", pool.Name, err)
+		}
+
 		machineClassSpec := map[string]interface{}{
 			"OS":            machineImage.ID,
 			"ipxeScriptUrl": machineImage.IPXEScriptURL,
 			"projectID":     string(credentials.ProjectID),
-			"billingCycle":  "hourly",
-			"machineType":   pool.MachineType,
+			"billingCycle":  ptr.Deref(workerConfig.BillingCycle, api.BillingCycleHourly),
+			"machineType":   machineType,
 			"metro":         w.worker.Spec.Region,
-			"sshKeys":       []string{infrastructureStatus.SSHKeyID},
-			"tags": []string{
-				fmt.Sprintf("kubernetes.io/cluster/%s", w.worker.Namespace),
-				"kubernetes.io/role/node",
-			},
+			"sshKeys":       sshKeys,
+			"tags":          machineTags(w.worker.Namespace, workerConfig, pool),
 			"secret": map[string]interface{}{
 				"cloudConfig": string(userData),
 			},
@@ -116,75 +190,372 @@ func (w *workerDelegate) generateMachineConfig(ctx context.Context) error {
 			},
 		}
 
-		if len(pool.Zones) > 0 {
-			machineClassSpec["facilities"] = pool.Zones
+		workerPoolStatus := api.WorkerPoolStatus{Name: pool.Name}
+		if len(workerConfig.FallbackMachineTypes) > 0 {
+			workerPoolStatus.MachineType = machineType
+		}
+		if hibernationMode := helper.HibernationMode(w.controlPlaneConfig, workerConfig); hibernationMode != api.HibernationModeDelete {
+			workerPoolStatus.HibernationMode = hibernationMode
 		}
 
-		if len(workerConfig.ReservationIDs) > 0 {
-			machineClassSpec["reservationIDs"] = workerConfig.ReservationIDs
+		reservationIDs := workerConfig.ReservationIDs
+		if workerConfig.ReservationSelector != nil || len(reservationIDs) > 0 {
+			if workerConfig.ReservationSelector != nil {
+				hardwareReservations, err := w.listHardwareReservations(ctx, credentials)
+				if err != nil {
+					return err
+				}
+
+				workerPoolStatus.ReservationIDs = selectReservationIDs(hardwareReservations, workerConfig.ReservationSelector, pool.MachineType)
+				reservationIDs = sets.List(sets.New(reservationIDs...).Insert(workerPoolStatus.ReservationIDs...))
+			}
+
+			reservations[pool.Name] = poolReservations{
+				ids:                 reservationIDs,
+				reservedDevicesOnly: ptr.Deref(workerConfig.ReservedDevicesOnly, false),
+			}
+		}
+
+		if len(reservationIDs) > 0 {
+			machineClassSpec["reservationIDs"] = reservationIDs
 		}
 
 		if workerConfig.ReservedDevicesOnly != nil {
 			machineClassSpec["reservedDevicesOnly"] = *workerConfig.ReservedDevicesOnly
 		}
 
-		var (
-			deploymentName = fmt.Sprintf("%s-%s", w.worker.Namespace, pool.Name)
-			className      = fmt.Sprintf("%s-%s", deploymentName, workerPoolHash)
-		)
+		var network *poolNetwork
+		if workerConfig.Network != nil {
+			vlanIDs, err := w.vlanIDs(ctx, credentials, workerConfig.Network.VLANs)
+			if err != nil {
+				return err
+			}
+			if network = newPoolNetwork(workerConfig.Network, vlanIDs); network != nil {
+				networks[pool.Name] = *network
+			}
+		}
 
-		updateConfiguration := machinev1alpha1.UpdateConfiguration{
-			MaxUnavailable: ptr.To(pool.MaxUnavailable),
-			MaxSurge:       ptr.To(pool.MaxSurge),
+		customData, err := machineCustomData(workerConfig, gardenerCustomData{Network: network, Volumes: volumeLayout})
+		if err != nil {
+			return fmt.Errorf("could not generate custom data of worker pool %q: %w", pool.Name, err)
+		}
+		if customData != "" {
+			machineClassSpec["customData"] = customData
 		}
 
-		machineDeploymentStrategy := machinev1alpha1.MachineDeploymentStrategy{
-			Type: machinev1alpha1.RollingUpdateMachineDeploymentStrategyType,
-			RollingUpdate: &machinev1alpha1.RollingUpdateMachineDeployment{
-				UpdateConfiguration: updateConfiguration,
-			},
+		if storage != nil {
+			machineClassSpec["storage"] = string(storage)
 		}
 
-		if gardencorev1beta1helper.IsUpdateStrategyInPlace(pool.UpdateStrategy) {
-			machineDeploymentStrategy = machinev1alpha1.MachineDeploymentStrategy{
-				Type: machinev1alpha1.InPlaceUpdateMachineDeploymentStrategyType,
-				InPlaceUpdate: &machinev1alpha1.InPlaceUpdateMachineDeployment{
-					UpdateConfiguration: updateConfiguration,
-					OrchestrationType:   machinev1alpha1.OrchestrationTypeAuto,
-				},
+		if ptr.Deref(workerConfig.SpotInstance, false) {
+			machineClassSpec["spotInstance"] = true
+			if workerConfig.SpotPriceMax != nil {
+				spotPriceMax, err := strconv.ParseFloat(*workerConfig.SpotPriceMax, 64)
+				if err != nil {
+					return fmt.Errorf("could not parse spot price of worker pool %q: %w", pool.Name, err)
+				}
+				machineClassSpec["spotPriceMax"] = spotPriceMax
 			}
+		}
 
-			if gardencorev1beta1helper.IsUpdateStrategyManualInPlace(pool.UpdateStrategy) {
-				machineDeploymentStrategy.InPlaceUpdate.OrchestrationType = machinev1alpha1.OrchestrationTypeManual
+		if workerConfig.IPXE != nil {
+			if err := w.addIPXEToMachineClassSpec(machineClassSpec, workerConfig.IPXE, pool); err != nil {
+				return fmt.Errorf("could not render iPXE configuration of worker pool %q: %w", pool.Name, err)
 			}
 		}
 
-		machineDeployments = append(machineDeployments, worker.MachineDeployment{
-			Name:                         deploymentName,
-			ClassName:                    className,
-			SecretName:                   className,
-			Minimum:                      pool.Minimum,
-			Maximum:                      pool.Maximum,
-			Strategy:                     machineDeploymentStrategy,
-			Priority:                     pool.Priority,
-			Labels:                       pool.Labels,
-			Annotations:                  pool.Annotations,
-			Taints:                       pool.Taints,
-			MachineConfiguration:         genericworkeractuator.ReadMachineConfiguration(pool),
-			ClusterAutoscalerAnnotations: extensionsv1alpha1helper.GetMachineDeploymentClusterAutoscalerAnnotations(pool.ClusterAutoscaler),
-		})
+		switch {
+		case workerConfig.IPXE != nil && workerConfig.IPXE.AlwaysPXE != nil:
+			alwaysPXE[pool.Name] = *workerConfig.IPXE.AlwaysPXE
+			workerPoolStatus.AlwaysPXE = *workerConfig.IPXE.AlwaysPXE
+		case previousAlwaysPXE.Has(pool.Name):
+			// the devices keep booting via iPXE until they have been reset, hence, the worker pool is reported until then
+			alwaysPXE[pool.Name] = false
+			workerPoolStatus.AlwaysPXE = true
+		}
 
-		machineClassSpec["name"] = className
 		machineClassSpec["labels"] = map[string]string{
 			v1beta1constants.GardenerPurpose: v1beta1constants.GardenPurposeMachineClass,
 		}
 
-		machineClasses = append(machineClasses, machineClassSpec)
+		labels, taints := pool.Labels, pool.Taints
+		if ptr.Deref(workerConfig.SpotInstance, false) {
+			labels = utils.MergeStringMaps(pool.Labels, map[string]string{equinixmetal.SpotInstanceLabel: "true"})
+			taints = append(append([]corev1.Taint{}, pool.Taints...), corev1.Taint{
+				Key:    equinixmetal.SpotInstanceLabel,
+				Value:  "true",
+				Effect: corev1.TaintEffectPreferNoSchedule,
+			})
+		}
+
+		zones := w.machineDeploymentZones(pool)
+
+		// the node templates describe the nodes of new machines, hence, they are derived from the selected machine type
+		templatePool := poolWithMachineType(pool, machineType, w.cluster.CloudProfile)
+
+		for zoneIndex, zone := range zones {
+			var (
+				zoneIdx   = int32(zoneIndex)
+				zoneCount = int32(len(zones))
+				classSpec = maps.Clone(machineClassSpec)
+			)
+
+			if len(zone.facilities) > 0 {
+				classSpec["facilities"] = zone.facilities
+			}
+
+			nodeTemplate, err := w.machineNodeTemplate(ctx, credentials, templatePool, *arch, zone.facilities)
+			if err != nil {
+				return fmt.Errorf("could not generate node template of worker pool %q: %w", pool.Name, err)
+			}
+
+			deploymentLabels := labels
+			if nodeTemplate != nil {
+				classSpec["nodeTemplate"] = nodeTemplate
+				deploymentLabels = utils.MergeStringMaps(labels, nodeTemplateLabels(ptr.Deref(workerConfig.SpotInstance, false), w.worker.Spec.Region))
+			}
+
+			updateConfiguration := machinev1alpha1.UpdateConfiguration{
+				MaxUnavailable: ptr.To(worker.DistributePositiveIntOrPercent(zoneIdx, pool.MaxUnavailable, zoneCount, pool.Minimum)),
+				MaxSurge:       ptr.To(worker.DistributePositiveIntOrPercent(zoneIdx, pool.MaxSurge, zoneCount, pool.Maximum)),
+			}
+
+			deployments := []zoneMachineDeployment{{
+				name:      zone.name,
+				classSpec: classSpec,
+				minimum:   worker.DistributeOverZones(zoneIdx, pool.Minimum, zoneCount),
+				maximum:   worker.DistributeOverZones(zoneIdx, pool.Maximum, zoneCount),
+				priority:  pool.Priority,
+			}}
+			if ptr.Deref(workerConfig.OnDemandOverflow, false) {
+				hardwareReservations, err := w.listHardwareReservations(ctx, credentials)
+				if err != nil {
+					return err
+				}
+				total, _ := countReservations(hardwareReservations, reservationIDs, zone.facilities)
+				deployments = splitOnDemandOverflow(deployments[0], total)
+			}
+
+			for _, deployment := range deployments {
+				className := fmt.Sprintf("%s-%s", deployment.name, workerPoolHash)
+				deployment.classSpec["name"] = className
+
+				strategy := machineDeploymentStrategy(pool, updateConfiguration)
+				if strategy.RollingUpdate != nil && deployment.classSpec["reservedDevicesOnly"] == true {
+					hardwareReservations, err := w.listHardwareReservations(ctx, credentials)
+					if err != nil {
+						return err
+					}
+					_, free := countReservations(hardwareReservations, reservationIDs, zone.facilities)
+
+					rollingUpdateConfiguration, updateStrategy, err := reservationAwareUpdateConfiguration(deployment.name, strategy.RollingUpdate.UpdateConfiguration, deployment.maximum, free)
+					if err != nil {
+						return fmt.Errorf("could not adjust update strategy of machine deployment %q: %w", deployment.name, err)
+					}
+					strategy.RollingUpdate.UpdateConfiguration = rollingUpdateConfiguration
+					workerPoolStatus.UpdateStrategies = append(workerPoolStatus.UpdateStrategies, updateStrategy)
+				}
+
+				machineDeployments = append(machineDeployments, worker.MachineDeployment{
+					Name:                         deployment.name,
+					PoolName:                     pool.Name,
+					ClassName:                    className,
+					SecretName:                   className,
+					Minimum:                      deployment.minimum,
+					Maximum:                      deployment.maximum,
+					Strategy:                     strategy,
+					Priority:                     deployment.priority,
+					Labels:                       deploymentLabels,
+					Annotations:                  pool.Annotations,
+					Taints:                       taints,
+					MachineConfiguration:         w.machineConfiguration(pool, machineType),
+					ClusterAutoscalerAnnotations: extensionsv1alpha1helper.GetMachineDeploymentClusterAutoscalerAnnotations(pool.ClusterAutoscaler),
+				})
+
+				machineClasses = append(machineClasses, deployment.classSpec)
+
+				if requiresOnDemandCapacity(deployment.classSpec) {
+					capacities = append(capacities, deploymentCapacity{
+						name:        deployment.name,
+						className:   className,
+						machineType: machineType,
+						facilities:  zone.facilities,
+					})
+				}
+
+				if workerConfig.AdoptDevices != nil {
+					adoptions = append(adoptions, adoptionTarget{
+						pool:                pool.Name,
+						name:                deployment.name,
+						className:           className,
+						classSpec:           deployment.classSpec,
+						userData:            string(userData),
+						machineTypes:        append([]string{pool.MachineType}, workerConfig.FallbackMachineTypes...),
+						facilities:          zone.facilities,
+						maximum:             deployment.maximum,
+						reservedDevicesOnly: deployment.classSpec["reservedDevicesOnly"] == true,
+						adoption:            workerConfig.AdoptDevices,
+					})
+				}
+			}
+		}
+
+		if _, ok := reservations[pool.Name]; ok || workerPoolStatus.MachineType != "" || workerPoolStatus.HibernationMode != "" || workerPoolStatus.AlwaysPXE {
+			workerPools = append(workerPools, workerPoolStatus)
+		}
 	}
 
 	w.machineDeployments = machineDeployments
 	w.machineClasses = machineClasses
 	w.machineImages = machineImages
+	w.workerPools = workerPools
+	w.poolReservations = reservations
+	w.poolNetworks = networks
+	w.poolAlwaysPXE = alwaysPXE
+	w.deploymentCapacities = capacities
+	w.adoptionTargets = adoptions
+
+	// the capacity is checked before the machine deployments are handed over, as the ones without capacity for new
+	// machines keep their previous machine class and replicas
+	return w.checkCapacity(ctx)
+}
+
+// machineConfiguration returns the machine configuration of the machine deployments of the given worker pool. The
+// creation, health and drain timeouts which are not configured for the worker pool default to the ones of the cloud
+// profile config for its plan and machine image, as provisioning some plans and operating systems takes considerably
+// longer than others.
+func (w *workerDelegate) machineConfiguration(pool extensionsv1alpha1.WorkerPool, machineType string) *machinev1alpha1.MachineConfiguration {
+	machineConfiguration := genericworkeractuator.ReadMachineConfiguration(pool)
+
+	timeouts := helper.MachineTimeouts(w.cloudProfileConfig, machineType, pool.MachineImage.Name)
+	if machineConfiguration.MachineCreationTimeout == nil {
+		machineConfiguration.MachineCreationTimeout = timeouts.CreationTimeout
+	}
+	if machineConfiguration.MachineHealthTimeout == nil {
+		machineConfiguration.MachineHealthTimeout = timeouts.HealthTimeout
+	}
+	if machineConfiguration.MachineDrainTimeout == nil {
+		machineConfiguration.MachineDrainTimeout = timeouts.DrainTimeout
+	}
+	return machineConfiguration
+}
+
+// machineDeploymentStrategy returns the strategy of the machine deployments of the given worker pool.
+func machineDeploymentStrategy(pool extensionsv1alpha1.WorkerPool, updateConfiguration machinev1alpha1.UpdateConfiguration) machinev1alpha1.MachineDeploymentStrategy {
+	if !gardencorev1beta1helper.IsUpdateStrategyInPlace(pool.UpdateStrategy) {
+		return machinev1alpha1.MachineDeploymentStrategy{
+			Type: machinev1alpha1.RollingUpdateMachineDeploymentStrategyType,
+			RollingUpdate: &machinev1alpha1.RollingUpdateMachineDeployment{
+				UpdateConfiguration: updateConfiguration,
+			},
+		}
+	}
+
+	strategy := machinev1alpha1.MachineDeploymentStrategy{
+		Type: machinev1alpha1.InPlaceUpdateMachineDeploymentStrategyType,
+		InPlaceUpdate: &machinev1alpha1.InPlaceUpdateMachineDeployment{
+			UpdateConfiguration: updateConfiguration,
+			OrchestrationType:   machinev1alpha1.OrchestrationTypeAuto,
+		},
+	}
+	if gardencorev1beta1helper.IsUpdateStrategyManualInPlace(pool.UpdateStrategy) {
+		strategy.InPlaceUpdate.OrchestrationType = machinev1alpha1.OrchestrationTypeManual
+	}
+	return strategy
+}
+
+// machineTags returns the tags of the devices of the given worker pool. Besides the default tags, it contains the
+// tags of the worker config and the tags derived from the configured pool labels.
+func machineTags(namespace string, workerConfig *api.WorkerConfig, pool extensionsv1alpha1.WorkerPool) []string {
+	tags := []string{
+		fmt.Sprintf("kubernetes.io/cluster/%s", namespace),
+		"kubernetes.io/role/node",
+	}
 
-	return nil
+	for _, tag := range workerConfig.Tags {
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	for _, key := range workerConfig.TagsFromPoolLabels {
+		value, ok := pool.Labels[key]
+		if !ok {
+			continue
+		}
+		if tag := fmt.Sprintf("%s=%s", key, value); !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// machineStorage returns the given storage layout in the format of the Equinix Metal API, or nil if no custom storage
+// layout is configured.
+func machineStorage(storage *api.Storage) ([]byte, error) {
+	if storage == nil {
+		return nil, nil
+	}
+
+	deviceStorage := metalv1.Storage{}
+	for _, disk := range storage.Disks {
+		deviceDisk := metalv1.Disk{Device: ptr.To(disk.Device), WipeTable: disk.WipeTable}
+		for _, partition := range disk.Partitions {
+			deviceDisk.Partitions = append(deviceDisk.Partitions, metalv1.Partition{
+				Label:  ptr.To(partition.Label),
+				Number: ptr.To(partition.Number),
+				Size:   ptr.To(partition.Size),
+			})
+		}
+		deviceStorage.Disks = append(deviceStorage.Disks, deviceDisk)
+	}
+	for _, raid := range storage.RAID {
+		deviceStorage.Raid = append(deviceStorage.Raid, metalv1.Raid{
+			Name:    ptr.To(raid.Name),
+			Level:   ptr.To(raid.Level),
+			Devices: raid.Devices,
+		})
+	}
+	for _, filesystem := range storage.Filesystems {
+		deviceStorage.Filesystems = append(deviceStorage.Filesystems, metalv1.Filesystem{Mount: &metalv1.Mount{
+			Device:  ptr.To(filesystem.Device),
+			Format:  ptr.To(filesystem.Format),
+			Point:   filesystem.Point,
+			Options: filesystem.Options,
+		}})
+	}
+
+	return json.Marshal(deviceStorage)
+}
+
+// gardenerCustomData is the data which is passed to the devices with the reserved key of the custom data, so that the
+// VLAN interfaces and the local volumes can be configured on the nodes.
+type gardenerCustomData struct {
+	Network *poolNetwork      `json:"network,omitempty"`
+	Volumes *poolVolumeLayout `json:"volumes,omitempty"`
+}
+
+// machineCustomData returns the custom data of the devices of a worker pool.
+func machineCustomData(workerConfig *api.WorkerConfig, gardenerData gardenerCustomData) (string, error) {
+	if gardenerData == (gardenerCustomData{}) {
+		if workerConfig.CustomData == nil {
+			return "", nil
+		}
+		return string(workerConfig.CustomData.Raw), nil
+	}
+
+	customData := map[string]interface{}{}
+	if workerConfig.CustomData != nil {
+		if err := json.Unmarshal(workerConfig.CustomData.Raw, &customData); err != nil {
+			return "", err
+		}
+	}
+	customData[api.CustomDataKeyGardener] = gardenerData
+
+	data, err := json.Marshal(customData)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
 }
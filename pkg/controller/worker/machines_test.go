@@ -12,6 +12,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/equinix/equinix-sdk-go/services/metalv1"
 	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
 	"github.com/gardener/gardener/extensions/pkg/controller/worker"
 	genericworkeractuator "github.com/gardener/gardener/extensions/pkg/controller/worker/genericactuator"
@@ -24,8 +25,10 @@ import (
 	machinev1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
 	"go.uber.org/mock/gomock"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
@@ -37,6 +40,8 @@ import (
 	apiv1alpha1 "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal/v1alpha1"
 	. "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/controller/worker"
 	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal"
+	eqxmclient "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal/client"
+	mockeqxmclient "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal/client/mock"
 )
 
 var (
@@ -72,7 +77,7 @@ var _ = Describe("Machines", func() {
 
 	Context("workerDelegate", func() {
 		BeforeEach(func() {
-			workerDelegate, _ = NewWorkerDelegate(nil, scheme, nil, "", nil, nil)
+			workerDelegate, _ = NewWorkerDelegate(nil, scheme, nil, nil, nil, "", nil, nil, nil)
 		})
 
 		Describe("#GenerateMachineDeployments, #DeployMachineClasses", func() {
@@ -89,6 +94,9 @@ var _ = Describe("Machines", func() {
 				machineImageName    string
 				machineImageVersion string
 				machineImage        string
+				machineImageARM64   string
+
+				ipxeMachineImageName string
 
 				machineType           string
 				sshKeyID              string
@@ -133,6 +141,9 @@ var _ = Describe("Machines", func() {
 				machineImageName = "my-os"
 				machineImageVersion = "123"
 				machineImage = "uuid"
+				machineImageARM64 = "uuid-arm64"
+
+				ipxeMachineImageName = "my-ipxe-os"
 
 				machineType = "large"
 				sshKeyID = "1-2-3-4"
@@ -180,6 +191,21 @@ var _ = Describe("Machines", func() {
 									Version: machineImageVersion,
 									ID:      machineImage,
 								},
+								{
+									Version:      machineImageVersion,
+									ID:           machineImageARM64,
+									Architecture: ptr.To(v1beta1constants.ArchitectureARM64),
+								},
+							},
+						},
+						{
+							Name: ipxeMachineImageName,
+							Versions: []apiv1alpha1.MachineImageVersion{
+								{
+									Version:       machineImageVersion,
+									ID:            "custom_ipxe",
+									IPXEScriptURL: "https://example.com/default.ipxe",
+								},
 							},
 						},
 					},
@@ -261,7 +287,7 @@ var _ = Describe("Machines", func() {
 				workerPoolHash2, _ = worker.WorkerPoolHash(w.Spec.Pools[1],
 					cluster, nil, nil, nil)
 
-				workerDelegate, _ = NewWorkerDelegate(c, scheme, chartApplier, "", w, clusterWithoutImages)
+				workerDelegate, _ = NewWorkerDelegate(c, scheme, nil, nil, chartApplier, "", w, clusterWithoutImages, nil)
 			})
 
 			expectGetUserDataSecretCallToWork := func() {
@@ -299,14 +325,17 @@ var _ = Describe("Machines", func() {
 					}
 
 					var (
-						machineClassPool1 = copyMachineClass(defaultMachineClass)
-						machineClassPool2 = copyMachineClass(defaultMachineClass)
+						machineClassPool1Zone1 = copyMachineClass(defaultMachineClass)
+						machineClassPool1Zone2 = copyMachineClass(defaultMachineClass)
+						machineClassPool2      = copyMachineClass(defaultMachineClass)
 
-						machineClassNamePool1 = fmt.Sprintf("%s-%s", namespace, namePool1)
-						machineClassNamePool2 = fmt.Sprintf("%s-%s", namespace, namePool2)
+						machineClassNamePool1Zone1 = fmt.Sprintf("%s-%s-z1", namespace, namePool1)
+						machineClassNamePool1Zone2 = fmt.Sprintf("%s-%s-z2", namespace, namePool1)
+						machineClassNamePool2      = fmt.Sprintf("%s-%s", namespace, namePool2)
 
-						machineClassWithHashPool1 = fmt.Sprintf("%s-%s", machineClassNamePool1, workerPoolHash1)
-						machineClassWithHashPool2 = fmt.Sprintf("%s-%s", machineClassNamePool2, workerPoolHash2)
+						machineClassWithHashPool1Zone1 = fmt.Sprintf("%s-%s", machineClassNamePool1Zone1, workerPoolHash1)
+						machineClassWithHashPool1Zone2 = fmt.Sprintf("%s-%s", machineClassNamePool1Zone2, workerPoolHash1)
+						machineClassWithHashPool2      = fmt.Sprintf("%s-%s", machineClassNamePool2, workerPoolHash2)
 					)
 
 					emptyClusterAutoscalerAnnotations := map[string]string{
@@ -317,29 +346,52 @@ var _ = Describe("Machines", func() {
 						"autoscaler.gardener.cloud/scale-down-utilization-threshold":     "",
 					}
 
-					addNameAndSecretToMachineClass(machineClassPool1, machineClassWithHashPool1, w.Spec.SecretRef)
+					addNameAndSecretToMachineClass(machineClassPool1Zone1, machineClassWithHashPool1Zone1, w.Spec.SecretRef)
+					addNameAndSecretToMachineClass(machineClassPool1Zone2, machineClassWithHashPool1Zone2, w.Spec.SecretRef)
 					addNameAndSecretToMachineClass(machineClassPool2, machineClassWithHashPool2, w.Spec.SecretRef)
 
-					machineClassPool1["facilities"] = []string{facility1, facility2}
+					machineClassPool1Zone1["facilities"] = []string{facility1}
+					machineClassPool1Zone2["facilities"] = []string{facility2}
 
 					machineClasses = map[string]interface{}{"machineClasses": []map[string]interface{}{
-						machineClassPool1,
+						machineClassPool1Zone1,
+						machineClassPool1Zone2,
 						machineClassPool2,
 					}}
 
 					machineDeployments = worker.MachineDeployments{
 						{
-							Name:       machineClassNamePool1,
-							ClassName:  machineClassWithHashPool1,
-							SecretName: machineClassWithHashPool1,
-							Minimum:    minPool1,
-							Maximum:    maxPool1,
+							Name:       machineClassNamePool1Zone1,
+							PoolName:   namePool1,
+							ClassName:  machineClassWithHashPool1Zone1,
+							SecretName: machineClassWithHashPool1Zone1,
+							Minimum:    worker.DistributeOverZones(0, minPool1, 2),
+							Maximum:    worker.DistributeOverZones(0, maxPool1, 2),
+							Strategy: machinev1alpha1.MachineDeploymentStrategy{
+								Type: machinev1alpha1.RollingUpdateMachineDeploymentStrategyType,
+								RollingUpdate: &machinev1alpha1.RollingUpdateMachineDeployment{
+									UpdateConfiguration: machinev1alpha1.UpdateConfiguration{
+										MaxUnavailable: ptr.To(worker.DistributePositiveIntOrPercent(0, maxUnavailablePool1, 2, minPool1)),
+										MaxSurge:       ptr.To(worker.DistributePositiveIntOrPercent(0, maxSurgePool1, 2, maxPool1)),
+									},
+								},
+							},
+							MachineConfiguration:         machineConfiguration,
+							ClusterAutoscalerAnnotations: emptyClusterAutoscalerAnnotations,
+						},
+						{
+							Name:       machineClassNamePool1Zone2,
+							PoolName:   namePool1,
+							ClassName:  machineClassWithHashPool1Zone2,
+							SecretName: machineClassWithHashPool1Zone2,
+							Minimum:    worker.DistributeOverZones(1, minPool1, 2),
+							Maximum:    worker.DistributeOverZones(1, maxPool1, 2),
 							Strategy: machinev1alpha1.MachineDeploymentStrategy{
 								Type: machinev1alpha1.RollingUpdateMachineDeploymentStrategyType,
 								RollingUpdate: &machinev1alpha1.RollingUpdateMachineDeployment{
 									UpdateConfiguration: machinev1alpha1.UpdateConfiguration{
-										MaxUnavailable: ptr.To(maxUnavailablePool1),
-										MaxSurge:       ptr.To(maxSurgePool1),
+										MaxUnavailable: ptr.To(worker.DistributePositiveIntOrPercent(1, maxUnavailablePool1, 2, minPool1)),
+										MaxSurge:       ptr.To(worker.DistributePositiveIntOrPercent(1, maxSurgePool1, 2, maxPool1)),
 									},
 								},
 							},
@@ -348,6 +400,7 @@ var _ = Describe("Machines", func() {
 						},
 						{
 							Name:       machineClassNamePool2,
+							PoolName:   namePool2,
 							ClassName:  machineClassWithHashPool2,
 							SecretName: machineClassWithHashPool2,
 							Minimum:    minPool2,
@@ -368,7 +421,7 @@ var _ = Describe("Machines", func() {
 				})
 
 				It("should return the expected machine deployments for profile image types", func() {
-					workerDelegate, _ := NewWorkerDelegate(c, scheme, chartApplier, "", w, cluster)
+					workerDelegate, _ := NewWorkerDelegate(c, scheme, nil, nil, chartApplier, "", w, cluster, nil)
 
 					expectGetSecretCallToWork(c, apiToken, projectID)
 					expectGetUserDataSecretCallToWork()
@@ -391,9 +444,10 @@ var _ = Describe("Machines", func() {
 					// Test workerDelegate.UpdateMachineImagesStatus()
 					expectStatusContainsMachineImages(ctx, c, statusWriter, w, []apiv1alpha1.MachineImage{
 						{
-							Name:    machineImageName,
-							Version: machineImageVersion,
-							ID:      machineImage,
+							Name:         machineImageName,
+							Version:      machineImageVersion,
+							ID:           machineImage,
+							Architecture: ptr.To(v1beta1constants.ArchitectureAMD64),
 						},
 					})
 					err = workerDelegate.UpdateMachineImagesStatus(ctx)
@@ -405,6 +459,46 @@ var _ = Describe("Machines", func() {
 					Expect(result).To(Equal(machineDeployments))
 				})
 
+				Context("machine deployments for all zones", func() {
+					var legacyName string
+
+					BeforeEach(func() {
+						legacyName = fmt.Sprintf("%s-%s", namespace, namePool1)
+						w.Status.MachineDeployments = []extensionsv1alpha1.MachineDeployment{{Name: legacyName}}
+					})
+
+					It("should replace the machine deployment for all zones with a machine deployment per zone", func() {
+						expectGetSecretCallToWork(c, apiToken, projectID)
+						expectGetUserDataSecretCallToWork()
+
+						workerDelegate, _ := NewWorkerDelegate(c, scheme, nil, nil, chartApplier, "", w, cluster, nil)
+
+						result, err := workerDelegate.GenerateMachineDeployments(ctx)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(result).To(Equal(machineDeployments))
+					})
+
+					It("should keep the machine deployment for all zones while the shoot is hibernated", func() {
+						cluster.Shoot = cluster.Shoot.DeepCopy()
+						cluster.Shoot.Spec.Hibernation = &gardencorev1beta1.Hibernation{Enabled: ptr.To(true)}
+
+						expectGetSecretCallToWork(c, apiToken, projectID)
+						expectGetUserDataSecretCallToWork()
+
+						workerDelegate, _ := NewWorkerDelegate(c, scheme, nil, nil, chartApplier, "", w, cluster, nil)
+
+						result, err := workerDelegate.GenerateMachineDeployments(ctx)
+						Expect(err).NotTo(HaveOccurred())
+						Expect(result).To(HaveLen(2))
+						Expect(result[0]).To(MatchFields(IgnoreExtras, Fields{
+							"Name":      Equal(legacyName),
+							"ClassName": Equal(fmt.Sprintf("%s-%s", legacyName, workerPoolHash1)),
+							"Minimum":   Equal(minPool1),
+							"Maximum":   Equal(maxPool1),
+						}))
+					})
+				})
+
 				It("should deploy the correct machine class when using values for reserved devices", func() {
 					var (
 						reservationIDs      = []string{"foo", "bar"}
@@ -424,14 +518,23 @@ var _ = Describe("Machines", func() {
 						machineClassWithHashPool2 = fmt.Sprintf("%s-%s", machineClassNamePool2, newHash)
 					)
 
-					machineClasses["machineClasses"].([]map[string]interface{})[1]["name"] = machineClassWithHashPool2
-					machineClasses["machineClasses"].([]map[string]interface{})[1]["reservationIDs"] = reservationIDs
-					machineClasses["machineClasses"].([]map[string]interface{})[1]["reservedDevicesOnly"] = reservedDevicesOnly
+					machineClasses["machineClasses"].([]map[string]interface{})[2]["name"] = machineClassWithHashPool2
+					machineClasses["machineClasses"].([]map[string]interface{})[2]["reservationIDs"] = reservationIDs
+					machineClasses["machineClasses"].([]map[string]interface{})[2]["reservedDevicesOnly"] = reservedDevicesOnly
+
+					equinixClient := mockeqxmclient.NewMockClientInterface(ctrl)
+					equinixClient.EXPECT().ListHardwareReservations(ctx, projectID).Return([]metalv1.HardwareReservation{
+						{Id: ptr.To("foo"), Spare: ptr.To(false), Facility: &metalv1.Facility{Code: ptr.To(facility1)}},
+						{Id: ptr.To("bar"), Spare: ptr.To(false), Facility: &metalv1.Facility{Code: ptr.To(facility1)}, Device: &metalv1.Device{Id: ptr.To("device-1")}},
+					}, nil)
+					newClient := func(_ string) (eqxmclient.ClientInterface, error) {
+						return equinixClient, nil
+					}
 
 					expectGetSecretCallToWork(c, apiToken, projectID)
 					expectGetUserDataSecretCallToWork()
 
-					workerDelegate, _ := NewWorkerDelegate(c, scheme, chartApplier, "", w, cluster)
+					workerDelegate, _ := NewWorkerDelegate(c, scheme, newClient, nil, chartApplier, "", w, cluster, nil)
 
 					chartApplier.
 						EXPECT().
@@ -445,6 +548,899 @@ var _ = Describe("Machines", func() {
 						)
 
 					Expect(workerDelegate.DeployMachineClasses(context.TODO())).NotTo(HaveOccurred())
+
+					result, err := workerDelegate.GenerateMachineDeployments(ctx)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result[2].Strategy.RollingUpdate.MaxSurge).To(PointTo(Equal(intstr.FromInt32(1))))
+					Expect(result[2].Strategy.RollingUpdate.MaxUnavailable).To(PointTo(Equal(intstr.FromInt32(15))))
+
+					expectStatus(ctx, c, statusWriter, w, &apiv1alpha1.WorkerStatus{
+						MachineImages: []apiv1alpha1.MachineImage{
+							{
+								Name:         machineImageName,
+								Version:      machineImageVersion,
+								ID:           machineImage,
+								Architecture: ptr.To(v1beta1constants.ArchitectureAMD64),
+							},
+						},
+						WorkerPools: []apiv1alpha1.WorkerPoolStatus{
+							{
+								Name: namePool2,
+								UpdateStrategies: []apiv1alpha1.UpdateStrategyStatus{
+									{
+										Name:             fmt.Sprintf("%s-%s", namespace, namePool2),
+										Type:             "RollingUpdate",
+										FreeReservations: 1,
+										MaxSurge:         1,
+										MaxUnavailable:   15,
+									},
+								},
+							},
+						},
+					})
+					Expect(workerDelegate.UpdateMachineImagesStatus(ctx)).To(Succeed())
+				})
+
+				It("should deploy the correct machine class when selecting hardware reservations", func() {
+					w.Spec.Pools[1].ProviderConfig = &runtime.RawExtension{Raw: encode(&api.WorkerConfig{
+						ReservationIDs: []string{"foo"},
+						ReservationSelector: &api.ReservationSelector{
+							Metros: []string{region},
+							Tags:   []string{"team-a"},
+						},
+					})}
+
+					newHash, err := worker.WorkerPoolHash(w.Spec.Pools[1], cluster, []string{}, []string{}, []string{})
+					Expect(err).NotTo(HaveOccurred())
+
+					machineClass := machineClasses["machineClasses"].([]map[string]interface{})[2]
+					machineClass["name"] = fmt.Sprintf("%s-%s-%s", namespace, namePool2, newHash)
+					machineClass["reservationIDs"] = []string{"foo", "reservation-1", "reservation-2"}
+
+					newReservation := func(id, plan, metro string, spare bool, tags ...interface{}) metalv1.HardwareReservation {
+						return metalv1.HardwareReservation{
+							Id:                   ptr.To(id),
+							Plan:                 &metalv1.Plan{Slug: ptr.To(plan)},
+							Facility:             &metalv1.Facility{Code: ptr.To(facility1), Metro: &metalv1.DeviceMetro{Code: ptr.To(metro)}},
+							Spare:                ptr.To(spare),
+							AdditionalProperties: map[string]interface{}{"tags": tags},
+						}
+					}
+
+					equinixClient := mockeqxmclient.NewMockClientInterface(ctrl)
+					equinixClient.EXPECT().ListHardwareReservations(ctx, projectID).Return([]metalv1.HardwareReservation{
+						newReservation("reservation-2", machineType, region, false, "team-a", "team-b"),
+						newReservation("reservation-1", machineType, region, false, "team-a"),
+						newReservation("spare", machineType, region, true, "team-a"),
+						newReservation("other-plan", "other", region, false, "team-a"),
+						newReservation("other-metro", machineType, "da", false, "team-a"),
+						newReservation("other-tag", machineType, region, false, "team-b"),
+					}, nil)
+					newClient := func(apiKey string) (eqxmclient.ClientInterface, error) {
+						Expect(apiKey).To(Equal(apiToken))
+						return equinixClient, nil
+					}
+
+					expectGetSecretCallToWork(c, apiToken, projectID)
+					expectGetUserDataSecretCallToWork()
+
+					workerDelegate, _ := NewWorkerDelegate(c, scheme, newClient, nil, chartApplier, "", w, cluster, nil)
+
+					chartApplier.
+						EXPECT().
+						ApplyFromEmbeddedFS(
+							ctx,
+							charts.InternalChart,
+							filepath.Join(charts.InternalChartsPath, "machineclass"),
+							namespace,
+							"machineclass",
+							kubernetes.Values(machineClasses),
+						)
+
+					Expect(workerDelegate.DeployMachineClasses(ctx)).To(Succeed())
+
+					expectStatus(ctx, c, statusWriter, w, &apiv1alpha1.WorkerStatus{
+						MachineImages: []apiv1alpha1.MachineImage{
+							{
+								Name:         machineImageName,
+								Version:      machineImageVersion,
+								ID:           machineImage,
+								Architecture: ptr.To(v1beta1constants.ArchitectureAMD64),
+							},
+						},
+						WorkerPools: []apiv1alpha1.WorkerPoolStatus{
+							{
+								Name:           namePool2,
+								ReservationIDs: []string{"reservation-1", "reservation-2"},
+							},
+						},
+					})
+					Expect(workerDelegate.UpdateMachineImagesStatus(ctx)).To(Succeed())
+				})
+
+				It("should split a worker pool with hardware reservations into a reserved and an on-demand overflow machine deployment", func() {
+					w.Spec.Pools[1].ProviderConfig = &runtime.RawExtension{Raw: encode(&api.WorkerConfig{
+						ReservationIDs:   []string{"reservation-1", "reservation-2"},
+						OnDemandOverflow: ptr.To(true),
+					})}
+
+					equinixClient := mockeqxmclient.NewMockClientInterface(ctrl)
+					equinixClient.EXPECT().ListHardwareReservations(ctx, projectID).Return([]metalv1.HardwareReservation{
+						{Id: ptr.To("reservation-1"), Spare: ptr.To(false), Facility: &metalv1.Facility{Code: ptr.To(facility1)}},
+						{Id: ptr.To("reservation-2"), Spare: ptr.To(false), Facility: &metalv1.Facility{Code: ptr.To(facility1)}},
+					}, nil)
+					newClient := func(_ string) (eqxmclient.ClientInterface, error) {
+						return equinixClient, nil
+					}
+
+					expectGetSecretCallToWork(c, apiToken, projectID)
+					expectGetUserDataSecretCallToWork()
+
+					workerDelegate, _ := NewWorkerDelegate(c, scheme, newClient, nil, chartApplier, "", w, cluster, nil)
+
+					result, err := workerDelegate.GenerateMachineDeployments(ctx)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(HaveLen(4))
+
+					var (
+						reservedName = fmt.Sprintf("%s-%s", namespace, namePool2)
+						overflowName = reservedName + "-od"
+					)
+					Expect(result[2]).To(MatchFields(IgnoreExtras, Fields{
+						"Name":     Equal(reservedName),
+						"Minimum":  Equal(int32(2)),
+						"Maximum":  Equal(int32(2)),
+						"Priority": BeNil(),
+					}))
+					Expect(result[3]).To(MatchFields(IgnoreExtras, Fields{
+						"Name":     Equal(overflowName),
+						"Minimum":  Equal(minPool2 - 2),
+						"Maximum":  Equal(maxPool2 - 2),
+						"Priority": PointTo(Equal(int32(-1))),
+					}))
+					Expect(result[2].ClassName).To(HavePrefix(reservedName + "-"))
+					Expect(result[3].ClassName).To(HavePrefix(overflowName + "-"))
+				})
+
+				It("should deploy the correct machine class when attaching VLANs", func() {
+					w.Spec.Pools[1].ProviderConfig = &runtime.RawExtension{Raw: encode(&api.WorkerConfig{
+						CustomData: &runtime.RawExtension{Raw: []byte(`{"foo":"bar"}`)},
+						Network: &api.WorkerNetwork{
+							Type: ptr.To("hybrid"),
+							VLANs: []api.VLAN{
+								{Name: "existing", ID: ptr.To[int32](42)},
+								{Name: "managed"},
+								{Name: "new"},
+							},
+						},
+					})}
+
+					newHash, err := worker.WorkerPoolHash(w.Spec.Pools[1], cluster, []string{}, []string{`{"foo":"bar"}`, "hybrid", "existing", "managed", "new"}, []string{})
+					Expect(err).NotTo(HaveOccurred())
+
+					machineClass := machineClasses["machineClasses"].([]map[string]interface{})[2]
+					machineClass["name"] = fmt.Sprintf("%s-%s-%s", namespace, namePool2, newHash)
+					machineClass["customData"] = `{"foo":"bar","gardener":{"network":{"type":"hybrid","vlans":[42,1001,1002]}}}`
+
+					clusterTag := fmt.Sprintf("kubernetes.io/cluster/%s", namespace)
+					equinixClient := mockeqxmclient.NewMockClientInterface(ctrl)
+					equinixClient.EXPECT().ListVLANs(ctx, projectID, region).Return([]metalv1.VirtualNetwork{
+						{Description: ptr.To(namespace + "-managed"), Vxlan: ptr.To[int32](1001), Tags: []string{clusterTag}},
+						{Description: ptr.To(namespace + "-new"), Vxlan: ptr.To[int32](1003)},
+						{Description: ptr.To(namespace + "-new"), Vxlan: ptr.To[int32](1002), Tags: []string{clusterTag}},
+					}, nil)
+					newClient := func(_ string) (eqxmclient.ClientInterface, error) {
+						return equinixClient, nil
+					}
+
+					expectGetSecretCallToWork(c, apiToken, projectID)
+					expectGetUserDataSecretCallToWork()
+
+					workerDelegate, _ := NewWorkerDelegate(c, scheme, newClient, nil, chartApplier, "", w, cluster, nil)
+
+					chartApplier.
+						EXPECT().
+						ApplyFromEmbeddedFS(
+							ctx,
+							charts.InternalChart,
+							filepath.Join(charts.InternalChartsPath, "machineclass"),
+							namespace,
+							"machineclass",
+							kubernetes.Values(machineClasses),
+						)
+
+					Expect(workerDelegate.DeployMachineClasses(ctx)).To(Succeed())
+				})
+
+				It("should deploy the correct machine class when overriding the iPXE configuration", func() {
+					w.Spec.Pools[1].MachineImage.Name = ipxeMachineImageName
+					w.Spec.Pools[1].ProviderConfig = &runtime.RawExtension{Raw: encode(&api.WorkerConfig{
+						IPXE: &api.IPXE{
+							ScriptURL:  ptr.To("https://example.com/{{ .Pool }}.ipxe?metro={{ .Metro }}&args={{ .KernelArgs }}"),
+							KernelArgs: []string{"foo=bar", "baz"},
+							AlwaysPXE:  ptr.To(true),
+						},
+					})}
+
+					newHash, err := worker.WorkerPoolHash(w.Spec.Pools[1], cluster, []string{}, []string{}, []string{})
+					Expect(err).NotTo(HaveOccurred())
+
+					machineClass := machineClasses["machineClasses"].([]map[string]interface{})[2]
+					machineClass["name"] = fmt.Sprintf("%s-%s-%s", namespace, namePool2, newHash)
+					machineClass["OS"] = "custom_ipxe"
+					machineClass["ipxeScriptUrl"] = fmt.Sprintf("https://example.com/%s.ipxe?metro=%s&args=foo%%3Dbar%%20baz", namePool2, region)
+
+					expectGetSecretCallToWork(c, apiToken, projectID)
+					expectGetUserDataSecretCallToWork()
+
+					workerDelegate, _ := NewWorkerDelegate(c, scheme, nil, nil, chartApplier, "", w, cluster, nil)
+
+					chartApplier.
+						EXPECT().
+						ApplyFromEmbeddedFS(
+							ctx,
+							charts.InternalChart,
+							filepath.Join(charts.InternalChartsPath, "machineclass"),
+							namespace,
+							"machineclass",
+							kubernetes.Values(machineClasses),
+						)
+
+					Expect(workerDelegate.DeployMachineClasses(ctx)).To(Succeed())
+				})
+
+				It("should deploy the correct machine class when using an inline iPXE script", func() {
+					w.Spec.Pools[1].MachineImage.Name = ipxeMachineImageName
+					w.Spec.Pools[1].ProviderConfig = &runtime.RawExtension{Raw: encode(&api.WorkerConfig{
+						IPXE: &api.IPXE{
+							Script:     ptr.To("#!ipxe\nkernel https://example.com/{{ .Metro }}/vmlinuz {{ .KernelArgs }}\nboot\n"),
+							KernelArgs: []string{"foo=bar", "baz"},
+						},
+					})}
+
+					newHash, err := worker.WorkerPoolHash(w.Spec.Pools[1], cluster, []string{}, []string{}, []string{})
+					Expect(err).NotTo(HaveOccurred())
+
+					machineClass := machineClasses["machineClasses"].([]map[string]interface{})[2]
+					machineClass["name"] = fmt.Sprintf("%s-%s-%s", namespace, namePool2, newHash)
+					machineClass["OS"] = "custom_ipxe"
+					machineClass["ipxeScriptUrl"] = ""
+					machineClass["ipxeScript"] = fmt.Sprintf("#!ipxe\nkernel https://example.com/%s/vmlinuz foo=bar baz\nboot\n", region)
+
+					expectGetSecretCallToWork(c, apiToken, projectID)
+					expectGetUserDataSecretCallToWork()
+
+					workerDelegate, _ := NewWorkerDelegate(c, scheme, nil, nil, chartApplier, "", w, cluster, nil)
+
+					chartApplier.
+						EXPECT().
+						ApplyFromEmbeddedFS(
+							ctx,
+							charts.InternalChart,
+							filepath.Join(charts.InternalChartsPath, "machineclass"),
+							namespace,
+							"machineclass",
+							kubernetes.Values(machineClasses),
+						)
+
+					Expect(workerDelegate.DeployMachineClasses(ctx)).To(Succeed())
+				})
+
+				It("should fail when overriding the iPXE configuration of a machine image not using custom iPXE", func() {
+					w.Spec.Pools[1].ProviderConfig = &runtime.RawExtension{Raw: encode(&api.WorkerConfig{
+						IPXE: &api.IPXE{
+							ScriptURL: ptr.To("https://example.com/boot.ipxe"),
+						},
+					})}
+
+					expectGetSecretCallToWork(c, apiToken, projectID)
+					expectGetUserDataSecretCallToWork()
+
+					workerDelegate, _ := NewWorkerDelegate(c, scheme, nil, nil, chartApplier, "", w, cluster, nil)
+
+					Expect(workerDelegate.DeployMachineClasses(ctx)).To(MatchError(ContainSubstring("spec.pools[1].providerConfig.ipxe")))
+				})
+
+				It("should deploy the correct machine class and deployment for spot instances", func() {
+					w.Spec.Pools[1].Labels = map[string]string{"foo": "bar"}
+					w.Spec.Pools[1].ProviderConfig = &runtime.RawExtension{Raw: encode(&api.WorkerConfig{
+						SpotInstance: ptr.To(true),
+						SpotPriceMax: ptr.To("0.5"),
+					})}
+
+					newHash, err := worker.WorkerPoolHash(w.Spec.Pools[1], cluster, []string{}, []string{}, []string{})
+					Expect(err).NotTo(HaveOccurred())
+
+					machineClass := machineClasses["machineClasses"].([]map[string]interface{})[2]
+					machineClass["name"] = fmt.Sprintf("%s-%s-%s", namespace, namePool2, newHash)
+					machineClass["spotInstance"] = true
+					machineClass["spotPriceMax"] = 0.5
+
+					expectGetSecretCallToWork(c, apiToken, projectID)
+					expectGetUserDataSecretCallToWork()
+
+					workerDelegate, _ := NewWorkerDelegate(c, scheme, nil, nil, chartApplier, "", w, cluster, nil)
+
+					chartApplier.
+						EXPECT().
+						ApplyFromEmbeddedFS(
+							ctx,
+							charts.InternalChart,
+							filepath.Join(charts.InternalChartsPath, "machineclass"),
+							namespace,
+							"machineclass",
+							kubernetes.Values(machineClasses),
+						)
+
+					Expect(workerDelegate.DeployMachineClasses(ctx)).To(Succeed())
+
+					result, err := workerDelegate.GenerateMachineDeployments(ctx)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result[2].Labels).To(Equal(map[string]string{"foo": "bar", "metal.equinix.com/spot-instance": "true"}))
+					Expect(result[2].Taints).To(ConsistOf(corev1.Taint{
+						Key:    "metal.equinix.com/spot-instance",
+						Value:  "true",
+						Effect: corev1.TaintEffectPreferNoSchedule,
+					}))
+					Expect(result[0].Labels).To(BeNil())
+					Expect(result[0].Taints).To(BeNil())
+				})
+
+				It("should deploy the correct machine class and deployment with the node template of the cloud profile", func() {
+					w.Spec.Pools[1].Zones = []string{facility1}
+					w.Spec.Pools[1].NodeTemplate = &extensionsv1alpha1.NodeTemplate{
+						Capacity: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("16"),
+							corev1.ResourceMemory: resource.MustParse("64Gi"),
+						},
+					}
+
+					newHash, err := worker.WorkerPoolHash(w.Spec.Pools[1], cluster, []string{}, []string{}, []string{})
+					Expect(err).NotTo(HaveOccurred())
+
+					machineClass := machineClasses["machineClasses"].([]map[string]interface{})[2]
+					machineClass["name"] = fmt.Sprintf("%s-%s-z1-%s", namespace, namePool2, newHash)
+					machineClass["facilities"] = []string{facility1}
+					machineClass["nodeTemplate"] = &machinev1alpha1.NodeTemplate{
+						Capacity: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("16"),
+							corev1.ResourceMemory: resource.MustParse("64Gi"),
+						},
+						InstanceType: machineType,
+						Region:       region,
+						Zone:         facility1,
+						Architecture: ptr.To(v1beta1constants.ArchitectureAMD64),
+					}
+
+					expectGetSecretCallToWork(c, apiToken, projectID)
+					expectGetUserDataSecretCallToWork()
+
+					workerDelegate, _ := NewWorkerDelegate(c, scheme, nil, nil, chartApplier, "", w, cluster, nil)
+
+					chartApplier.
+						EXPECT().
+						ApplyFromEmbeddedFS(
+							ctx,
+							charts.InternalChart,
+							filepath.Join(charts.InternalChartsPath, "machineclass"),
+							namespace,
+							"machineclass",
+							kubernetes.Values(machineClasses),
+						)
+
+					Expect(workerDelegate.DeployMachineClasses(ctx)).To(Succeed())
+
+					result, err := workerDelegate.GenerateMachineDeployments(ctx)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result[2].Labels).To(Equal(map[string]string{
+						"metal.equinix.com/metro":         region,
+						"metal.equinix.com/capacity-type": "on-demand",
+					}))
+					Expect(result[0].Labels).To(BeNil())
+				})
+
+				It("should deploy the correct machine class with the node template of the plan for pools scaling from zero", func() {
+					w.Spec.Pools[1].Minimum = 0
+					w.Spec.Pools[1].ProviderConfig = &runtime.RawExtension{Raw: encode(&api.WorkerConfig{
+						SpotInstance: ptr.To(true),
+					})}
+
+					newHash, err := worker.WorkerPoolHash(w.Spec.Pools[1], cluster, []string{}, []string{}, []string{})
+					Expect(err).NotTo(HaveOccurred())
+
+					machineClass := machineClasses["machineClasses"].([]map[string]interface{})[2]
+					machineClass["name"] = fmt.Sprintf("%s-%s-%s", namespace, namePool2, newHash)
+					machineClass["spotInstance"] = true
+					machineClass["nodeTemplate"] = &machinev1alpha1.NodeTemplate{
+						Capacity: corev1.ResourceList{
+							corev1.ResourceCPU:              *resource.NewQuantity(48, resource.DecimalSI),
+							corev1.ResourceMemory:           resource.MustParse("256G"),
+							corev1.ResourceEphemeralStorage: resource.MustParse("240G"),
+							"nvidia.com/gpu":                *resource.NewQuantity(2, resource.DecimalSI),
+						},
+						InstanceType: machineType,
+						Region:       region,
+						Architecture: ptr.To(v1beta1constants.ArchitectureAMD64),
+					}
+
+					equinixClient := mockeqxmclient.NewMockClientInterface(ctrl)
+					equinixClient.EXPECT().ListPlans(ctx, projectID).Return([]metalv1.Plan{
+						{Slug: ptr.To("other")},
+						{
+							Slug: ptr.To(machineType),
+							Specs: &metalv1.PlanSpecs{
+								Cpus:   []metalv1.PlanSpecsCpusInner{{Count: ptr.To[int32](2), Type: ptr.To("Intel Xeon Gold 6338 24-Core Processor @ 2.0GHz")}},
+								Memory: &metalv1.PlanSpecsMemory{Total: ptr.To("256GB")},
+								Drives: []metalv1.PlanSpecsDrivesInner{
+									{Count: ptr.To[int32](2), Size: ptr.To("3.8TB"), Category: ptr.To("storage")},
+									{Count: ptr.To[int32](2), Size: ptr.To("240GB"), Category: ptr.To("boot")},
+								},
+								AdditionalProperties: map[string]interface{}{
+									"gpu": []interface{}{map[string]interface{}{"count": float64(2), "type": "NVIDIA A100"}},
+								},
+							},
+						},
+					}, nil)
+					newClient := func(apiKey string) (eqxmclient.ClientInterface, error) {
+						Expect(apiKey).To(Equal(apiToken))
+						return equinixClient, nil
+					}
+
+					expectGetSecretCallToWork(c, apiToken, projectID)
+					expectGetUserDataSecretCallToWork()
+
+					workerDelegate, _ := NewWorkerDelegate(c, scheme, newClient, nil, chartApplier, "", w, cluster, nil)
+
+					chartApplier.
+						EXPECT().
+						ApplyFromEmbeddedFS(
+							ctx,
+							charts.InternalChart,
+							filepath.Join(charts.InternalChartsPath, "machineclass"),
+							namespace,
+							"machineclass",
+							kubernetes.Values(machineClasses),
+						)
+
+					Expect(workerDelegate.DeployMachineClasses(ctx)).To(Succeed())
+
+					result, err := workerDelegate.GenerateMachineDeployments(ctx)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(result[2].Labels).To(Equal(map[string]string{
+						"metal.equinix.com/spot-instance": "true",
+						"metal.equinix.com/metro":         region,
+						"metal.equinix.com/capacity-type": "spot",
+					}))
+				})
+
+				It("should fail when the plan of a pool scaling from zero does not exist", func() {
+					w.Spec.Pools[1].Minimum = 0
+
+					equinixClient := mockeqxmclient.NewMockClientInterface(ctrl)
+					equinixClient.EXPECT().ListPlans(ctx, projectID).Return([]metalv1.Plan{{Slug: ptr.To("other")}}, nil)
+					newClient := func(_ string) (eqxmclient.ClientInterface, error) {
+						return equinixClient, nil
+					}
+
+					expectGetSecretCallToWork(c, apiToken, projectID)
+					expectGetUserDataSecretCallToWork()
+
+					workerDelegate, _ := NewWorkerDelegate(c, scheme, newClient, nil, chartApplier, "", w, cluster, nil)
+
+					_, err := workerDelegate.GenerateMachineDeployments(ctx)
+					Expect(err).To(MatchError(ContainSubstring(fmt.Sprintf("plan %q not found", machineType))))
+				})
+
+				Context("additional SSH keys", func() {
+					BeforeEach(func() {
+						cluster.Shoot.Spec.Provider.Workers = []gardencorev1beta1.Worker{{Name: namePool1}, {Name: namePool2}}
+						w.Spec.Pools[1].ProviderConfig = &runtime.RawExtension{Raw: encode(&api.WorkerConfig{
+							SSH: &api.WorkerSSH{
+								ProjectKeyIDs:  []string{"project-key-2"},
+								UserKeyIDs:     []string{"user-key"},
+								AllProjectKeys: ptr.To(true),
+							},
+						})}
+					})
+
+					It("should deploy the correct machine class with the additional SSH keys", func() {
+						newHash, err := worker.WorkerPoolHash(w.Spec.Pools[1], cluster, []string{}, []string{}, []string{})
+						Expect(err).NotTo(HaveOccurred())
+
+						machineClass := machineClasses["machineClasses"].([]map[string]interface{})[2]
+						machineClass["name"] = fmt.Sprintf("%s-%s-%s", namespace, namePool2, newHash)
+						machineClass["sshKeys"] = []string{sshKeyID, "project-key-2", "project-key-1", "user-key"}
+
+						equinixClient := mockeqxmclient.NewMockClientInterface(ctrl)
+						equinixClient.EXPECT().ListProjectSSHKeys(ctx, projectID).Return([]metalv1.SSHKey{
+							{Id: ptr.To(sshKeyID)},
+							{Id: ptr.To("project-key-1")},
+							{Id: ptr.To("project-key-2")},
+						}, nil)
+						equinixClient.EXPECT().GetSSHKey(ctx, "user-key").Return(&metalv1.SSHKey{Id: ptr.To("user-key")}, nil)
+						newClient := func(apiKey string) (eqxmclient.ClientInterface, error) {
+							Expect(apiKey).To(Equal(apiToken))
+							return equinixClient, nil
+						}
+
+						expectGetSecretCallToWork(c, apiToken, projectID)
+						expectGetUserDataSecretCallToWork()
+
+						workerDelegate, _ := NewWorkerDelegate(c, scheme, newClient, nil, chartApplier, "", w, cluster, nil)
+
+						chartApplier.
+							EXPECT().
+							ApplyFromEmbeddedFS(
+								ctx,
+								charts.InternalChart,
+								filepath.Join(charts.InternalChartsPath, "machineclass"),
+								namespace,
+								"machineclass",
+								kubernetes.Values(machineClasses),
+							)
+
+						Expect(workerDelegate.DeployMachineClasses(ctx)).To(Succeed())
+					})
+
+					It("should not add the additional SSH keys if SSH access is disabled", func() {
+						cluster.Shoot.Spec.Provider.WorkersSettings = &gardencorev1beta1.WorkersSettings{
+							SSHAccess: &gardencorev1beta1.SSHAccess{Enabled: false},
+						}
+
+						newHash, err := worker.WorkerPoolHash(w.Spec.Pools[1], cluster, []string{}, []string{}, []string{})
+						Expect(err).NotTo(HaveOccurred())
+
+						machineClass := machineClasses["machineClasses"].([]map[string]interface{})[2]
+						machineClass["name"] = fmt.Sprintf("%s-%s-%s", namespace, namePool2, newHash)
+
+						expectGetSecretCallToWork(c, apiToken, projectID)
+						expectGetUserDataSecretCallToWork()
+
+						workerDelegate, _ := NewWorkerDelegate(c, scheme, nil, nil, chartApplier, "", w, cluster, nil)
+
+						chartApplier.
+							EXPECT().
+							ApplyFromEmbeddedFS(
+								ctx,
+								charts.InternalChart,
+								filepath.Join(charts.InternalChartsPath, "machineclass"),
+								namespace,
+								"machineclass",
+								kubernetes.Values(machineClasses),
+							)
+
+						Expect(workerDelegate.DeployMachineClasses(ctx)).To(Succeed())
+					})
+
+					It("should fail if a project key does not belong to the project", func() {
+						equinixClient := mockeqxmclient.NewMockClientInterface(ctrl)
+						equinixClient.EXPECT().ListProjectSSHKeys(ctx, projectID).Return([]metalv1.SSHKey{{Id: ptr.To("project-key-1")}}, nil)
+						newClient := func(_ string) (eqxmclient.ClientInterface, error) {
+							return equinixClient, nil
+						}
+
+						expectGetSecretCallToWork(c, apiToken, projectID)
+						expectGetUserDataSecretCallToWork()
+
+						workerDelegate, _ := NewWorkerDelegate(c, scheme, newClient, nil, chartApplier, "", w, cluster, nil)
+
+						_, err := workerDelegate.GenerateMachineDeployments(ctx)
+						Expect(err).To(MatchError(ContainSubstring(`SSH key "project-key-2" is not a key of the project`)))
+					})
+				})
+
+				Context("fallback machine types", func() {
+					const fallbackMachineType = "medium"
+
+					var equinixClient *mockeqxmclient.MockClientInterface
+
+					BeforeEach(func() {
+						w.Spec.Pools[1].Zones = []string{facility1}
+						w.Spec.Pools[1].NodeTemplate = &extensionsv1alpha1.NodeTemplate{
+							Capacity: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("16"),
+								corev1.ResourceMemory: resource.MustParse("64Gi"),
+							},
+						}
+						w.Spec.Pools[1].ProviderConfig = &runtime.RawExtension{Raw: encode(&api.WorkerConfig{
+							FallbackMachineTypes: []string{"xlarge", fallbackMachineType},
+						})}
+
+						cluster.CloudProfile.Spec.MachineTypes = []gardencorev1beta1.MachineType{{
+							Name:   fallbackMachineType,
+							CPU:    resource.MustParse("8"),
+							GPU:    resource.MustParse("0"),
+							Memory: resource.MustParse("32Gi"),
+						}}
+
+						equinixClient = mockeqxmclient.NewMockClientInterface(ctrl)
+					})
+
+					expectMachineClass := func(workerDelegate genericworkeractuator.WorkerDelegate, machineType string, nodeTemplate *machinev1alpha1.NodeTemplate) {
+						newHash, err := worker.WorkerPoolHash(w.Spec.Pools[1], cluster, []string{}, []string{}, []string{})
+						Expect(err).NotTo(HaveOccurred())
+
+						machineClass := machineClasses["machineClasses"].([]map[string]interface{})[2]
+						machineClass["name"] = fmt.Sprintf("%s-%s-z1-%s", namespace, namePool2, newHash)
+						machineClass["machineType"] = machineType
+						machineClass["facilities"] = []string{facility1}
+						machineClass["nodeTemplate"] = nodeTemplate
+
+						chartApplier.
+							EXPECT().
+							ApplyFromEmbeddedFS(
+								ctx,
+								charts.InternalChart,
+								filepath.Join(charts.InternalChartsPath, "machineclass"),
+								namespace,
+								"machineclass",
+								kubernetes.Values(machineClasses),
+							)
+
+						Expect(workerDelegate.DeployMachineClasses(ctx)).To(Succeed())
+
+						expectStatus(ctx, c, statusWriter, w, &apiv1alpha1.WorkerStatus{
+							MachineImages: []apiv1alpha1.MachineImage{
+								{
+									Name:         machineImageName,
+									Version:      machineImageVersion,
+									ID:           machineImage,
+									Architecture: ptr.To(v1beta1constants.ArchitectureAMD64),
+								},
+							},
+							WorkerPools: []apiv1alpha1.WorkerPoolStatus{
+								{
+									Name:        namePool2,
+									MachineType: machineType,
+								},
+							},
+						})
+						Expect(workerDelegate.UpdateMachineImagesStatus(ctx)).To(Succeed())
+					}
+
+					It("should keep the machine type of the pool while its capacity is available", func() {
+						equinixClient.EXPECT().ListMetroCapacity(ctx).Return(map[string]map[string]metalv1.CapacityLevelPerBaremetal{
+							region: {machineType: {Level: ptr.To("limited")}},
+						}, nil)
+
+						expectGetSecretCallToWork(c, apiToken, projectID)
+						expectGetUserDataSecretCallToWork()
+
+						workerDelegate, _ := NewWorkerDelegate(c, scheme, func(_ string) (eqxmclient.ClientInterface, error) {
+							return equinixClient, nil
+						}, nil, chartApplier, "", w, cluster, nil)
+
+						expectMachineClass(workerDelegate, machineType, &machinev1alpha1.NodeTemplate{
+							Capacity: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("16"),
+								corev1.ResourceMemory: resource.MustParse("64Gi"),
+							},
+							InstanceType: machineType,
+							Region:       region,
+							Zone:         facility1,
+							Architecture: ptr.To(v1beta1constants.ArchitectureAMD64),
+						})
+					})
+
+					It("should switch to the first available fallback machine type", func() {
+						equinixClient.EXPECT().ListMetroCapacity(ctx).Return(map[string]map[string]metalv1.CapacityLevelPerBaremetal{
+							region: {
+								machineType: {Level: ptr.To("unavailable")},
+								"xlarge":    {Level: ptr.To("unavailable")},
+							},
+							"da": {machineType: {Level: ptr.To("normal")}},
+						}, nil)
+
+						expectGetSecretCallToWork(c, apiToken, projectID)
+						expectGetUserDataSecretCallToWork()
+
+						workerDelegate, _ := NewWorkerDelegate(c, scheme, func(_ string) (eqxmclient.ClientInterface, error) {
+							return equinixClient, nil
+						}, nil, chartApplier, "", w, cluster, nil)
+
+						expectMachineClass(workerDelegate, fallbackMachineType, &machinev1alpha1.NodeTemplate{
+							Capacity: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("8"),
+								"gpu":                 resource.MustParse("0"),
+								corev1.ResourceMemory: resource.MustParse("32Gi"),
+							},
+							InstanceType: fallbackMachineType,
+							Region:       region,
+							Zone:         facility1,
+							Architecture: ptr.To(v1beta1constants.ArchitectureAMD64),
+						})
+
+						result, err := workerDelegate.GenerateMachineDeployments(ctx)
+						Expect(err).NotTo(HaveOccurred())
+						// the nodes of the previous machine type must keep their plan label
+						Expect(result[2].Labels).NotTo(HaveKey("metal.equinix.com/plan"))
+					})
+				})
+
+				It("should deploy the correct machine class when using tags and custom data", func() {
+					w.Spec.Pools[1].Labels = map[string]string{"example.com/cost-center": "1234", "foo": "bar"}
+					w.Spec.Pools[1].ProviderConfig = &runtime.RawExtension{Raw: encode(&api.WorkerConfig{
+						Tags:               []string{"team-a", "kubernetes.io/role/node"},
+						TagsFromPoolLabels: []string{"example.com/cost-center", "missing"},
+						CustomData:         &runtime.RawExtension{Raw: []byte(`{"foo":"bar"}`)},
+					})}
+
+					newHash, err := worker.WorkerPoolHash(w.Spec.Pools[1], cluster, []string{}, []string{`{"foo":"bar"}`}, []string{})
+					Expect(err).NotTo(HaveOccurred())
+
+					machineClass := machineClasses["machineClasses"].([]map[string]interface{})[2]
+					machineClass["name"] = fmt.Sprintf("%s-%s-%s", namespace, namePool2, newHash)
+					machineClass["tags"] = []string{
+						fmt.Sprintf("kubernetes.io/cluster/%s", namespace),
+						"kubernetes.io/role/node",
+						"team-a",
+						"example.com/cost-center=1234",
+					}
+					machineClass["customData"] = `{"foo":"bar"}`
+
+					expectGetSecretCallToWork(c, apiToken, projectID)
+					expectGetUserDataSecretCallToWork()
+
+					workerDelegate, _ := NewWorkerDelegate(c, scheme, nil, nil, chartApplier, "", w, cluster, nil)
+
+					chartApplier.
+						EXPECT().
+						ApplyFromEmbeddedFS(
+							ctx,
+							charts.InternalChart,
+							filepath.Join(charts.InternalChartsPath, "machineclass"),
+							namespace,
+							"machineclass",
+							kubernetes.Values(machineClasses),
+						)
+
+					Expect(workerDelegate.DeployMachineClasses(ctx)).To(Succeed())
+				})
+
+				It("should deploy the correct machine class when using a custom storage layout", func() {
+					w.Spec.Pools[1].ProviderConfig = &runtime.RawExtension{Raw: encode(&api.WorkerConfig{
+						Storage: &api.Storage{
+							Disks: []api.StorageDisk{{
+								Device:     "/dev/sda",
+								WipeTable:  ptr.To(true),
+								Partitions: []api.StoragePartition{{Label: "ROOT", Number: 1, Size: "0"}},
+							}},
+							Filesystems: []api.StorageFilesystem{{Device: "/dev/sda1", Format: "ext4", Point: ptr.To("/")}},
+						},
+					})}
+
+					storage := `{"disks":[{"device":"/dev/sda","partitions":[{"label":"ROOT","number":1,"size":"0"}],"wipeTable":true}],"filesystems":[{"mount":{"device":"/dev/sda1","format":"ext4","point":"/"}}]}`
+					newHash, err := worker.WorkerPoolHash(w.Spec.Pools[1], cluster, []string{}, []string{storage}, []string{})
+					Expect(err).NotTo(HaveOccurred())
+
+					machineClass := machineClasses["machineClasses"].([]map[string]interface{})[2]
+					machineClass["name"] = fmt.Sprintf("%s-%s-%s", namespace, namePool2, newHash)
+					machineClass["storage"] = storage
+
+					expectGetSecretCallToWork(c, apiToken, projectID)
+					expectGetUserDataSecretCallToWork()
+
+					workerDelegate, _ := NewWorkerDelegate(c, scheme, nil, nil, chartApplier, "", w, cluster, nil)
+
+					chartApplier.
+						EXPECT().
+						ApplyFromEmbeddedFS(
+							ctx,
+							charts.InternalChart,
+							filepath.Join(charts.InternalChartsPath, "machineclass"),
+							namespace,
+							"machineclass",
+							kubernetes.Values(machineClasses),
+						)
+
+					Expect(workerDelegate.DeployMachineClasses(ctx)).To(Succeed())
+				})
+
+				It("should deploy the correct machine class when using a volume layout", func() {
+					w.Spec.Pools[1].ProviderConfig = &runtime.RawExtension{Raw: encode(&api.WorkerConfig{
+						CustomData: &runtime.RawExtension{Raw: []byte(`{"foo":"bar"}`)},
+						VolumeLayout: &api.VolumeLayout{
+							Disks:   &api.DiskSelector{Types: []string{"nvme"}},
+							Volumes: []api.LogicalVolume{{Name: "containerd"}, {Name: "log", Size: ptr.To(resource.MustParse("1Gi"))}},
+						},
+					})}
+
+					volumeLayout := `{"diskTypes":["nvme"],"filesystem":"ext4","volumes":[{"name":"log","mountPoint":"/var/log","size":1073741824},{"name":"containerd","mountPoint":"/var/lib/containerd"}]}`
+					newHash, err := worker.WorkerPoolHash(w.Spec.Pools[1], cluster, []string{}, []string{`{"foo":"bar"}`, volumeLayout}, []string{})
+					Expect(err).NotTo(HaveOccurred())
+
+					machineClass := machineClasses["machineClasses"].([]map[string]interface{})[2]
+					machineClass["name"] = fmt.Sprintf("%s-%s-%s", namespace, namePool2, newHash)
+					machineClass["customData"] = `{"foo":"bar","gardener":{"volumes":` + volumeLayout + `}}`
+
+					expectGetSecretCallToWork(c, apiToken, projectID)
+					expectGetUserDataSecretCallToWork()
+
+					workerDelegate, _ := NewWorkerDelegate(c, scheme, nil, nil, chartApplier, "", w, cluster, nil)
+
+					chartApplier.
+						EXPECT().
+						ApplyFromEmbeddedFS(
+							ctx,
+							charts.InternalChart,
+							filepath.Join(charts.InternalChartsPath, "machineclass"),
+							namespace,
+							"machineclass",
+							kubernetes.Values(machineClasses),
+						)
+
+					Expect(workerDelegate.DeployMachineClasses(ctx)).To(Succeed())
+				})
+
+				It("should deploy the correct machine class for a billing cycle without changing the worker pool hash", func() {
+					w.Spec.Pools[1].NodeAgentSecretName = ptr.To("node-agent-secret")
+					hashWithoutBillingCycle, err := worker.WorkerPoolHash(w.Spec.Pools[1], cluster, []string{}, []string{}, []string{})
+					Expect(err).NotTo(HaveOccurred())
+
+					w.Spec.Pools[1].ProviderConfig = &runtime.RawExtension{Raw: encode(&api.WorkerConfig{
+						BillingCycle: ptr.To("monthly"),
+					})}
+
+					machineClass := machineClasses["machineClasses"].([]map[string]interface{})[2]
+					machineClass["name"] = fmt.Sprintf("%s-%s-%s", namespace, namePool2, hashWithoutBillingCycle)
+					machineClass["billingCycle"] = "monthly"
+
+					expectGetSecretCallToWork(c, apiToken, projectID)
+					expectGetUserDataSecretCallToWork()
+
+					workerDelegate, _ := NewWorkerDelegate(c, scheme, nil, nil, chartApplier, "", w, cluster, nil)
+
+					chartApplier.
+						EXPECT().
+						ApplyFromEmbeddedFS(
+							ctx,
+							charts.InternalChart,
+							filepath.Join(charts.InternalChartsPath, "machineclass"),
+							namespace,
+							"machineclass",
+							kubernetes.Values(machineClasses),
+						)
+
+					Expect(workerDelegate.DeployMachineClasses(ctx)).To(Succeed())
+				})
+
+				It("should use the machine image matching the architecture of the pool", func() {
+					w.Spec.Pools[1].Architecture = ptr.To(v1beta1constants.ArchitectureARM64)
+					machineClasses["machineClasses"].([]map[string]interface{})[2]["OS"] = machineImageARM64
+
+					expectGetSecretCallToWork(c, apiToken, projectID)
+					expectGetUserDataSecretCallToWork()
+
+					workerDelegate, _ := NewWorkerDelegate(c, scheme, nil, nil, chartApplier, "", w, cluster, nil)
+
+					chartApplier.
+						EXPECT().
+						ApplyFromEmbeddedFS(
+							ctx,
+							charts.InternalChart,
+							filepath.Join(charts.InternalChartsPath, "machineclass"),
+							namespace,
+							"machineclass",
+							kubernetes.Values(machineClasses),
+						)
+
+					Expect(workerDelegate.DeployMachineClasses(ctx)).To(Succeed())
+
+					expectStatusContainsMachineImages(ctx, c, statusWriter, w, []apiv1alpha1.MachineImage{
+						{
+							Name:         machineImageName,
+							Version:      machineImageVersion,
+							ID:           machineImage,
+							Architecture: ptr.To(v1beta1constants.ArchitectureAMD64),
+						},
+						{
+							Name:         machineImageName,
+							Version:      machineImageVersion,
+							ID:           machineImageARM64,
+							Architecture: ptr.To(v1beta1constants.ArchitectureARM64),
+						},
+					})
+					Expect(workerDelegate.UpdateMachineImagesStatus(ctx)).To(Succeed())
 				})
 			})
 
@@ -462,7 +1458,7 @@ var _ = Describe("Machines", func() {
 				expectGetSecretCallToWork(c, apiToken, projectID)
 
 				clusterWithoutImages.Shoot.Spec.Kubernetes.Version = "invalid"
-				workerDelegate, _ = NewWorkerDelegate(c, scheme, chartApplier, "", w, cluster)
+				workerDelegate, _ = NewWorkerDelegate(c, scheme, nil, nil, chartApplier, "", w, cluster, nil)
 
 				result, err := workerDelegate.GenerateMachineDeployments(ctx)
 				Expect(err).To(HaveOccurred())
@@ -472,7 +1468,7 @@ var _ = Describe("Machines", func() {
 			It("should return err when the infrastructure provider status cannot be decoded", func() {
 				w.Spec.InfrastructureProviderStatus = &runtime.RawExtension{Raw: []byte(`invalid`)}
 
-				workerDelegate, _ = NewWorkerDelegate(c, scheme, chartApplier, "", w, cluster)
+				workerDelegate, _ = NewWorkerDelegate(c, scheme, nil, nil, chartApplier, "", w, cluster, nil)
 
 				result, err := workerDelegate.GenerateMachineDeployments(ctx)
 				Expect(err).To(HaveOccurred())
@@ -482,7 +1478,7 @@ var _ = Describe("Machines", func() {
 			It("should fail because the machine image cannot be found", func() {
 				expectGetSecretCallToWork(c, apiToken, projectID)
 
-				workerDelegate, _ = NewWorkerDelegate(c, scheme, chartApplier, "", w, clusterWithoutImages)
+				workerDelegate, _ = NewWorkerDelegate(c, scheme, nil, nil, chartApplier, "", w, clusterWithoutImages, nil)
 
 				result, err := workerDelegate.GenerateMachineDeployments(ctx)
 				Expect(err).To(HaveOccurred())
@@ -505,7 +1501,7 @@ var _ = Describe("Machines", func() {
 					NodeConditions:         testNodeConditions,
 				}
 
-				workerDelegate, _ = NewWorkerDelegate(c, scheme, chartApplier, "", w, cluster)
+				workerDelegate, _ = NewWorkerDelegate(c, scheme, nil, nil, chartApplier, "", w, cluster, nil)
 
 				expectGetUserDataSecretCallToWork()
 
@@ -520,6 +1516,41 @@ var _ = Describe("Machines", func() {
 				Expect(resultSettings.MaxEvictRetries).To(Equal(&testMaxEvictRetries))
 				Expect(resultSettings.NodeConditions).To(Equal(&resultNodeConditions))
 			})
+
+			It("should default the machine timeouts of the pools from the cloud profile config", func() {
+				expectGetSecretCallToWork(c, apiToken, projectID)
+
+				testDrainTimeout := metav1.Duration{Duration: 10 * time.Minute}
+				w.Spec.Pools[0].MachineControllerManagerSettings = &gardencorev1beta1.MachineControllerManagerSettings{
+					MachineDrainTimeout: &testDrainTimeout,
+				}
+
+				cloudProfileConfig := &apiv1alpha1.CloudProfileConfig{}
+				Expect(json.Unmarshal(cluster.CloudProfile.Spec.ProviderConfig.Raw, cloudProfileConfig)).To(Succeed())
+				cloudProfileConfig.MachineTimeouts = []apiv1alpha1.MachineTimeouts{
+					{
+						PlanFamilies:    []string{machineType},
+						MachineImages:   []string{machineImageName},
+						CreationTimeout: &metav1.Duration{Duration: 40 * time.Minute},
+						DrainTimeout:    &metav1.Duration{Duration: time.Hour},
+					},
+					{
+						PlanFamilies:  []string{"other"},
+						HealthTimeout: &metav1.Duration{Duration: time.Hour},
+					},
+				}
+				cluster.CloudProfile.Spec.ProviderConfig = &runtime.RawExtension{Raw: encode(cloudProfileConfig)}
+
+				workerDelegate, _ = NewWorkerDelegate(c, scheme, nil, nil, chartApplier, "", w, cluster, nil)
+
+				expectGetUserDataSecretCallToWork()
+
+				result, err := workerDelegate.GenerateMachineDeployments(ctx)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result[0].MachineConfiguration.MachineCreationTimeout).To(Equal(&metav1.Duration{Duration: 40 * time.Minute}))
+				Expect(result[0].MachineConfiguration.MachineHealthTimeout).To(BeNil())
+				Expect(result[0].MachineConfiguration.MachineDrainTimeout).To(Equal(&testDrainTimeout))
+			})
 		})
 	})
 })
@@ -542,12 +1573,13 @@ func expectGetSecretCallToWork(c *mockclient.MockClient, apiToken, projectID str
 }
 
 func expectStatusContainsMachineImages(ctx context.Context, c *mockclient.MockClient, statusWriter *mockclient.MockStatusWriter, worker *extensionsv1alpha1.Worker, images []apiv1alpha1.MachineImage) {
-	expectedProviderStatus := &apiv1alpha1.WorkerStatus{
-		TypeMeta: metav1.TypeMeta{
-			APIVersion: apiv1alpha1.SchemeGroupVersion.String(),
-			Kind:       "WorkerStatus",
-		},
-		MachineImages: images,
+	expectStatus(ctx, c, statusWriter, worker, &apiv1alpha1.WorkerStatus{MachineImages: images})
+}
+
+func expectStatus(ctx context.Context, c *mockclient.MockClient, statusWriter *mockclient.MockStatusWriter, worker *extensionsv1alpha1.Worker, expectedProviderStatus *apiv1alpha1.WorkerStatus) {
+	expectedProviderStatus.TypeMeta = metav1.TypeMeta{
+		APIVersion: apiv1alpha1.SchemeGroupVersion.String(),
+		Kind:       "WorkerStatus",
 	}
 	workerWithExpectedStatus := worker.DeepCopy()
 	workerWithExpectedStatus.Status.ProviderStatus = &runtime.RawExtension{
@@ -0,0 +1,319 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/equinix/equinix-sdk-go/services/metalv1"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/ptr"
+
+	api "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal"
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal/helper"
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal"
+	eqxcmclient "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal/client"
+)
+
+// poolNetwork contains the network configuration of the devices of a worker pool.
+type poolNetwork struct {
+	Type    string  `json:"type"`
+	VLANIDs []int32 `json:"vlans,omitempty"`
+}
+
+// clusterTag returns the tag of the Equinix Metal resources belonging to the cluster.
+func (w *workerDelegate) clusterTag() string {
+	return fmt.Sprintf("kubernetes.io/cluster/%s", w.worker.Namespace)
+}
+
+// managedVLANNames returns the names of the VLANs of the worker pools which are managed by the extension, i.e., which
+// do not reference an existing VLAN by its ID.
+func (w *workerDelegate) managedVLANNames() (sets.Set[string], error) {
+	names := sets.New[string]()
+	for _, pool := range w.worker.Spec.Pools {
+		workerConfig, err := helper.WorkerConfigFromRawExtension(pool.ProviderConfig)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode provider config of worker pool %q: %w", pool.Name, err)
+		}
+		if workerConfig.Network == nil {
+			continue
+		}
+		for _, vlan := range workerConfig.Network.VLANs {
+			if vlan.ID == nil {
+				names.Insert(vlan.Name)
+			}
+		}
+	}
+	return names, nil
+}
+
+// managedVLANDescription returns the description of the VLAN with the given name which is managed by the extension.
+func (w *workerDelegate) managedVLANDescription(name string) string {
+	return fmt.Sprintf("%s-%s", w.worker.Namespace, name)
+}
+
+// listVLANs lists the VLANs in the metro of the shoot. They are only listed once per reconciliation.
+func (w *workerDelegate) listVLANs(ctx context.Context, equinixClient eqxcmclient.ClientInterface, projectID string) error {
+	if w.vlans != nil {
+		return nil
+	}
+
+	vlans, err := equinixClient.ListVLANs(ctx, projectID, w.worker.Spec.Region)
+	if err != nil {
+		return fmt.Errorf("could not list VLANs: %w", err)
+	}
+	w.vlans = append([]metalv1.VirtualNetwork{}, vlans...)
+	return nil
+}
+
+// ensureManagedVLANs creates the VLANs of the worker pools which are managed by the extension in the metro of the
+// shoot if they do not exist yet. VLANs are never created while the worker is deleted.
+func (w *workerDelegate) ensureManagedVLANs(ctx context.Context) error {
+	if w.worker.DeletionTimestamp != nil {
+		return nil
+	}
+
+	names, err := w.managedVLANNames()
+	if err != nil || names.Len() == 0 {
+		return err
+	}
+
+	credentials, err := equinixmetal.GetCredentialsFromSecretRef(ctx, w.client, w.worker.Spec.SecretRef)
+	if err != nil {
+		return fmt.Errorf("could not get credentials from secret: %w", err)
+	}
+	equinixClient, err := w.newClient(string(credentials.APIToken))
+	if err != nil {
+		return err
+	}
+	if err := w.listVLANs(ctx, equinixClient, string(credentials.ProjectID)); err != nil {
+		return err
+	}
+
+	for _, name := range sets.List(names) {
+		description := w.managedVLANDescription(name)
+		if w.findManagedVLAN(description) != nil {
+			continue
+		}
+
+		newVLAN, err := equinixClient.CreateVLAN(ctx, string(credentials.ProjectID), metalv1.VirtualNetworkCreateInput{
+			Description: &description,
+			Metro:       &w.worker.Spec.Region,
+			Tags:        []string{w.clusterTag()},
+		})
+		if err != nil {
+			return fmt.Errorf("could not create VLAN %q: %w", name, err)
+		}
+		w.vlans = append(w.vlans, *newVLAN)
+	}
+
+	return nil
+}
+
+// vlanIDs returns the VLAN IDs of the given VLANs. The VLANs managed by the extension must have been created before,
+// only while the worker is deleted missing VLANs are skipped.
+func (w *workerDelegate) vlanIDs(ctx context.Context, credentials *equinixmetal.Credentials, vlans []api.VLAN) ([]int32, error) {
+	var vlanIDs []int32
+
+	for _, vlan := range vlans {
+		if vlan.ID != nil {
+			vlanIDs = append(vlanIDs, *vlan.ID)
+			continue
+		}
+
+		equinixClient, err := w.newClient(string(credentials.APIToken))
+		if err != nil {
+			return nil, err
+		}
+		if err := w.listVLANs(ctx, equinixClient, string(credentials.ProjectID)); err != nil {
+			return nil, err
+		}
+
+		existingVLAN := w.findManagedVLAN(w.managedVLANDescription(vlan.Name))
+		switch {
+		case existingVLAN != nil:
+			vlanIDs = append(vlanIDs, existingVLAN.GetVxlan())
+		case w.worker.DeletionTimestamp == nil:
+			return nil, fmt.Errorf("VLAN %q does not exist", vlan.Name)
+		}
+	}
+
+	return vlanIDs, nil
+}
+
+func (w *workerDelegate) findManagedVLAN(description string) *metalv1.VirtualNetwork {
+	for i, vlan := range w.vlans {
+		if vlan.GetDescription() == description && sets.New(vlan.GetTags()...).Has(w.clusterTag()) {
+			return &w.vlans[i]
+		}
+	}
+	return nil
+}
+
+// deleteObsoleteVLANs deletes the VLANs managed by the extension which are no longer used by any worker pool. VLANs
+// which are still assigned to devices, e.g. because the worker pool has not been rolled yet, are kept until a later
+// reconciliation.
+func (w *workerDelegate) deleteObsoleteVLANs(ctx context.Context, equinixClient eqxcmclient.ClientInterface, projectID string) error {
+	names, err := w.managedVLANNames()
+	if err != nil {
+		return err
+	}
+
+	vlans, err := equinixClient.ListVLANs(ctx, projectID, w.worker.Spec.Region)
+	if err != nil {
+		return fmt.Errorf("could not list VLANs: %w", err)
+	}
+
+	desired := sets.New[string]()
+	for name := range names {
+		desired.Insert(w.managedVLANDescription(name))
+	}
+
+	for _, vlan := range vlans {
+		if !sets.New(vlan.GetTags()...).Has(w.clusterTag()) || desired.Has(vlan.GetDescription()) || len(vlan.GetInstances()) > 0 {
+			continue
+		}
+		if err := equinixClient.DeleteVLAN(ctx, vlan.GetId()); err != nil {
+			return fmt.Errorf("could not delete VLAN %q: %w", vlan.GetDescription(), err)
+		}
+	}
+
+	return nil
+}
+
+// deleteManagedVLANs deletes the VLANs managed by the extension.
+func (w *workerDelegate) deleteManagedVLANs(ctx context.Context, equinixClient eqxcmclient.ClientInterface, projectID string) error {
+	vlans, err := equinixClient.ListVLANs(ctx, projectID, w.worker.Spec.Region)
+	if err != nil {
+		return fmt.Errorf("could not list VLANs: %w", err)
+	}
+
+	for _, vlan := range vlans {
+		if !sets.New(vlan.GetTags()...).Has(w.clusterTag()) {
+			continue
+		}
+		if err := equinixClient.DeleteVLAN(ctx, vlan.GetId()); err != nil {
+			return fmt.Errorf("could not delete VLAN %q: %w", vlan.GetDescription(), err)
+		}
+	}
+
+	return nil
+}
+
+// ensureDeviceNetworks converts the ports of the devices of the nodes to the network type of their worker pools and
+// assigns the VLANs.
+func (w *workerDelegate) ensureDeviceNetworks(ctx context.Context, equinixClient eqxcmclient.ClientInterface, nodes []corev1.Node) error {
+	if len(w.poolNetworks) == 0 {
+		return nil
+	}
+
+	var poolNodes []corev1.Node
+	for _, node := range nodes {
+		if _, ok := w.poolNetworks[node.Labels[v1beta1constants.LabelWorkerPool]]; ok {
+			poolNodes = append(poolNodes, node)
+		}
+	}
+
+	return w.forEachNodeDevice(ctx, equinixClient, poolNodes, func(ctx context.Context, node *corev1.Node, device *metalv1.Device) error {
+		if err := ensureDeviceNetwork(ctx, equinixClient, device, w.poolNetworks[node.Labels[v1beta1constants.LabelWorkerPool]]); err != nil {
+			return fmt.Errorf("could not configure network of node %s: %w", node.Name, err)
+		}
+		return nil
+	})
+}
+
+// ensureDeviceNetwork converts the ports of the given device to the given network type and assigns the VLANs. Only
+// devices in the default layer 3 mode are converted, devices which already use another network type are left as they
+// are.
+func ensureDeviceNetwork(ctx context.Context, equinixClient eqxcmclient.ClientInterface, device *metalv1.Device, network poolNetwork) error {
+	ports := map[string]metalv1.Port{}
+	for _, port := range device.GetNetworkPorts() {
+		ports[port.GetName()] = port
+	}
+
+	var (
+		bond0, eth0, eth1 = ports["bond0"], ports["eth0"], ports["eth1"]
+		currentType       = string(bond0.GetNetworkType())
+		needsConversion   = currentType == api.NetworkTypeLayer3
+		vlanPort          = bond0
+	)
+
+	if !needsConversion && currentType != network.Type {
+		return nil
+	}
+
+	convert := func(steps ...func() (*metalv1.Port, error)) error {
+		if !needsConversion {
+			return nil
+		}
+		for _, step := range steps {
+			if _, err := step(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var err error
+	switch network.Type {
+	case api.NetworkTypeHybrid:
+		vlanPort = eth1
+		err = convert(
+			func() (*metalv1.Port, error) { return equinixClient.DisbondPort(ctx, eth1.GetId(), false) },
+			func() (*metalv1.Port, error) { return equinixClient.ConvertPortToLayer2(ctx, eth1.GetId()) },
+		)
+	case api.NetworkTypeHybridBonded:
+		// the bond stays in layer 3 mode, assigning the VLANs to it makes it hybrid bonded
+	case api.NetworkTypeLayer2Bonded:
+		err = convert(
+			func() (*metalv1.Port, error) { return equinixClient.ConvertPortToLayer2(ctx, bond0.GetId()) },
+		)
+	case api.NetworkTypeLayer2Individual:
+		vlanPort = eth1
+		err = convert(
+			func() (*metalv1.Port, error) { return equinixClient.DisbondPort(ctx, bond0.GetId(), true) },
+			func() (*metalv1.Port, error) { return equinixClient.ConvertPortToLayer2(ctx, eth0.GetId()) },
+			func() (*metalv1.Port, error) { return equinixClient.ConvertPortToLayer2(ctx, eth1.GetId()) },
+		)
+	}
+	if err != nil {
+		return fmt.Errorf("could not convert ports to network type %q: %w", network.Type, err)
+	}
+
+	assignedVLANIDs := sets.New[int32]()
+	for _, vlan := range vlanPort.GetVirtualNetworks() {
+		assignedVLANIDs.Insert(vlan.GetVxlan())
+	}
+
+	for _, vlanID := range network.VLANIDs {
+		if assignedVLANIDs.Has(vlanID) {
+			continue
+		}
+		if _, err := equinixClient.AssignPortVLAN(ctx, vlanPort.GetId(), strconv.Itoa(int(vlanID))); err != nil {
+			return fmt.Errorf("could not assign VLAN %d to port %s: %w", vlanID, vlanPort.GetName(), err)
+		}
+	}
+
+	return nil
+}
+
+// newPoolNetwork returns the network configuration of a worker pool, or nil if it uses the default network type
+// without VLANs.
+func newPoolNetwork(network *api.WorkerNetwork, vlanIDs []int32) *poolNetwork {
+	if network == nil {
+		return nil
+	}
+
+	networkType := ptr.Deref(network.Type, api.NetworkTypeLayer3)
+	if networkType == api.NetworkTypeLayer3 && len(vlanIDs) == 0 {
+		return nil
+	}
+
+	return &poolNetwork{Type: networkType, VLANIDs: vlanIDs}
+}
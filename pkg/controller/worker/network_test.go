@@ -0,0 +1,207 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	"context"
+
+	"github.com/equinix/equinix-sdk-go/services/metalv1"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubernetesscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/ptr"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	api "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal"
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal"
+	eqxcmclient "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal/client"
+	mockeqxcmclient "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal/client/mock"
+)
+
+var _ = Describe("Network", func() {
+	var (
+		ctx           = context.TODO()
+		ctrl          *gomock.Controller
+		equinixClient *mockeqxcmclient.MockClientInterface
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		equinixClient = mockeqxcmclient.NewMockClientInterface(ctrl)
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	Describe("#ensureDeviceNetwork", func() {
+		newDevice := func(networkType metalv1.PortNetworkType, eth1VLANs ...int32) *metalv1.Device {
+			var virtualNetworks []metalv1.VirtualNetwork
+			for _, vlan := range eth1VLANs {
+				virtualNetworks = append(virtualNetworks, metalv1.VirtualNetwork{Vxlan: ptr.To(vlan)})
+			}
+
+			return &metalv1.Device{NetworkPorts: []metalv1.Port{
+				{Id: ptr.To("bond0-id"), Name: ptr.To("bond0"), NetworkType: &networkType},
+				{Id: ptr.To("eth0-id"), Name: ptr.To("eth0")},
+				{Id: ptr.To("eth1-id"), Name: ptr.To("eth1"), VirtualNetworks: virtualNetworks},
+			}}
+		}
+
+		It("should convert layer3 devices and assign the VLANs", func() {
+			gomock.InOrder(
+				equinixClient.EXPECT().DisbondPort(ctx, "eth1-id", false),
+				equinixClient.EXPECT().ConvertPortToLayer2(ctx, "eth1-id"),
+				equinixClient.EXPECT().AssignPortVLAN(ctx, "eth1-id", "1001"),
+				equinixClient.EXPECT().AssignPortVLAN(ctx, "eth1-id", "1002"),
+			)
+
+			Expect(ensureDeviceNetwork(ctx, equinixClient, newDevice(metalv1.PORTNETWORKTYPE_LAYER3), poolNetwork{
+				Type:    "hybrid",
+				VLANIDs: []int32{1001, 1002},
+			})).To(Succeed())
+		})
+
+		It("should only assign missing VLANs to converted devices", func() {
+			equinixClient.EXPECT().AssignPortVLAN(ctx, "eth1-id", "1002")
+
+			Expect(ensureDeviceNetwork(ctx, equinixClient, newDevice(metalv1.PORTNETWORKTYPE_HYBRID, 1001), poolNetwork{
+				Type:    "hybrid",
+				VLANIDs: []int32{1001, 1002},
+			})).To(Succeed())
+		})
+
+		It("should assign the VLANs to the bond of bonded devices", func() {
+			gomock.InOrder(
+				equinixClient.EXPECT().ConvertPortToLayer2(ctx, "bond0-id"),
+				equinixClient.EXPECT().AssignPortVLAN(ctx, "bond0-id", "1001"),
+			)
+
+			Expect(ensureDeviceNetwork(ctx, equinixClient, newDevice(metalv1.PORTNETWORKTYPE_LAYER3), poolNetwork{
+				Type:    "layer2-bonded",
+				VLANIDs: []int32{1001},
+			})).To(Succeed())
+		})
+
+		It("should assign the VLANs to the bond of layer3 devices without converting it for hybrid bonded devices", func() {
+			equinixClient.EXPECT().AssignPortVLAN(ctx, "bond0-id", "1001")
+
+			Expect(ensureDeviceNetwork(ctx, equinixClient, newDevice(metalv1.PORTNETWORKTYPE_LAYER3), poolNetwork{
+				Type:    "hybrid-bonded",
+				VLANIDs: []int32{1001},
+			})).To(Succeed())
+		})
+
+		It("should not touch devices using another network type", func() {
+			Expect(ensureDeviceNetwork(ctx, equinixClient, newDevice(metalv1.PORTNETWORKTYPE_LAYER2_BONDED), poolNetwork{
+				Type:    "hybrid",
+				VLANIDs: []int32{1001},
+			})).To(Succeed())
+		})
+	})
+
+	Describe("VLANs", func() {
+		const (
+			namespace  = "shoot--foo--bar"
+			clusterTag = "kubernetes.io/cluster/" + namespace
+		)
+
+		var w *workerDelegate
+
+		BeforeEach(func() {
+			seedClient := fakeclient.NewClientBuilder().WithScheme(kubernetesscheme.Scheme).WithObjects(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace},
+				Data:       map[string][]byte{equinixmetal.APIToken: []byte("token"), equinixmetal.ProjectID: []byte("project-id")},
+			}).Build()
+
+			w = &workerDelegate{
+				client: seedClient,
+				newClient: func(_ string) (eqxcmclient.ClientInterface, error) {
+					return equinixClient, nil
+				},
+				worker: &extensionsv1alpha1.Worker{
+					ObjectMeta: metav1.ObjectMeta{Namespace: namespace},
+					Spec: extensionsv1alpha1.WorkerSpec{
+						SecretRef: corev1.SecretReference{Name: "secret", Namespace: namespace},
+						Region:    "ny",
+						Pools: []extensionsv1alpha1.WorkerPool{{
+							Name:           "pool-1",
+							ProviderConfig: &runtime.RawExtension{Raw: []byte(`{"apiVersion":"equinixmetal.provider.extensions.gardener.cloud/v1alpha1","kind":"WorkerConfig","network":{"type":"hybrid","vlans":[{"name":"existing","id":42},{"name":"managed"},{"name":"new"}]}}`)},
+						}},
+					},
+				},
+			}
+		})
+
+		Describe("#ensureManagedVLANs", func() {
+			It("should create the missing VLANs managed by the extension", func() {
+				equinixClient.EXPECT().ListVLANs(ctx, "project-id", "ny").Return([]metalv1.VirtualNetwork{
+					{Description: ptr.To(namespace + "-managed"), Vxlan: ptr.To[int32](1001), Tags: []string{clusterTag}},
+					{Description: ptr.To(namespace + "-new"), Vxlan: ptr.To[int32](1003)},
+				}, nil)
+				equinixClient.EXPECT().CreateVLAN(ctx, "project-id", metalv1.VirtualNetworkCreateInput{
+					Description: ptr.To(namespace + "-new"),
+					Metro:       ptr.To("ny"),
+					Tags:        []string{clusterTag},
+				}).Return(&metalv1.VirtualNetwork{Description: ptr.To(namespace + "-new"), Vxlan: ptr.To[int32](1002), Tags: []string{clusterTag}}, nil)
+
+				Expect(w.ensureManagedVLANs(ctx)).To(Succeed())
+				Expect(w.vlanIDs(ctx, &equinixmetal.Credentials{ProjectID: []byte("project-id")}, []api.VLAN{
+					{Name: "existing", ID: ptr.To[int32](42)},
+					{Name: "managed"},
+					{Name: "new"},
+				})).To(Equal([]int32{42, 1001, 1002}))
+			})
+
+			It("should not create VLANs while the worker is deleted", func() {
+				w.worker.DeletionTimestamp = &metav1.Time{}
+				equinixClient.EXPECT().ListVLANs(ctx, "project-id", "ny").Return(nil, nil)
+
+				Expect(w.ensureManagedVLANs(ctx)).To(Succeed())
+				Expect(w.vlanIDs(ctx, &equinixmetal.Credentials{ProjectID: []byte("project-id")}, []api.VLAN{
+					{Name: "existing", ID: ptr.To[int32](42)},
+					{Name: "new"},
+				})).To(Equal([]int32{42}))
+			})
+		})
+
+		Describe("#deleteObsoleteVLANs", func() {
+			It("should only delete the unassigned VLANs of the cluster which are no longer used by any worker pool", func() {
+				equinixClient.EXPECT().ListVLANs(ctx, "project-id", "ny").Return([]metalv1.VirtualNetwork{
+					{Id: ptr.To("managed"), Description: ptr.To(namespace + "-managed"), Tags: []string{clusterTag}},
+					{Id: ptr.To("removed"), Description: ptr.To(namespace + "-removed"), Tags: []string{clusterTag}},
+					{Id: ptr.To("assigned"), Description: ptr.To(namespace + "-assigned"), Tags: []string{clusterTag}, Instances: []metalv1.Device{{Id: ptr.To("device-1")}}},
+					{Id: ptr.To("other-cluster"), Description: ptr.To(namespace + "-removed"), Tags: []string{"kubernetes.io/cluster/shoot--foo--baz"}},
+				}, nil)
+				equinixClient.EXPECT().DeleteVLAN(ctx, "removed")
+
+				Expect(w.deleteObsoleteVLANs(ctx, equinixClient, "project-id")).To(Succeed())
+			})
+		})
+	})
+
+	Describe("#deleteManagedVLANs", func() {
+		It("should only delete the VLANs of the cluster", func() {
+			w := &workerDelegate{worker: &extensionsv1alpha1.Worker{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "shoot--foo--bar"},
+				Spec:       extensionsv1alpha1.WorkerSpec{Region: "ny"},
+			}}
+
+			equinixClient.EXPECT().ListVLANs(ctx, "project-id", "ny").Return([]metalv1.VirtualNetwork{
+				{Id: ptr.To("managed"), Tags: []string{"kubernetes.io/cluster/shoot--foo--bar"}},
+				{Id: ptr.To("other-cluster"), Tags: []string{"kubernetes.io/cluster/shoot--foo--baz"}},
+				{Id: ptr.To("unmanaged")},
+			}, nil)
+			equinixClient.EXPECT().DeleteVLAN(ctx, "managed")
+
+			Expect(w.deleteManagedVLANs(ctx, equinixClient, "project-id")).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,218 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/equinix/equinix-sdk-go/services/metalv1"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	gardencorev1beta1helper "github.com/gardener/gardener/pkg/apis/core/v1beta1/helper"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	machinev1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal"
+)
+
+const (
+	// resourceGPU is the name of the resource of the NVIDIA GPUs of the nodes.
+	resourceGPU corev1.ResourceName = "nvidia.com/gpu"
+	// planDriveCategoryBoot is the category of the drives of a plan which hold the operating system.
+	planDriveCategoryBoot = "boot"
+)
+
+// cpuCoresPattern matches the number of cores in the type of a CPU of a plan, e.g. "AMD EPYC 7402P 24-Core Processor".
+var cpuCoresPattern = regexp.MustCompile(`(\d+)-Core`)
+
+// machineNodeTemplate returns the node template which the cluster-autoscaler uses to scale a machine deployment of the
+// given worker pool, whose devices are created in the given facilities, from zero. The capacity is taken from the
+// machine type of the CloudProfile if Gardener provides it for the worker pool. Otherwise, the capacity of worker pools
+// which can be scaled to zero is derived from the plan of the Equinix Metal API. Nil is returned for all other worker
+// pools.
+func (w *workerDelegate) machineNodeTemplate(ctx context.Context, credentials *equinixmetal.Credentials, pool extensionsv1alpha1.WorkerPool, arch string, facilities []string) (*machinev1alpha1.NodeTemplate, error) {
+	nodeTemplate := &machinev1alpha1.NodeTemplate{
+		InstanceType: pool.MachineType,
+		Region:       w.worker.Spec.Region,
+		Architecture: &arch,
+	}
+
+	switch {
+	case pool.NodeTemplate != nil:
+		nodeTemplate.Capacity = pool.NodeTemplate.Capacity.DeepCopy()
+		nodeTemplate.VirtualCapacity = pool.NodeTemplate.VirtualCapacity.DeepCopy()
+
+	case pool.Minimum == 0:
+		plan, err := w.findPlan(ctx, credentials, pool.MachineType)
+		if err != nil {
+			return nil, err
+		}
+		if nodeTemplate.Capacity, err = planCapacity(plan); err != nil {
+			return nil, fmt.Errorf("could not determine capacity of plan %q: %w", pool.MachineType, err)
+		}
+
+	default:
+		return nil, nil
+	}
+
+	// the nodes of machine deployments spanning multiple facilities cannot be attributed to a single zone
+	if len(facilities) == 1 {
+		nodeTemplate.Zone = facilities[0]
+	}
+
+	return nodeTemplate, nil
+}
+
+// poolWithMachineType returns a copy of the given worker pool using the given machine type. If it differs from the
+// machine type of the worker pool, the node template which Gardener derived from the CloudProfile is replaced by the
+// one of the given machine type, like Gardener does it for the machine type of a worker pool.
+func poolWithMachineType(pool extensionsv1alpha1.WorkerPool, machineType string, cloudProfile *gardencorev1beta1.CloudProfile) extensionsv1alpha1.WorkerPool {
+	if machineType == pool.MachineType {
+		return pool
+	}
+
+	pool.MachineType = machineType
+	pool.NodeTemplate = nil
+	if cloudProfile == nil {
+		return pool
+	}
+	if details := gardencorev1beta1helper.FindMachineTypeByName(cloudProfile.Spec.MachineTypes, machineType); details != nil {
+		pool.NodeTemplate = &extensionsv1alpha1.NodeTemplate{
+			Capacity: corev1.ResourceList{
+				corev1.ResourceCPU:    details.CPU,
+				"gpu":                 details.GPU,
+				corev1.ResourceMemory: details.Memory,
+			},
+		}
+	}
+	return pool
+}
+
+// nodeTemplateLabels returns the labels which are set on the nodes of a machine deployment once their devices are
+// known, see setDeviceNodeLabels, and which are the same for all of its nodes. They are added to the machine deployment,
+// so that the cluster-autoscaler considers them when scaling the machine deployment from zero. The plan and the facility
+// are deliberately not added: machine-controller-manager propagates the labels of a machine deployment to its existing
+// nodes, whose plan differs from the one of new machines once a fallback machine type is selected. The cluster-autoscaler
+// takes them from the instance type and the zone of the node template instead.
+func nodeTemplateLabels(spotInstance bool, region string) map[string]string {
+	capacityType := capacityTypeOnDemand
+	if spotInstance {
+		capacityType = capacityTypeSpot
+	}
+
+	return map[string]string{
+		equinixmetal.MetroLabel:        region,
+		equinixmetal.CapacityTypeLabel: capacityType,
+	}
+}
+
+// findPlan returns the plan with the given slug. The plans of the project are only fetched once per reconciliation.
+func (w *workerDelegate) findPlan(ctx context.Context, credentials *equinixmetal.Credentials, slug string) (*metalv1.Plan, error) {
+	if w.plans == nil {
+		equinixClient, err := w.newClient(string(credentials.APIToken))
+		if err != nil {
+			return nil, err
+		}
+
+		plans, err := equinixClient.ListPlans(ctx, string(credentials.ProjectID))
+		if err != nil {
+			return nil, fmt.Errorf("could not list plans: %w", err)
+		}
+		w.plans = append([]metalv1.Plan{}, plans...)
+	}
+
+	for i := range w.plans {
+		if w.plans[i].GetSlug() == slug {
+			return &w.plans[i], nil
+		}
+	}
+	return nil, fmt.Errorf("plan %q not found", slug)
+}
+
+// planCapacity returns the capacity of the devices of the given plan. The plans only describe the physical cores of
+// their CPUs, hence, the returned number of CPUs is a lower bound of the number of logical CPUs of the nodes. The
+// ephemeral storage is the size of a single boot drive.
+func planCapacity(plan *metalv1.Plan) (corev1.ResourceList, error) {
+	specs := plan.GetSpecs()
+
+	var cpus int64
+	for _, cpu := range specs.Cpus {
+		cores := int64(1)
+		if match := cpuCoresPattern.FindStringSubmatch(cpu.GetType()); match != nil {
+			parsed, err := strconv.ParseInt(match[1], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse cores of CPU type %q: %w", cpu.GetType(), err)
+			}
+			cores = parsed
+		}
+		cpus += int64(cpu.GetCount()) * cores
+	}
+	if cpus == 0 {
+		return nil, errors.New("no CPUs specified")
+	}
+
+	memorySpec := specs.GetMemory()
+	memory, err := parsePlanSize(memorySpec.GetTotal())
+	if err != nil {
+		return nil, fmt.Errorf("could not parse memory: %w", err)
+	}
+
+	capacity := corev1.ResourceList{
+		corev1.ResourceCPU:    *resource.NewQuantity(cpus, resource.DecimalSI),
+		corev1.ResourceMemory: memory,
+	}
+
+	for _, drive := range specs.Drives {
+		if drive.GetCategory() != planDriveCategoryBoot {
+			continue
+		}
+		size, err := parsePlanSize(drive.GetSize())
+		if err != nil {
+			return nil, fmt.Errorf("could not parse size of boot drive: %w", err)
+		}
+		capacity[corev1.ResourceEphemeralStorage] = size
+		break
+	}
+
+	if gpus := planGPUs(specs); gpus > 0 {
+		capacity[resourceGPU] = *resource.NewQuantity(gpus, resource.DecimalSI)
+	}
+
+	return capacity, nil
+}
+
+// parsePlanSize parses the sizes used by the plans, e.g. "32GB" or "3.8TB". They are interpreted as decimal units to
+// not overestimate the capacity of the devices.
+func parsePlanSize(size string) (resource.Quantity, error) {
+	if size == "" {
+		return resource.Quantity{}, errors.New("no size specified")
+	}
+	return resource.ParseQuantity(strings.TrimSuffix(size, "B"))
+}
+
+// planGPUs returns the number of GPUs of a plan. They are not part of the API schema, but GPU plans list them like the
+// CPUs, e.g. "gpu": [{"count": 2, "type": "NVIDIA A100"}].
+func planGPUs(specs metalv1.PlanSpecs) int64 {
+	gpus, ok := specs.AdditionalProperties["gpu"].([]interface{})
+	if !ok {
+		return 0
+	}
+
+	var count int64
+	for _, gpu := range gpus {
+		if spec, ok := gpu.(map[string]interface{}); ok {
+			if c, ok := spec["count"].(float64); ok {
+				count += int64(c)
+			}
+		}
+	}
+	return count
+}
@@ -0,0 +1,333 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/equinix/equinix-sdk-go/services/metalv1"
+	extensionsconfig "github.com/gardener/gardener/extensions/pkg/apis/config/v1alpha1"
+	"github.com/gardener/gardener/extensions/pkg/controller/worker"
+	"github.com/gardener/gardener/extensions/pkg/util"
+	machinev1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/clock"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal"
+	eqxcmclient "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal/client"
+)
+
+const (
+	// ReinstallControllerName is the name of the controller reinstalling the devices of machines for in-place updates.
+	ReinstallControllerName = "machine-reinstall"
+
+	// machineClassProvider is the provider of the machine classes created by the worker controller.
+	machineClassProvider = "EquinixMetal"
+	// reinstallPollInterval is the interval in which the nodes of reinstalled devices are checked.
+	reinstallPollInterval = 30 * time.Second
+	// reinstallTimeout is the time after which a reinstall is considered failed if the node did not rejoin the cluster.
+	reinstallTimeout = time.Hour
+)
+
+// reinstallProviderSpec contains the fields of the provider spec of a machine class which are used to reinstall a
+// device.
+type reinstallProviderSpec struct {
+	OS            string `json:"OS"`
+	IPXEScriptURL string `json:"ipxeScriptUrl,omitempty"`
+	IPXEScript    string `json:"ipxeScript,omitempty"`
+}
+
+// reinstallReconciler performs in-place updates of machines by reinstalling their devices. Machine-controller-manager
+// drains and cordons the node of a machine before it marks the machine ready for the update. If the device does not
+// run the machine image of the machine deployment, it is reinstalled with it, which keeps the device ID and hence its
+// hardware reservation. Once the node rejoined the cluster, or the reinstall failed, the result is reported to
+// machine-controller-manager with the update result label of the node. All other in-place updates are left to
+// gardener-node-agent.
+type reinstallReconciler struct {
+	client         client.Client
+	newClient      eqxcmclient.NewClientFunc
+	newShootClient func(ctx context.Context, namespace string) (client.Client, error)
+	recorder       record.EventRecorder
+	clock          clock.Clock
+}
+
+// addReinstallControllerToManager adds the controller reinstalling the devices of machines to the given manager.
+func addReinstallControllerToManager(mgr manager.Manager, opts AddOptions) error {
+	r := &reinstallReconciler{
+		client:    mgr.GetClient(),
+		newClient: eqxcmclient.NewClient,
+		newShootClient: func(ctx context.Context, namespace string) (client.Client, error) {
+			_, shootClient, err := util.NewClientForShoot(ctx, mgr.GetClient(), namespace, client.Options{}, extensionsconfig.RESTOptions{})
+			return shootClient, err
+		},
+		recorder: mgr.GetEventRecorderFor(equinixmetal.Name),
+		clock:    clock.RealClock{},
+	}
+
+	return builder.
+		ControllerManagedBy(mgr).
+		Named(ReinstallControllerName).
+		WithOptions(opts.Controller).
+		For(&machinev1alpha1.Machine{}, builder.WithPredicates(predicate.NewPredicateFuncs(func(obj client.Object) bool {
+			machine, ok := obj.(*machinev1alpha1.Machine)
+			return ok && (isReadyForReinstall(machine) || isReinstalling(machine))
+		}))).
+		Complete(r)
+}
+
+// Reconcile reinstalls the device of a machine which is ready for an in-place update and reports the result once the
+// node rejoined the cluster.
+func (r *reinstallReconciler) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	machine := &machinev1alpha1.Machine{}
+	if err := r.client.Get(ctx, request.NamespacedName, machine); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !isReadyForReinstall(machine) && !isReinstalling(machine) {
+		return reconcile.Result{}, nil
+	}
+
+	nodeName := machine.Labels[machinev1alpha1.NodeLabelKey]
+	if nodeName == "" {
+		return reconcile.Result{}, nil
+	}
+
+	shootClient, err := r.newShootClient(ctx, machine.Namespace)
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("could not create shoot client: %w", err)
+	}
+
+	node := &corev1.Node{}
+	if err := shootClient.Get(ctx, client.ObjectKey{Name: nodeName}, node); err != nil {
+		return reconcile.Result{}, fmt.Errorf("could not get node %s: %w", nodeName, err)
+	}
+
+	if node.Labels[machinev1alpha1.LabelKeyNodeUpdateResult] != "" {
+		return reconcile.Result{}, r.removeReinstallAnnotations(ctx, machine)
+	}
+
+	if isReinstalling(machine) {
+		return r.checkReinstall(ctx, shootClient, machine, node)
+	}
+
+	return r.reinstall(ctx, shootClient, machine, node)
+}
+
+// reinstall reinstalls the device of the given machine with the machine image of its machine deployment.
+func (r *reinstallReconciler) reinstall(ctx context.Context, shootClient client.Client, machine *machinev1alpha1.Machine, node *corev1.Node) (reconcile.Result, error) {
+	machineClass, err := r.getDesiredMachineClass(ctx, machine)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if machineClass == nil || machineClass.Provider != machineClassProvider || machineClass.ProviderSpec.Raw == nil {
+		return reconcile.Result{}, nil
+	}
+
+	providerSpec := &reinstallProviderSpec{}
+	if err := json.Unmarshal(machineClass.ProviderSpec.Raw, providerSpec); err != nil {
+		return reconcile.Result{}, fmt.Errorf("could not decode provider spec of machine class %s: %w", machineClass.Name, err)
+	}
+
+	deviceID, err := deviceIDFromProviderID(machine.Spec.ProviderID)
+	if deviceID == "" || err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if machineClass.CredentialsSecretRef == nil || machineClass.SecretRef == nil {
+		return reconcile.Result{}, fmt.Errorf("machine class %s does not reference its secrets", machineClass.Name)
+	}
+
+	credentials, err := equinixmetal.GetCredentialsFromSecretRef(ctx, r.client, *machineClass.CredentialsSecretRef)
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("could not get credentials from secret: %w", err)
+	}
+
+	equinixClient, err := r.newClient(string(credentials.APIToken))
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	device, err := equinixClient.GetDevice(ctx, deviceID)
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("could not get device of machine %s: %w", machine.Name, err)
+	}
+
+	if !needsReinstall(device, providerSpec) {
+		return reconcile.Result{}, nil
+	}
+
+	if providerSpec.IPXEScript != "" {
+		return reconcile.Result{}, r.reportUpdateResult(ctx, shootClient, machine, node, machinev1alpha1.LabelValueNodeUpdateFailed,
+			"devices with an inline iPXE script cannot be reinstalled, use an iPXE script URL instead")
+	}
+
+	userDataSecret := &corev1.Secret{}
+	if err := r.client.Get(ctx, client.ObjectKey{Namespace: machineClass.SecretRef.Namespace, Name: machineClass.SecretRef.Name}, userDataSecret); err != nil {
+		return reconcile.Result{}, fmt.Errorf("could not get user data secret of machine class %s: %w", machineClass.Name, err)
+	}
+
+	// The annotations are added before the reinstall is triggered to never reinstall a device twice.
+	patch := client.MergeFrom(machine.DeepCopy())
+	metav1.SetMetaDataAnnotation(&machine.ObjectMeta, equinixmetal.ReinstallStartedAtAnnotation, r.clock.Now().UTC().Format(time.RFC3339))
+	metav1.SetMetaDataAnnotation(&machine.ObjectMeta, equinixmetal.ReinstallBootIDAnnotation, node.Status.NodeInfo.BootID)
+	if err := r.client.Patch(ctx, machine, patch); err != nil {
+		return reconcile.Result{}, fmt.Errorf("could not annotate machine %s: %w", machine.Name, err)
+	}
+
+	if err := reinstallDevice(ctx, equinixClient, deviceID, providerSpec, string(userDataSecret.Data["userData"])); err != nil {
+		if removeErr := r.removeReinstallAnnotations(ctx, machine); removeErr != nil {
+			return reconcile.Result{}, removeErr
+		}
+		return reconcile.Result{}, fmt.Errorf("could not reinstall device of machine %s: %w", machine.Name, err)
+	}
+
+	r.recorder.Eventf(machine, corev1.EventTypeNormal, "DeviceReinstalling",
+		"Reinstalling device %s with operating system %q", deviceID, providerSpec.OS)
+
+	return reconcile.Result{RequeueAfter: reinstallPollInterval}, nil
+}
+
+// checkReinstall reports the result of the reinstall of the device of the given machine once its node rejoined the
+// cluster or the reinstall timed out.
+func (r *reinstallReconciler) checkReinstall(ctx context.Context, shootClient client.Client, machine *machinev1alpha1.Machine, node *corev1.Node) (reconcile.Result, error) {
+	if nodeRejoined(node, machine.Annotations[equinixmetal.ReinstallBootIDAnnotation]) {
+		r.recorder.Event(machine, corev1.EventTypeNormal, "DeviceReinstalled", "Node rejoined the cluster after reinstalling the device")
+		return reconcile.Result{}, r.reportUpdateResult(ctx, shootClient, machine, node, machinev1alpha1.LabelValueNodeUpdateSuccessful, "")
+	}
+
+	startedAt, err := time.Parse(time.RFC3339, machine.Annotations[equinixmetal.ReinstallStartedAtAnnotation])
+	if err != nil || r.clock.Since(startedAt) > reinstallTimeout {
+		reason := fmt.Sprintf("node did not rejoin the cluster within %s after reinstalling the device", reinstallTimeout)
+		r.recorder.Event(machine, corev1.EventTypeWarning, "DeviceReinstallFailed", reason)
+		return reconcile.Result{}, r.reportUpdateResult(ctx, shootClient, machine, node, machinev1alpha1.LabelValueNodeUpdateFailed, reason)
+	}
+
+	return reconcile.Result{RequeueAfter: reinstallPollInterval}, nil
+}
+
+// getDesiredMachineClass returns the machine class of the machine deployment the given machine belongs to. The machine
+// itself still references the machine class it was created with while it is updated in-place.
+func (r *reinstallReconciler) getDesiredMachineClass(ctx context.Context, machine *machinev1alpha1.Machine) (*machinev1alpha1.MachineClass, error) {
+	machineSetRef := metav1.GetControllerOf(machine)
+	if machineSetRef == nil || machineSetRef.Kind != "MachineSet" {
+		return nil, nil
+	}
+
+	machineSet := &machinev1alpha1.MachineSet{}
+	if err := r.client.Get(ctx, client.ObjectKey{Namespace: machine.Namespace, Name: machineSetRef.Name}, machineSet); err != nil {
+		return nil, fmt.Errorf("could not get machine set of machine %s: %w", machine.Name, err)
+	}
+
+	machineDeploymentRef := metav1.GetControllerOf(machineSet)
+	if machineDeploymentRef == nil || machineDeploymentRef.Kind != "MachineDeployment" {
+		return nil, nil
+	}
+
+	machineDeployment := &machinev1alpha1.MachineDeployment{}
+	if err := r.client.Get(ctx, client.ObjectKey{Namespace: machine.Namespace, Name: machineDeploymentRef.Name}, machineDeployment); err != nil {
+		return nil, fmt.Errorf("could not get machine deployment of machine %s: %w", machine.Name, err)
+	}
+
+	machineClass := &machinev1alpha1.MachineClass{}
+	if err := r.client.Get(ctx, client.ObjectKey{Namespace: machine.Namespace, Name: machineDeployment.Spec.Template.Spec.Class.Name}, machineClass); err != nil {
+		return nil, fmt.Errorf("could not get machine class of machine deployment %s: %w", machineDeployment.Name, err)
+	}
+
+	return machineClass, nil
+}
+
+// reportUpdateResult reports the result of the in-place update to machine-controller-manager and removes the
+// reinstall annotations of the machine.
+func (r *reinstallReconciler) reportUpdateResult(ctx context.Context, shootClient client.Client, machine *machinev1alpha1.Machine, node *corev1.Node, result, reason string) error {
+	patch := client.MergeFrom(node.DeepCopy())
+	metav1.SetMetaDataLabel(&node.ObjectMeta, machinev1alpha1.LabelKeyNodeUpdateResult, result)
+	if reason != "" {
+		metav1.SetMetaDataAnnotation(&node.ObjectMeta, machinev1alpha1.AnnotationKeyMachineUpdateFailedReason, reason)
+	}
+	if err := shootClient.Patch(ctx, node, patch); err != nil {
+		return fmt.Errorf("could not report update result of node %s: %w", node.Name, err)
+	}
+
+	return r.removeReinstallAnnotations(ctx, machine)
+}
+
+// removeReinstallAnnotations removes the reinstall annotations of the given machine.
+func (r *reinstallReconciler) removeReinstallAnnotations(ctx context.Context, machine *machinev1alpha1.Machine) error {
+	if !isReinstalling(machine) {
+		return nil
+	}
+
+	patch := client.MergeFrom(machine.DeepCopy())
+	delete(machine.Annotations, equinixmetal.ReinstallStartedAtAnnotation)
+	delete(machine.Annotations, equinixmetal.ReinstallBootIDAnnotation)
+	if err := r.client.Patch(ctx, machine, patch); err != nil {
+		return fmt.Errorf("could not remove reinstall annotations of machine %s: %w", machine.Name, err)
+	}
+	return nil
+}
+
+// reinstallDevice updates the user data of the given device and reinstalls it with the operating system of the given
+// provider spec. The user data is updated as the device was created with a bootstrap token which may have expired.
+func reinstallDevice(ctx context.Context, equinixClient eqxcmclient.ClientInterface, deviceID string, providerSpec *reinstallProviderSpec, userData string) error {
+	if _, err := equinixClient.UpdateDevice(ctx, deviceID, metalv1.DeviceUpdateInput{Userdata: &userData}); err != nil {
+		return fmt.Errorf("could not update user data: %w", err)
+	}
+
+	input := metalv1.DeviceActionInput{
+		Type:            metalv1.DEVICEACTIONINPUTTYPE_REINSTALL,
+		OperatingSystem: &providerSpec.OS,
+	}
+	if providerSpec.IPXEScriptURL != "" {
+		input.IpxeScriptUrl = &providerSpec.IPXEScriptURL
+	}
+
+	return equinixClient.PerformDeviceAction(ctx, deviceID, input)
+}
+
+// needsReinstall returns true if the given device does not run the operating system of the given provider spec.
+func needsReinstall(device *metalv1.Device, providerSpec *reinstallProviderSpec) bool {
+	operatingSystem := device.GetOperatingSystem()
+	if operatingSystem.GetSlug() != providerSpec.OS {
+		return true
+	}
+	return providerSpec.IPXEScriptURL != "" && device.GetIpxeScriptUrl() != providerSpec.IPXEScriptURL
+}
+
+// nodeRejoined returns true if the given node booted again since the reinstall and is ready.
+func nodeRejoined(node *corev1.Node, bootID string) bool {
+	if node.Status.NodeInfo.BootID == "" || node.Status.NodeInfo.BootID == bootID {
+		return false
+	}
+
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// isReadyForReinstall returns true if machine-controller-manager drained the node of the given machine for an
+// in-place update.
+func isReadyForReinstall(machine *machinev1alpha1.Machine) bool {
+	condition := worker.GetMachineCondition(machine, machinev1alpha1.NodeInPlaceUpdate)
+	return condition != nil && condition.Reason == machinev1alpha1.ReadyForUpdate
+}
+
+// isReinstalling returns true if the device of the given machine is being reinstalled.
+func isReinstalling(machine *machinev1alpha1.Machine) bool {
+	_, ok := machine.Annotations[equinixmetal.ReinstallStartedAtAnnotation]
+	return ok
+}
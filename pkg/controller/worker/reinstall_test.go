@@ -0,0 +1,212 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/equinix/equinix-sdk-go/services/metalv1"
+	machinev1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	testclock "k8s.io/utils/clock/testing"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal"
+	eqxcmclient "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal/client"
+	mockeqxcmclient "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal/client/mock"
+)
+
+var _ = Describe("Reinstall", func() {
+	const namespace = "shoot--foo--bar"
+
+	var (
+		ctx  = context.TODO()
+		ctrl *gomock.Controller
+		now  = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+		seedClient    client.Client
+		shootClient   client.Client
+		equinixClient *mockeqxcmclient.MockClientInterface
+		recorder      *record.FakeRecorder
+		r             *reinstallReconciler
+
+		machine *machinev1alpha1.Machine
+		node    *corev1.Node
+		request = reconcile.Request{NamespacedName: client.ObjectKey{Namespace: namespace, Name: "machine"}}
+	)
+
+	BeforeEach(func() {
+		ctrl = gomock.NewController(GinkgoT())
+		equinixClient = mockeqxcmclient.NewMockClientInterface(ctrl)
+		recorder = record.NewFakeRecorder(10)
+
+		seedScheme := runtime.NewScheme()
+		Expect(scheme.AddToScheme(seedScheme)).To(Succeed())
+		Expect(machinev1alpha1.AddToScheme(seedScheme)).To(Succeed())
+
+		machine = &machinev1alpha1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "machine",
+				Namespace: namespace,
+				Labels:    map[string]string{machinev1alpha1.NodeLabelKey: "node"},
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "machine.sapcloud.io/v1alpha1", Kind: "MachineSet", Name: "machine-set", Controller: ptr.To(true)},
+				},
+			},
+			Spec: machinev1alpha1.MachineSpec{ProviderID: "equinixmetal://device-id"},
+			Status: machinev1alpha1.MachineStatus{Conditions: []corev1.NodeCondition{
+				{Type: machinev1alpha1.NodeInPlaceUpdate, Reason: machinev1alpha1.ReadyForUpdate},
+			}},
+		}
+		node = &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node"},
+			Status: corev1.NodeStatus{
+				NodeInfo:   corev1.NodeSystemInfo{BootID: "old-boot-id"},
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+			},
+		}
+
+		seedClient = fakeclient.NewClientBuilder().WithScheme(seedScheme).WithObjects(
+			machine,
+			&machinev1alpha1.MachineSet{ObjectMeta: metav1.ObjectMeta{
+				Name:      "machine-set",
+				Namespace: namespace,
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "machine.sapcloud.io/v1alpha1", Kind: "MachineDeployment", Name: "machine-deployment", Controller: ptr.To(true)},
+				},
+			}},
+			&machinev1alpha1.MachineDeployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "machine-deployment", Namespace: namespace},
+				Spec: machinev1alpha1.MachineDeploymentSpec{Template: machinev1alpha1.MachineTemplateSpec{
+					Spec: machinev1alpha1.MachineSpec{Class: machinev1alpha1.ClassSpec{Kind: "MachineClass", Name: "machine-class"}},
+				}},
+			},
+			&machinev1alpha1.MachineClass{
+				ObjectMeta:           metav1.ObjectMeta{Name: "machine-class", Namespace: namespace},
+				ProviderSpec:         runtime.RawExtension{Raw: []byte(`{"OS":"flatcar_stable","projectID":"project-id"}`)},
+				SecretRef:            &corev1.SecretReference{Name: "machine-class", Namespace: namespace},
+				CredentialsSecretRef: &corev1.SecretReference{Name: "cloudprovider", Namespace: namespace},
+				Provider:             "EquinixMetal",
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "machine-class", Namespace: namespace},
+				Data:       map[string][]byte{"userData": []byte("user-data")},
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "cloudprovider", Namespace: namespace},
+				Data:       map[string][]byte{equinixmetal.APIToken: []byte("token"), equinixmetal.ProjectID: []byte("project-id")},
+			},
+		).Build()
+		shootClient = fakeclient.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(node).Build()
+
+		r = &reinstallReconciler{
+			client: seedClient,
+			newClient: func(string) (eqxcmclient.ClientInterface, error) {
+				return equinixClient, nil
+			},
+			newShootClient: func(context.Context, string) (client.Client, error) {
+				return shootClient, nil
+			},
+			recorder: recorder,
+			clock:    testclock.NewFakeClock(now),
+		}
+	})
+
+	AfterEach(func() {
+		ctrl.Finish()
+	})
+
+	newDevice := func(operatingSystem string) *metalv1.Device {
+		return &metalv1.Device{OperatingSystem: &metalv1.OperatingSystem{Slug: ptr.To(operatingSystem)}}
+	}
+
+	startReinstall := func(startedAt time.Time) {
+		metav1.SetMetaDataAnnotation(&machine.ObjectMeta, equinixmetal.ReinstallStartedAtAnnotation, startedAt.Format(time.RFC3339))
+		metav1.SetMetaDataAnnotation(&machine.ObjectMeta, equinixmetal.ReinstallBootIDAnnotation, "old-boot-id")
+		machine.Status.Conditions = nil
+		Expect(seedClient.Update(ctx, machine)).To(Succeed())
+	}
+
+	It("should ignore machines which are not ready for an in-place update", func() {
+		machine.Status.Conditions[0].Reason = machinev1alpha1.SelectedForUpdate
+		Expect(seedClient.Update(ctx, machine)).To(Succeed())
+
+		Expect(r.Reconcile(ctx, request)).To(Equal(reconcile.Result{}))
+	})
+
+	It("should leave machines whose devices run the desired operating system to gardener-node-agent", func() {
+		equinixClient.EXPECT().GetDevice(ctx, "device-id").Return(newDevice("flatcar_stable"), nil)
+
+		Expect(r.Reconcile(ctx, request)).To(Equal(reconcile.Result{}))
+
+		Expect(seedClient.Get(ctx, request.NamespacedName, machine)).To(Succeed())
+		Expect(machine.Annotations).To(BeEmpty())
+	})
+
+	It("should reinstall the device with the operating system and user data of the machine deployment", func() {
+		gomock.InOrder(
+			equinixClient.EXPECT().GetDevice(ctx, "device-id").Return(newDevice("ubuntu_22_04"), nil),
+			equinixClient.EXPECT().UpdateDevice(ctx, "device-id", metalv1.DeviceUpdateInput{Userdata: ptr.To("user-data")}),
+			equinixClient.EXPECT().PerformDeviceAction(ctx, "device-id", metalv1.DeviceActionInput{
+				Type:            metalv1.DEVICEACTIONINPUTTYPE_REINSTALL,
+				OperatingSystem: ptr.To("flatcar_stable"),
+			}),
+		)
+
+		Expect(r.Reconcile(ctx, request)).To(Equal(reconcile.Result{RequeueAfter: reinstallPollInterval}))
+
+		Expect(seedClient.Get(ctx, request.NamespacedName, machine)).To(Succeed())
+		Expect(machine.Annotations).To(Equal(map[string]string{
+			equinixmetal.ReinstallStartedAtAnnotation: "2024-01-01T12:00:00Z",
+			equinixmetal.ReinstallBootIDAnnotation:    "old-boot-id",
+		}))
+		Expect(recorder.Events).To(Receive(ContainSubstring(`Reinstalling device device-id with operating system "flatcar_stable"`)))
+	})
+
+	It("should wait until the node rejoined the cluster", func() {
+		startReinstall(now.Add(-10 * time.Minute))
+
+		Expect(r.Reconcile(ctx, request)).To(Equal(reconcile.Result{RequeueAfter: reinstallPollInterval}))
+
+		Expect(shootClient.Get(ctx, client.ObjectKeyFromObject(node), node)).To(Succeed())
+		Expect(node.Labels).NotTo(HaveKey(machinev1alpha1.LabelKeyNodeUpdateResult))
+	})
+
+	It("should report a successful update once the node rejoined the cluster", func() {
+		startReinstall(now.Add(-10 * time.Minute))
+		node.Status.NodeInfo.BootID = "new-boot-id"
+		Expect(shootClient.Status().Update(ctx, node)).To(Succeed())
+
+		Expect(r.Reconcile(ctx, request)).To(Equal(reconcile.Result{}))
+
+		Expect(shootClient.Get(ctx, client.ObjectKeyFromObject(node), node)).To(Succeed())
+		Expect(node.Labels).To(HaveKeyWithValue(machinev1alpha1.LabelKeyNodeUpdateResult, machinev1alpha1.LabelValueNodeUpdateSuccessful))
+		Expect(seedClient.Get(ctx, request.NamespacedName, machine)).To(Succeed())
+		Expect(machine.Annotations).To(BeEmpty())
+	})
+
+	It("should report a failed update if the node did not rejoin the cluster in time", func() {
+		startReinstall(now.Add(-2 * time.Hour))
+
+		Expect(r.Reconcile(ctx, request)).To(Equal(reconcile.Result{}))
+
+		Expect(shootClient.Get(ctx, client.ObjectKeyFromObject(node), node)).To(Succeed())
+		Expect(node.Labels).To(HaveKeyWithValue(machinev1alpha1.LabelKeyNodeUpdateResult, machinev1alpha1.LabelValueNodeUpdateFailed))
+		Expect(node.Annotations).To(HaveKeyWithValue(machinev1alpha1.AnnotationKeyMachineUpdateFailedReason, "node did not rejoin the cluster within 1h0m0s after reinstalling the device"))
+		Expect(seedClient.Get(ctx, request.NamespacedName, machine)).To(Succeed())
+		Expect(machine.Annotations).To(BeEmpty())
+	})
+})
@@ -0,0 +1,312 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/equinix/equinix-sdk-go/services/metalv1"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	machinev1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal"
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal"
+	eqxcmclient "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal/client"
+)
+
+// onDemandOverflowSuffix is the suffix of the names of the overflow machine deployments for on-demand devices.
+const onDemandOverflowSuffix = "-od"
+
+// poolReservations contains the hardware reservations of a worker pool.
+type poolReservations struct {
+	ids                 []string
+	reservedDevicesOnly bool
+}
+
+// listHardwareReservations returns the hardware reservations of the project. They are only fetched once per
+// reconciliation.
+func (w *workerDelegate) listHardwareReservations(ctx context.Context, credentials *equinixmetal.Credentials) ([]metalv1.HardwareReservation, error) {
+	if w.hardwareReservations != nil {
+		return w.hardwareReservations, nil
+	}
+
+	equinixClient, err := w.newClient(string(credentials.APIToken))
+	if err != nil {
+		return nil, err
+	}
+
+	reservations, err := equinixClient.ListHardwareReservations(ctx, string(credentials.ProjectID))
+	if err != nil {
+		return nil, fmt.Errorf("could not list hardware reservations: %w", err)
+	}
+
+	w.hardwareReservations = append([]metalv1.HardwareReservation{}, reservations...)
+	return w.hardwareReservations, nil
+}
+
+// selectReservationIDs returns the sorted IDs of the hardware reservations matching the given selector. Spare
+// reservations are never selected.
+func selectReservationIDs(reservations []metalv1.HardwareReservation, selector *api.ReservationSelector, machineType string) []string {
+	plans := selector.Plans
+	if len(plans) == 0 {
+		plans = []string{machineType}
+	}
+
+	ids := sets.New[string]()
+	for _, reservation := range reservations {
+		if reservation.GetSpare() || reservation.GetId() == "" {
+			continue
+		}
+
+		var (
+			plan     = reservation.GetPlan()
+			facility = reservation.GetFacility()
+			metro    = facility.GetMetro()
+		)
+
+		if !slices.Contains(plans, plan.GetSlug()) ||
+			(len(selector.Facilities) > 0 && !slices.Contains(selector.Facilities, facility.GetCode())) ||
+			(len(selector.Metros) > 0 && !slices.Contains(selector.Metros, metro.GetCode())) ||
+			(len(selector.Tags) > 0 && !sets.New(reservationTags(reservation)...).HasAny(selector.Tags...)) {
+			continue
+		}
+
+		ids.Insert(reservation.GetId())
+	}
+
+	return sets.List(ids)
+}
+
+// countReservations returns the number of the hardware reservations with the given IDs which are located in one of
+// the given facilities, or in any facility if none are given, and the number of those which are not used by a device.
+// Spare reservations are not counted.
+func countReservations(hardwareReservations []metalv1.HardwareReservation, reservationIDs []string, facilities []string) (total, free int32) {
+	for _, reservation := range hardwareReservations {
+		facility := reservation.GetFacility()
+		if reservation.GetSpare() || !slices.Contains(reservationIDs, reservation.GetId()) ||
+			(len(facilities) > 0 && !slices.Contains(facilities, facility.GetCode())) {
+			continue
+		}
+		total++
+		if reservation.Device == nil {
+			free++
+		}
+	}
+	return total, free
+}
+
+// splitOnDemandOverflow splits the given machine deployment into a machine deployment which only uses hardware
+// reservations and is sized to the given number of reservations, and an overflow machine deployment for on-demand
+// devices. The overflow machine deployment has a lower priority, so that the cluster-autoscaler prefers the reserved
+// devices.
+func splitOnDemandOverflow(deployment zoneMachineDeployment, reservations int32) []zoneMachineDeployment {
+	reserved := deployment
+	reserved.classSpec = maps.Clone(deployment.classSpec)
+	reserved.classSpec["reservedDevicesOnly"] = true
+	reserved.minimum = min(deployment.minimum, reservations)
+	reserved.maximum = min(deployment.maximum, reservations)
+
+	overflow := deployment
+	overflow.name += onDemandOverflowSuffix
+	overflow.classSpec = maps.Clone(deployment.classSpec)
+	delete(overflow.classSpec, "reservationIDs")
+	delete(overflow.classSpec, "reservedDevicesOnly")
+	overflow.minimum = deployment.minimum - reserved.minimum
+	overflow.maximum = deployment.maximum - reserved.maximum
+	overflow.priority = ptr.To(ptr.Deref(deployment.priority, 0) - 1)
+
+	return []zoneMachineDeployment{reserved, overflow}
+}
+
+// reservationAwareUpdateConfiguration adjusts the update configuration of a machine deployment which only uses
+// reserved devices to its free hardware reservations. During a rolling update, the old machines keep their
+// reservations until they are deleted, hence, at most one new machine per free reservation can be created. If the
+// surge exceeds the free reservations, it is reduced to them, and if no reservation is free, old machines must be
+// deleted before new ones are created, i.e., at least one machine may be unavailable.
+func reservationAwareUpdateConfiguration(name string, updateConfiguration machinev1alpha1.UpdateConfiguration, maximum, freeReservations int32) (machinev1alpha1.UpdateConfiguration, api.UpdateStrategyStatus, error) {
+	maxSurge, err := intstr.GetScaledValueFromIntOrPercent(updateConfiguration.MaxSurge, int(maximum), true)
+	if err != nil {
+		return updateConfiguration, api.UpdateStrategyStatus{}, fmt.Errorf("invalid max surge: %w", err)
+	}
+	maxUnavailable, err := intstr.GetScaledValueFromIntOrPercent(updateConfiguration.MaxUnavailable, int(maximum), false)
+	if err != nil {
+		return updateConfiguration, api.UpdateStrategyStatus{}, fmt.Errorf("invalid max unavailable: %w", err)
+	}
+
+	status := api.UpdateStrategyStatus{
+		Name:             name,
+		Type:             api.UpdateStrategyRollingUpdate,
+		FreeReservations: freeReservations,
+		MaxSurge:         min(int32(maxSurge), freeReservations),
+		MaxUnavailable:   int32(maxUnavailable),
+	}
+	if status.MaxSurge == int32(maxSurge) {
+		return updateConfiguration, status, nil
+	}
+
+	if status.MaxSurge == 0 {
+		status.Type = api.UpdateStrategyDeleteBeforeCreate
+		status.MaxUnavailable = max(status.MaxUnavailable, 1)
+	}
+
+	return machinev1alpha1.UpdateConfiguration{
+		MaxSurge:       ptr.To(intstr.FromInt32(status.MaxSurge)),
+		MaxUnavailable: ptr.To(intstr.FromInt32(status.MaxUnavailable)),
+	}, status, nil
+}
+
+// reservationTags returns the custom tags of the given hardware reservation. They are not part of the SDK model,
+// hence, they are read from the additional properties.
+func reservationTags(reservation metalv1.HardwareReservation) []string {
+	rawTags, ok := reservation.AdditionalProperties["tags"].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var tags []string
+	for _, rawTag := range rawTags {
+		if tag, ok := rawTag.(string); ok {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// reportReservations reports the usage of the hardware reservations of the worker pools in the worker provider status.
+// A warning event is emitted for worker pools which only use reserved devices but have no free hardware reservations
+// left for the desired replicas of their machine deployments.
+func (w *workerDelegate) reportReservations(ctx context.Context, equinixClient eqxcmclient.ClientInterface, projectID string, nodes []corev1.Node) error {
+	if len(w.poolReservations) == 0 {
+		return nil
+	}
+
+	hardwareReservations, err := equinixClient.ListHardwareReservations(ctx, projectID)
+	if err != nil {
+		return fmt.Errorf("could not list hardware reservations: %w", err)
+	}
+
+	devicesByPool := map[string][]string{}
+	for _, node := range nodes {
+		deviceID, err := deviceIDFromProviderID(node.Spec.ProviderID)
+		if deviceID == "" || err != nil {
+			continue
+		}
+		pool := node.Labels[v1beta1constants.LabelWorkerPool]
+		devicesByPool[pool] = append(devicesByPool[pool], deviceID)
+	}
+
+	for i := range w.workerPools {
+		workerPool := &w.workerPools[i]
+		reservations, ok := w.poolReservations[workerPool.Name]
+		if !ok {
+			continue
+		}
+
+		workerPool.Reservations = newReservationReport(reservations.ids, hardwareReservations, devicesByPool[workerPool.Name])
+
+		if !reservations.reservedDevicesOnly || len(workerPool.Reservations.Free) > 0 {
+			continue
+		}
+
+		replicas, err := w.desiredReplicas(ctx, workerPool.Name)
+		if err != nil {
+			return err
+		}
+		if replicas > int32(len(workerPool.Reservations.InUse)) {
+			w.recorder.Eventf(w.worker, corev1.EventTypeWarning, "HardwareReservationsExhausted",
+				"Worker pool %q only uses reserved devices but has no free hardware reservations left for %d desired replicas",
+				workerPool.Name, replicas)
+		}
+	}
+
+	workerStatus, err := w.decodeWorkerProviderStatus()
+	if err != nil {
+		return fmt.Errorf("unable to decode the worker provider status: %w", err)
+	}
+
+	workerStatus.WorkerPools = w.workerPools
+	return w.updateWorkerProviderStatus(ctx, workerStatus)
+}
+
+// desiredReplicas returns the sum of the replicas of the machine deployments of the given worker pool.
+func (w *workerDelegate) desiredReplicas(ctx context.Context, pool string) (int32, error) {
+	var replicas int32
+	for _, deployment := range w.machineDeployments {
+		if deployment.PoolName != pool {
+			continue
+		}
+
+		machineDeployment := &machinev1alpha1.MachineDeployment{}
+		if err := w.client.Get(ctx, client.ObjectKey{Namespace: w.worker.Namespace, Name: deployment.Name}, machineDeployment); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return 0, fmt.Errorf("could not get machine deployment %s: %w", deployment.Name, err)
+		}
+		replicas += machineDeployment.Spec.Replicas
+	}
+	return replicas, nil
+}
+
+// newReservationReport returns the usage report of the hardware reservations with the given IDs for the given devices
+// of a worker pool. Only the hardware reservations which exist are counted, spare ones only if they are in use by the
+// worker pool.
+func newReservationReport(reservationIDs []string, hardwareReservations []metalv1.HardwareReservation, deviceIDs []string) *api.ReservationReport {
+	var (
+		report          = &api.ReservationReport{}
+		poolReservation = sets.New(reservationIDs...)
+		poolDevices     = sets.New(deviceIDs...)
+		reservedDevices = sets.New[string]()
+	)
+
+	for _, reservation := range hardwareReservations {
+		device := reservation.GetDevice()
+		if device.GetId() != "" {
+			reservedDevices.Insert(device.GetId())
+		}
+
+		if !poolReservation.Has(reservation.GetId()) {
+			continue
+		}
+
+		switch {
+		case poolDevices.Has(device.GetId()):
+			report.InUse = append(report.InUse, reservation.GetId())
+		case reservation.GetSpare():
+			continue
+		case device.GetId() == "":
+			report.Free = append(report.Free, reservation.GetId())
+		}
+		report.Total++
+	}
+
+	slices.Sort(report.InUse)
+	slices.Sort(report.Free)
+	report.OnDemandDevices = int32(poolDevices.Difference(reservedDevices).Len())
+
+	return report
+}
+
+// mergeReservationReports returns the given worker pool statuses with the reservation reports of the given previous
+// worker pool statuses. The reports are only refreshed after the machines have been reconciled.
+func mergeReservationReports(workerPools, previousWorkerPools []api.WorkerPoolStatus) []api.WorkerPoolStatus {
+	for i := range workerPools {
+		for _, previous := range previousWorkerPools {
+			if previous.Name == workerPools[i].Name && workerPools[i].Reservations == nil {
+				workerPools[i].Reservations = previous.Reservations
+			}
+		}
+	}
+	return workerPools
+}
@@ -0,0 +1,366 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/equinix/equinix-sdk-go/services/metalv1"
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	mockclient "github.com/gardener/gardener/third_party/mock/controller-runtime/client"
+	machinev1alpha1 "github.com/gardener/machine-controller-manager/pkg/apis/machine/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gstruct"
+	"go.uber.org/mock/gomock"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	api "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal"
+	apiv1alpha1 "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal/v1alpha1"
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal"
+	eqxcmclient "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal/client"
+	mockeqxcmclient "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal/client/mock"
+)
+
+var _ = Describe("Reservations", func() {
+	newReservation := func(id, deviceID string, spare bool) metalv1.HardwareReservation {
+		reservation := metalv1.HardwareReservation{Id: ptr.To(id), Spare: ptr.To(spare)}
+		if deviceID != "" {
+			reservation.Device = &metalv1.Device{Id: ptr.To(deviceID)}
+		}
+		return reservation
+	}
+
+	newNode := func(pool, deviceID string) corev1.Node {
+		return corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"worker.gardener.cloud/pool": pool}},
+			Spec:       corev1.NodeSpec{ProviderID: "equinixmetal://" + deviceID},
+		}
+	}
+
+	hardwareReservations := []metalv1.HardwareReservation{
+		newReservation("in-use", "device-1", false),
+		newReservation("free", "", false),
+		newReservation("spare", "", true),
+		newReservation("foreign", "device-other", false),
+		newReservation("other-pool", "device-3", false),
+	}
+
+	Describe("#newReservationReport", func() {
+		It("should report the usage of the hardware reservations", func() {
+			Expect(newReservationReport(
+				[]string{"in-use", "free", "spare", "foreign", "unknown"},
+				hardwareReservations,
+				[]string{"device-1", "device-2"},
+			)).To(Equal(&api.ReservationReport{
+				Total:           3,
+				InUse:           []string{"in-use"},
+				Free:            []string{"free"},
+				OnDemandDevices: 1,
+			}))
+		})
+	})
+
+	Describe("#mergeReservationReports", func() {
+		It("should keep the previous reports of worker pools without a report", func() {
+			previousReport := &api.ReservationReport{Total: 1}
+			newReport := &api.ReservationReport{Total: 2}
+
+			Expect(mergeReservationReports(
+				[]api.WorkerPoolStatus{{Name: "pool-1"}, {Name: "pool-2", Reservations: newReport}, {Name: "pool-3"}},
+				[]api.WorkerPoolStatus{{Name: "pool-1", Reservations: previousReport}, {Name: "pool-2", Reservations: previousReport}},
+			)).To(Equal([]api.WorkerPoolStatus{
+				{Name: "pool-1", Reservations: previousReport},
+				{Name: "pool-2", Reservations: newReport},
+				{Name: "pool-3"},
+			}))
+		})
+	})
+
+	Describe("#countReservations", func() {
+		It("should count the non-spare and free hardware reservations of the worker pool in the given facilities", func() {
+			reservations := []metalv1.HardwareReservation{
+				{Id: ptr.To("ny5-1"), Spare: ptr.To(false), Facility: &metalv1.Facility{Code: ptr.To("ny5")}, Device: &metalv1.Device{Id: ptr.To("device-1")}},
+				{Id: ptr.To("ny5-2"), Spare: ptr.To(false), Facility: &metalv1.Facility{Code: ptr.To("ny5")}},
+				{Id: ptr.To("ny5-spare"), Spare: ptr.To(true), Facility: &metalv1.Facility{Code: ptr.To("ny5")}},
+				{Id: ptr.To("ny7-1"), Spare: ptr.To(false), Facility: &metalv1.Facility{Code: ptr.To("ny7")}},
+				{Id: ptr.To("foreign"), Spare: ptr.To(false), Facility: &metalv1.Facility{Code: ptr.To("ny5")}},
+			}
+			reservationIDs := []string{"ny5-1", "ny5-2", "ny5-spare", "ny7-1"}
+
+			total, free := countReservations(reservations, reservationIDs, []string{"ny5"})
+			Expect(total).To(Equal(int32(2)))
+			Expect(free).To(Equal(int32(1)))
+
+			total, free = countReservations(reservations, reservationIDs, nil)
+			Expect(total).To(Equal(int32(3)))
+			Expect(free).To(Equal(int32(2)))
+		})
+	})
+
+	Describe("#splitOnDemandOverflow", func() {
+		deployment := zoneMachineDeployment{
+			name:      "pool-z1",
+			classSpec: map[string]interface{}{"machineType": "m3.large.x86", "reservationIDs": []string{"foo", "bar"}},
+			minimum:   1,
+			maximum:   5,
+			priority:  ptr.To[int32](10),
+		}
+
+		It("should split the machine deployment into a reserved and an on-demand overflow machine deployment", func() {
+			Expect(splitOnDemandOverflow(deployment, 2)).To(Equal([]zoneMachineDeployment{
+				{
+					name:      "pool-z1",
+					classSpec: map[string]interface{}{"machineType": "m3.large.x86", "reservationIDs": []string{"foo", "bar"}, "reservedDevicesOnly": true},
+					minimum:   1,
+					maximum:   2,
+					priority:  ptr.To[int32](10),
+				},
+				{
+					name:      "pool-z1-od",
+					classSpec: map[string]interface{}{"machineType": "m3.large.x86"},
+					minimum:   0,
+					maximum:   3,
+					priority:  ptr.To[int32](9),
+				},
+			}))
+			Expect(deployment.classSpec).NotTo(HaveKey("reservedDevicesOnly"))
+		})
+
+		It("should move the minimum to the overflow machine deployment if there are not enough reservations", func() {
+			result := splitOnDemandOverflow(deployment, 0)
+			Expect(result).To(HaveLen(2))
+			Expect(result[0].minimum).To(BeZero())
+			Expect(result[0].maximum).To(BeZero())
+			Expect(result[1].minimum).To(Equal(int32(1)))
+			Expect(result[1].maximum).To(Equal(int32(5)))
+		})
+	})
+
+	Describe("#reservationAwareUpdateConfiguration", func() {
+		updateConfiguration := machinev1alpha1.UpdateConfiguration{
+			MaxSurge:       ptr.To(intstr.FromString("50%")),
+			MaxUnavailable: ptr.To(intstr.FromInt32(0)),
+		}
+
+		It("should keep the update configuration if the surge fits into the free reservations", func() {
+			result, status, err := reservationAwareUpdateConfiguration("pool-z1", updateConfiguration, 4, 2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(updateConfiguration))
+			Expect(status).To(Equal(api.UpdateStrategyStatus{Name: "pool-z1", Type: "RollingUpdate", FreeReservations: 2, MaxSurge: 2}))
+		})
+
+		It("should limit the surge to the free reservations", func() {
+			result, status, err := reservationAwareUpdateConfiguration("pool-z1", updateConfiguration, 4, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.MaxSurge).To(PointTo(Equal(intstr.FromInt32(1))))
+			Expect(result.MaxUnavailable).To(PointTo(Equal(intstr.FromInt32(0))))
+			Expect(status).To(Equal(api.UpdateStrategyStatus{Name: "pool-z1", Type: "RollingUpdate", FreeReservations: 1, MaxSurge: 1}))
+		})
+
+		It("should delete machines before creating new ones if no reservation is free", func() {
+			result, status, err := reservationAwareUpdateConfiguration("pool-z1", updateConfiguration, 4, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.MaxSurge).To(PointTo(Equal(intstr.FromInt32(0))))
+			Expect(result.MaxUnavailable).To(PointTo(Equal(intstr.FromInt32(1))))
+			Expect(status).To(Equal(api.UpdateStrategyStatus{Name: "pool-z1", Type: "DeleteBeforeCreate", MaxUnavailable: 1}))
+		})
+	})
+
+	Describe("#reportReservations", func() {
+		const namespace = "shoot--foo--bar"
+
+		var (
+			ctx  = context.TODO()
+			ctrl *gomock.Controller
+
+			c             *mockclient.MockClient
+			statusWriter  *mockclient.MockStatusWriter
+			equinixClient *mockeqxcmclient.MockClientInterface
+			recorder      *record.FakeRecorder
+			scheme        *runtime.Scheme
+			w             *workerDelegate
+
+			newPool = func(name string, workerConfig *apiv1alpha1.WorkerConfig) extensionsv1alpha1.WorkerPool {
+				workerConfig.TypeMeta = metav1.TypeMeta{APIVersion: apiv1alpha1.SchemeGroupVersion.String(), Kind: "WorkerConfig"}
+				providerConfig, err := json.Marshal(workerConfig)
+				Expect(err).NotTo(HaveOccurred())
+
+				return extensionsv1alpha1.WorkerPool{
+					Name:           name,
+					Minimum:        1,
+					Maximum:        2,
+					MaxSurge:       intstr.FromInt32(1),
+					MaxUnavailable: intstr.FromInt32(0),
+					MachineType:    "m3.small.x86",
+					MachineImage:   extensionsv1alpha1.MachineImage{Name: "flatcar", Version: "1.0.0"},
+					ProviderConfig: &runtime.RawExtension{Raw: providerConfig},
+					UserDataSecretRef: corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "user-data"},
+						Key:                  "cloud_config",
+					},
+				}
+			}
+		)
+
+		BeforeEach(func() {
+			ctrl = gomock.NewController(GinkgoT())
+			c = mockclient.NewMockClient(ctrl)
+			statusWriter = mockclient.NewMockStatusWriter(ctrl)
+			equinixClient = mockeqxcmclient.NewMockClientInterface(ctrl)
+			recorder = record.NewFakeRecorder(10)
+
+			scheme = runtime.NewScheme()
+			Expect(api.AddToScheme(scheme)).To(Succeed())
+			Expect(apiv1alpha1.AddToScheme(scheme)).To(Succeed())
+
+			infrastructureStatus, err := json.Marshal(&apiv1alpha1.InfrastructureStatus{
+				TypeMeta: metav1.TypeMeta{APIVersion: apiv1alpha1.SchemeGroupVersion.String(), Kind: "InfrastructureStatus"},
+				SSHKeyID: "ssh-key-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			w = &workerDelegate{
+				client:   c,
+				decoder:  serializer.NewCodecFactory(scheme, serializer.EnableStrict).UniversalDecoder(),
+				scheme:   scheme,
+				recorder: recorder,
+				newClient: func(string) (eqxcmclient.ClientInterface, error) {
+					return equinixClient, nil
+				},
+				cloudProfileConfig: &api.CloudProfileConfig{
+					MachineImages: []api.MachineImages{{
+						Name:     "flatcar",
+						Versions: []api.MachineImageVersion{{Version: "1.0.0", ID: "flatcar_stable"}},
+					}},
+				},
+				cluster: &extensionscontroller.Cluster{
+					Shoot: &gardencorev1beta1.Shoot{Spec: gardencorev1beta1.ShootSpec{Kubernetes: gardencorev1beta1.Kubernetes{Version: "1.31.1"}}},
+				},
+				worker: &extensionsv1alpha1.Worker{
+					ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: namespace},
+					Spec: extensionsv1alpha1.WorkerSpec{
+						Region:                       "ny",
+						SecretRef:                    corev1.SecretReference{Name: "cloudprovider", Namespace: namespace},
+						InfrastructureProviderStatus: &runtime.RawExtension{Raw: infrastructureStatus},
+						Pools: []extensionsv1alpha1.WorkerPool{
+							newPool("pool-1", &apiv1alpha1.WorkerConfig{ReservationIDs: []string{"in-use", "free"}}),
+							newPool("pool-2", &apiv1alpha1.WorkerConfig{ReservationIDs: []string{"other-pool"}, ReservedDevicesOnly: ptr.To(true)}),
+						},
+					},
+				},
+			}
+		})
+
+		generateMachineConfig := func() {
+			c.EXPECT().Get(ctx, client.ObjectKey{Namespace: namespace, Name: "cloudprovider"}, gomock.AssignableToTypeOf(&corev1.Secret{})).DoAndReturn(
+				func(_ context.Context, _ client.ObjectKey, secret *corev1.Secret, _ ...client.GetOption) error {
+					secret.Data = map[string][]byte{equinixmetal.APIToken: []byte("token"), equinixmetal.ProjectID: []byte("project-id")}
+					return nil
+				})
+			c.EXPECT().Get(ctx, client.ObjectKey{Namespace: namespace, Name: "user-data"}, gomock.AssignableToTypeOf(&corev1.Secret{})).DoAndReturn(
+				func(_ context.Context, _ client.ObjectKey, secret *corev1.Secret, _ ...client.GetOption) error {
+					secret.Data = map[string][]byte{"cloud_config": []byte("user-data")}
+					return nil
+				}).Times(2)
+
+			ExpectWithOffset(1, w.generateMachineConfig(ctx)).To(Succeed())
+		}
+
+		expectMachineDeploymentReplicas := func(name string, replicas int32) {
+			c.EXPECT().Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, gomock.AssignableToTypeOf(&machinev1alpha1.MachineDeployment{})).DoAndReturn(
+				func(_ context.Context, _ client.ObjectKey, machineDeployment *machinev1alpha1.MachineDeployment, _ ...client.GetOption) error {
+					machineDeployment.Spec.Replicas = replicas
+					return nil
+				})
+		}
+
+		AfterEach(func() {
+			ctrl.Finish()
+		})
+
+		It("should do nothing if no worker pool uses hardware reservations", func() {
+			for i := range w.worker.Spec.Pools {
+				w.worker.Spec.Pools[i].ProviderConfig = nil
+			}
+			generateMachineConfig()
+
+			Expect(w.reportReservations(ctx, equinixClient, "project-id", nil)).To(Succeed())
+		})
+
+		It("should report the usage in the worker status and emit an event for exhausted worker pools", func() {
+			equinixClient.EXPECT().ListHardwareReservations(ctx, "project-id").Return(hardwareReservations, nil).Times(2)
+			generateMachineConfig()
+			expectMachineDeploymentReplicas("shoot--foo--bar-pool-2", 2)
+
+			c.EXPECT().Status().Return(statusWriter)
+			statusWriter.EXPECT().Patch(ctx, gomock.AssignableToTypeOf(&extensionsv1alpha1.Worker{}), gomock.Any()).DoAndReturn(
+				func(_ context.Context, worker *extensionsv1alpha1.Worker, _ client.Patch, _ ...client.SubResourcePatchOption) error {
+					Expect(worker.Status.ProviderStatus.Object).To(Equal(&apiv1alpha1.WorkerStatus{
+						TypeMeta: metav1.TypeMeta{
+							APIVersion: apiv1alpha1.SchemeGroupVersion.String(),
+							Kind:       "WorkerStatus",
+						},
+						WorkerPools: []apiv1alpha1.WorkerPoolStatus{
+							{
+								Name: "pool-1",
+								Reservations: &apiv1alpha1.ReservationReport{
+									Total:           2,
+									InUse:           []string{"in-use"},
+									Free:            []string{"free"},
+									OnDemandDevices: 1,
+								},
+							},
+							{
+								Name: "pool-2",
+								Reservations: &apiv1alpha1.ReservationReport{
+									Total: 1,
+									InUse: []string{"other-pool"},
+								},
+								UpdateStrategies: []apiv1alpha1.UpdateStrategyStatus{
+									{Name: "shoot--foo--bar-pool-2", Type: "DeleteBeforeCreate", MaxUnavailable: 1},
+								},
+							},
+						},
+					}))
+					return nil
+				})
+
+			Expect(w.reportReservations(ctx, equinixClient, "project-id", []corev1.Node{
+				newNode("pool-1", "device-1"),
+				newNode("pool-1", "device-2"),
+				newNode("pool-2", "device-3"),
+			})).To(Succeed())
+
+			Expect(recorder.Events).To(Receive(ContainSubstring(`Worker pool "pool-2" only uses reserved devices but has no free hardware reservations left for 2 desired replicas`)))
+			Expect(recorder.Events).NotTo(Receive())
+		})
+
+		It("should not emit an event if the hardware reservations in use suffice for the desired replicas", func() {
+			equinixClient.EXPECT().ListHardwareReservations(ctx, "project-id").Return(hardwareReservations, nil).Times(2)
+			generateMachineConfig()
+			expectMachineDeploymentReplicas("shoot--foo--bar-pool-2", 1)
+
+			c.EXPECT().Status().Return(statusWriter)
+			statusWriter.EXPECT().Patch(ctx, gomock.AssignableToTypeOf(&extensionsv1alpha1.Worker{}), gomock.Any())
+
+			Expect(w.reportReservations(ctx, equinixClient, "project-id", []corev1.Node{
+				newNode("pool-2", "device-3"),
+			})).To(Succeed())
+
+			Expect(recorder.Events).NotTo(Receive())
+		})
+	})
+})
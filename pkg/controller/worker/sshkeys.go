@@ -0,0 +1,89 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/equinix/equinix-sdk-go/services/metalv1"
+	"k8s.io/utils/ptr"
+
+	api "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal"
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal"
+)
+
+// machineSSHKeys returns the IDs of the SSH keys which are added to the devices of a worker pool. Besides the key
+// managed by Gardener, it contains the additional keys of the given configuration. The project keys are verified to
+// belong to the project and the user keys are verified to exist.
+func (w *workerDelegate) machineSSHKeys(ctx context.Context, credentials *equinixmetal.Credentials, gardenerKeyID string, ssh *api.WorkerSSH) ([]string, error) {
+	keyIDs := []string{gardenerKeyID}
+	if ssh == nil {
+		return keyIDs, nil
+	}
+
+	addKeyID := func(id string) {
+		if !slices.Contains(keyIDs, id) {
+			keyIDs = append(keyIDs, id)
+		}
+	}
+
+	if len(ssh.ProjectKeyIDs) > 0 || ptr.Deref(ssh.AllProjectKeys, false) {
+		projectKeys, err := w.listProjectSSHKeys(ctx, credentials)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, id := range ssh.ProjectKeyIDs {
+			if !slices.ContainsFunc(projectKeys, func(key metalv1.SSHKey) bool { return key.GetId() == id }) {
+				return nil, fmt.Errorf("SSH key %q is not a key of the project", id)
+			}
+			addKeyID(id)
+		}
+
+		if ptr.Deref(ssh.AllProjectKeys, false) {
+			for _, key := range projectKeys {
+				addKeyID(key.GetId())
+			}
+		}
+	}
+
+	if len(ssh.UserKeyIDs) > 0 {
+		equinixClient, err := w.newClient(string(credentials.APIToken))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, id := range ssh.UserKeyIDs {
+			if _, err := equinixClient.GetSSHKey(ctx, id); err != nil {
+				return nil, fmt.Errorf("could not get SSH key %q: %w", id, err)
+			}
+			addKeyID(id)
+		}
+	}
+
+	return keyIDs, nil
+}
+
+// listProjectSSHKeys returns the SSH keys of the project. They are only fetched once per reconciliation.
+func (w *workerDelegate) listProjectSSHKeys(ctx context.Context, credentials *equinixmetal.Credentials) ([]metalv1.SSHKey, error) {
+	if w.projectSSHKeys != nil {
+		return w.projectSSHKeys, nil
+	}
+
+	equinixClient, err := w.newClient(string(credentials.APIToken))
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := equinixClient.ListProjectSSHKeys(ctx, string(credentials.ProjectID))
+	if err != nil {
+		return nil, fmt.Errorf("could not list SSH keys of the project: %w", err)
+	}
+
+	w.projectSSHKeys = append([]metalv1.SSHKey{}, keys...)
+	return w.projectSSHKeys, nil
+}
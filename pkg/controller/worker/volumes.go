@@ -0,0 +1,91 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	"slices"
+
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	"k8s.io/utils/ptr"
+
+	api "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal"
+)
+
+// logicalVolumeMountPoints maps the names of the logical volumes to their mount points.
+var logicalVolumeMountPoints = map[string]string{
+	api.LogicalVolumeContainerd: "/var/lib/containerd",
+	api.LogicalVolumeKubelet:    "/var/lib/kubelet",
+	api.LogicalVolumeLog:        "/var/log",
+}
+
+// poolVolumeLayout contains the layout of the local disks of the devices of a worker pool. It is passed to the devices
+// via their custom data and applied by the LVM setup script of the nodes.
+type poolVolumeLayout struct {
+	DiskTypes   []string     `json:"diskTypes,omitempty"`
+	MinDiskSize int64        `json:"minDiskSize,omitempty"`
+	MaxDiskSize int64        `json:"maxDiskSize,omitempty"`
+	RAIDLevel   string       `json:"raidLevel,omitempty"`
+	Filesystem  string       `json:"filesystem"`
+	Volumes     []poolVolume `json:"volumes"`
+}
+
+// poolVolume is a logical volume of a volume layout. A size of 0 uses the remaining space of the volume group.
+type poolVolume struct {
+	Name       string `json:"name"`
+	MountPoint string `json:"mountPoint"`
+	Size       int64  `json:"size,omitempty"`
+}
+
+// newPoolVolumeLayout returns the volume layout of the devices of the given worker pool, or nil if the worker pool
+// uses neither a volume nor a volume layout. Worker pools with a volume but without a volume layout use a single
+// logical volume for containerd on all unused disks.
+func newPoolVolumeLayout(workerConfig *api.WorkerConfig, pool extensionsv1alpha1.WorkerPool) *poolVolumeLayout {
+	layout := workerConfig.VolumeLayout
+	if layout == nil {
+		if pool.Volume == nil {
+			return nil
+		}
+		layout = &api.VolumeLayout{}
+	}
+
+	poolLayout := &poolVolumeLayout{
+		RAIDLevel:  ptr.Deref(layout.RAIDLevel, ""),
+		Filesystem: ptr.Deref(layout.Filesystem, api.VolumeFilesystemExt4),
+	}
+
+	if disks := layout.Disks; disks != nil {
+		poolLayout.DiskTypes = disks.Types
+		if disks.MinSize != nil {
+			poolLayout.MinDiskSize = disks.MinSize.Value()
+		}
+		if disks.MaxSize != nil {
+			poolLayout.MaxDiskSize = disks.MaxSize.Value()
+		}
+	}
+
+	volumes := layout.Volumes
+	if len(volumes) == 0 {
+		volumes = []api.LogicalVolume{{Name: api.LogicalVolumeContainerd}}
+	}
+	for _, volume := range volumes {
+		poolVolume := poolVolume{Name: volume.Name, MountPoint: logicalVolumeMountPoints[volume.Name]}
+		if volume.Size != nil {
+			poolVolume.Size = volume.Size.Value()
+		}
+		poolLayout.Volumes = append(poolLayout.Volumes, poolVolume)
+	}
+	// the logical volume using the remaining space of the volume group must be created last
+	slices.SortStableFunc(poolLayout.Volumes, func(a, b poolVolume) int {
+		switch {
+		case a.Size == 0 && b.Size != 0:
+			return 1
+		case a.Size != 0 && b.Size == 0:
+			return -1
+		}
+		return 0
+	})
+
+	return poolLayout
+}
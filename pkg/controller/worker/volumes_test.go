@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/ptr"
+
+	api "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal"
+)
+
+var _ = Describe("Volumes", func() {
+	Describe("#newPoolVolumeLayout", func() {
+		It("should return nil for worker pools without volume and volume layout", func() {
+			Expect(newPoolVolumeLayout(&api.WorkerConfig{}, extensionsv1alpha1.WorkerPool{})).To(BeNil())
+		})
+
+		It("should use a single containerd volume for worker pools with a volume", func() {
+			pool := extensionsv1alpha1.WorkerPool{Volume: &extensionsv1alpha1.Volume{Size: "50Gi"}}
+
+			Expect(newPoolVolumeLayout(&api.WorkerConfig{}, pool)).To(Equal(&poolVolumeLayout{
+				Filesystem: "ext4",
+				Volumes:    []poolVolume{{Name: "containerd", MountPoint: "/var/lib/containerd"}},
+			}))
+		})
+
+		It("should convert the volume layout and create the volume using the remaining space last", func() {
+			workerConfig := &api.WorkerConfig{
+				VolumeLayout: &api.VolumeLayout{
+					Disks: &api.DiskSelector{
+						Types:   []string{"nvme"},
+						MinSize: ptr.To(resource.MustParse("1Ti")),
+					},
+					RAIDLevel:  ptr.To("raid0"),
+					Filesystem: ptr.To("xfs"),
+					Volumes: []api.LogicalVolume{
+						{Name: "containerd"},
+						{Name: "kubelet", Size: ptr.To(resource.MustParse("100Gi"))},
+						{Name: "log", Size: ptr.To(resource.MustParse("20Gi"))},
+					},
+				},
+			}
+
+			Expect(newPoolVolumeLayout(workerConfig, extensionsv1alpha1.WorkerPool{})).To(Equal(&poolVolumeLayout{
+				DiskTypes:   []string{"nvme"},
+				MinDiskSize: 1 << 40,
+				RAIDLevel:   "raid0",
+				Filesystem:  "xfs",
+				Volumes: []poolVolume{
+					{Name: "kubelet", MountPoint: "/var/lib/kubelet", Size: 100 << 30},
+					{Name: "log", MountPoint: "/var/log", Size: 20 << 30},
+					{Name: "containerd", MountPoint: "/var/lib/containerd"},
+				},
+			}))
+		})
+	})
+})
@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	"fmt"
+	"slices"
+
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+)
+
+// machineDeploymentZone is a machine deployment of a worker pool and the facilities of its devices.
+type machineDeploymentZone struct {
+	name       string
+	facilities []string
+}
+
+// zoneMachineDeployment is a machine deployment of a zone of a worker pool and the spec of its machine class.
+type zoneMachineDeployment struct {
+	name      string
+	classSpec map[string]interface{}
+	minimum   int32
+	maximum   int32
+	priority  *int32
+}
+
+// machineDeploymentZones returns the machine deployments of the given worker pool. Worker pools with zones have a
+// machine deployment per zone, so that the cluster-autoscaler can balance them across the facilities. The devices of
+// worker pools without zones can be created in any facility of the metro, they have a single machine deployment.
+//
+// Worker pools used to have a single machine deployment for all of their zones. It is replaced by the machine
+// deployments of the zones, i.e., machine-controller-manager creates their machines and the generic actuator deletes the
+// single machine deployment once they are available. While the shoot is hibernated, the single machine deployment is
+// kept, as the hibernated devices are attached to it again when the shoot wakes up.
+func (w *workerDelegate) machineDeploymentZones(pool extensionsv1alpha1.WorkerPool) []machineDeploymentZone {
+	name := fmt.Sprintf("%s-%s", w.worker.Namespace, pool.Name)
+	if len(pool.Zones) == 0 {
+		return []machineDeploymentZone{{name: name}}
+	}
+
+	if extensionscontroller.IsHibernationEnabled(w.cluster) && slices.Contains(w.legacyMachineDeployments(pool), name) {
+		return []machineDeploymentZone{{name: name, facilities: pool.Zones}}
+	}
+
+	zones := make([]machineDeploymentZone, 0, len(pool.Zones))
+	for i, zone := range pool.Zones {
+		zones = append(zones, machineDeploymentZone{
+			name:       fmt.Sprintf("%s-z%d", name, i+1),
+			facilities: []string{zone},
+		})
+	}
+	return zones
+}
+
+// legacyMachineDeployments returns the machine deployments for all zones of the given worker pool with zones which are
+// recorded in the status of the worker, i.e., the single machine deployment and its on-demand overflow.
+func (w *workerDelegate) legacyMachineDeployments(pool extensionsv1alpha1.WorkerPool) []string {
+	if len(pool.Zones) == 0 {
+		return nil
+	}
+
+	var (
+		name  = fmt.Sprintf("%s-%s", w.worker.Namespace, pool.Name)
+		names []string
+	)
+	for _, candidate := range []string{name, name + onDemandOverflowSuffix} {
+		if slices.ContainsFunc(w.worker.Status.MachineDeployments, func(machineDeployment extensionsv1alpha1.MachineDeployment) bool {
+			return machineDeployment.Name == candidate
+		}) {
+			names = append(names, candidate)
+		}
+	}
+	return names
+}
@@ -0,0 +1,63 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package worker
+
+import (
+	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+var _ = Describe("Zones", func() {
+	const (
+		namespace  = "shoot--foo--bar"
+		legacyName = namespace + "-pool-1"
+		zone1      = legacyName + "-z1"
+		zone2      = legacyName + "-z2"
+	)
+
+	var w *workerDelegate
+
+	BeforeEach(func() {
+		w = &workerDelegate{
+			cluster: &extensionscontroller.Cluster{Shoot: &gardencorev1beta1.Shoot{}},
+			worker:  &extensionsv1alpha1.Worker{ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: namespace}},
+		}
+	})
+
+	Describe("#machineDeploymentZones", func() {
+		var pool extensionsv1alpha1.WorkerPool
+
+		BeforeEach(func() {
+			pool = extensionsv1alpha1.WorkerPool{Name: "pool-1", Zones: []string{"da11", "da12"}}
+			w.worker.Status.MachineDeployments = []extensionsv1alpha1.MachineDeployment{{Name: legacyName}}
+		})
+
+		It("should return a machine deployment per zone", func() {
+			Expect(w.machineDeploymentZones(pool)).To(Equal([]machineDeploymentZone{
+				{name: zone1, facilities: []string{"da11"}},
+				{name: zone2, facilities: []string{"da12"}},
+			}))
+			Expect(w.legacyMachineDeployments(pool)).To(ConsistOf(legacyName))
+		})
+
+		It("should return a single machine deployment for worker pools without zones", func() {
+			pool.Zones = nil
+
+			Expect(w.machineDeploymentZones(pool)).To(Equal([]machineDeploymentZone{{name: legacyName}}))
+			Expect(w.legacyMachineDeployments(pool)).To(BeEmpty())
+		})
+
+		It("should keep the machine deployment for all zones while the shoot is hibernated", func() {
+			w.cluster.Shoot.Spec.Hibernation = &gardencorev1beta1.Hibernation{Enabled: ptr.To(true)}
+
+			Expect(w.machineDeploymentZones(pool)).To(Equal([]machineDeploymentZone{{name: legacyName, facilities: []string{"da11", "da12"}}}))
+		})
+	})
+})
@@ -8,6 +8,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
 	"strings"
 
 	"github.com/equinix/equinix-sdk-go/services/metalv1"
@@ -42,11 +43,65 @@ func (p *eqxmClient) GetDevice(
 ) (*metalv1.Device, error) {
 	device, _, err := p.client.DevicesApi.
 		FindDeviceById(ctx, deviceID).
-		Include([]string{"ip_addresses.parent_block,parent_block"}).
+		Include([]string{"ip_addresses.parent_block,parent_block", "network_ports.virtual_networks"}).
 		Execute()
 	return device, err
 }
 
+func (p *eqxmClient) ListDevices(
+	ctx context.Context,
+	projectID string,
+	tag string,
+) ([]metalv1.Device, error) {
+	devices, err := p.client.DevicesApi.
+		FindProjectDevices(ctx, projectID).
+		Tag(tag).
+		Include([]string{"ip_addresses.parent_block,parent_block", "network_ports.virtual_networks"}).
+		ExecuteWithPagination()
+	if err != nil {
+		return nil, err
+	}
+	return devices.Devices, nil
+}
+
+func (p *eqxmClient) UpdateDevice(
+	ctx context.Context,
+	deviceID string,
+	input metalv1.DeviceUpdateInput,
+) (*metalv1.Device, error) {
+	device, _, err := p.client.DevicesApi.
+		UpdateDevice(ctx, deviceID).
+		DeviceUpdateInput(input).
+		Execute()
+	return device, err
+}
+
+func (p *eqxmClient) PerformDeviceAction(
+	ctx context.Context,
+	deviceID string,
+	input metalv1.DeviceActionInput,
+) error {
+	_, err := p.client.DevicesApi.
+		PerformAction(ctx, deviceID).
+		DeviceActionInput(input).
+		Execute()
+	return err
+}
+
+// DeleteDevice deletes the device with the given ID. Devices which do not exist anymore are considered deleted.
+func (p *eqxmClient) DeleteDevice(
+	ctx context.Context,
+	deviceID string,
+) error {
+	resp, err := p.client.DevicesApi.
+		DeleteDevice(ctx, deviceID).
+		Execute()
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
 func (p *eqxmClient) GetNetwork(
 	ctx context.Context,
 	projectID string,
@@ -57,3 +112,155 @@ func (p *eqxmClient) GetNetwork(
 		Execute()
 	return addr, err
 }
+
+func (p *eqxmClient) ListHardwareReservations(
+	ctx context.Context,
+	projectID string,
+) ([]metalv1.HardwareReservation, error) {
+	reservations, err := p.client.HardwareReservationsApi.
+		FindProjectHardwareReservations(ctx, projectID).
+		Include([]string{"facility.metro", "plan"}).
+		ExecuteWithPagination()
+	if err != nil {
+		return nil, err
+	}
+	return reservations.HardwareReservations, nil
+}
+
+// ListProjectSSHKeys returns all SSH keys of the given project. Unlike the devices and hardware reservations of a
+// project, the SSH keys are not paginated by the Equinix Metal API, i.e., the response contains the complete list.
+func (p *eqxmClient) ListProjectSSHKeys(
+	ctx context.Context,
+	projectID string,
+) ([]metalv1.SSHKey, error) {
+	keys, _, err := p.client.SSHKeysApi.
+		FindProjectSSHKeys(ctx, projectID).
+		Execute()
+	if err != nil {
+		return nil, err
+	}
+	return keys.SshKeys, nil
+}
+
+func (p *eqxmClient) GetSSHKey(
+	ctx context.Context,
+	keyID string,
+) (*metalv1.SSHKey, error) {
+	key, _, err := p.client.SSHKeysApi.
+		FindSSHKeyById(ctx, keyID).
+		Execute()
+	return key, err
+}
+
+// ListPlans returns all plans which are available to the given project. The plans are not paginated by the Equinix
+// Metal API, i.e., the response contains the complete list.
+func (p *eqxmClient) ListPlans(
+	ctx context.Context,
+	projectID string,
+) ([]metalv1.Plan, error) {
+	plans, _, err := p.client.PlansApi.
+		FindPlansByProject(ctx, projectID).
+		Execute()
+	if err != nil {
+		return nil, err
+	}
+	return plans.Plans, nil
+}
+
+func (p *eqxmClient) ListMetroCapacity(
+	ctx context.Context,
+) (map[string]map[string]metalv1.CapacityLevelPerBaremetal, error) {
+	capacity, _, err := p.client.CapacityApi.
+		FindCapacityForMetro(ctx).
+		Execute()
+	if err != nil {
+		return nil, err
+	}
+	return capacity.GetCapacity(), nil
+}
+
+func (p *eqxmClient) ListFacilityCapacity(
+	ctx context.Context,
+) (map[string]map[string]metalv1.CapacityLevelPerBaremetal, error) {
+	capacity, _, err := p.client.CapacityApi.
+		FindCapacityForFacility(ctx).
+		Execute()
+	if err != nil {
+		return nil, err
+	}
+	return capacity.GetCapacity(), nil
+}
+
+// ListVLANs returns all VLANs of the given project in the given metro. The VLANs are not paginated by the Equinix Metal
+// API, i.e., the response contains the complete list.
+func (p *eqxmClient) ListVLANs(
+	ctx context.Context,
+	projectID string,
+	metro string,
+) ([]metalv1.VirtualNetwork, error) {
+	vlans, _, err := p.client.VLANsApi.
+		FindVirtualNetworks(ctx, projectID).
+		Metro(metro).
+		Execute()
+	if err != nil {
+		return nil, err
+	}
+	return vlans.VirtualNetworks, nil
+}
+
+func (p *eqxmClient) CreateVLAN(
+	ctx context.Context,
+	projectID string,
+	input metalv1.VirtualNetworkCreateInput,
+) (*metalv1.VirtualNetwork, error) {
+	vlan, _, err := p.client.VLANsApi.
+		CreateVirtualNetwork(ctx, projectID).
+		VirtualNetworkCreateInput(input).
+		Execute()
+	return vlan, err
+}
+
+func (p *eqxmClient) DeleteVLAN(
+	ctx context.Context,
+	vlanID string,
+) error {
+	_, err := p.client.VLANsApi.
+		DeleteVirtualNetwork(ctx, vlanID).
+		Execute()
+	return err
+}
+
+func (p *eqxmClient) ConvertPortToLayer2(
+	ctx context.Context,
+	portID string,
+) (*metalv1.Port, error) {
+	port, _, err := p.client.PortsApi.
+		ConvertLayer2(ctx, portID).
+		PortAssignInput(metalv1.PortAssignInput{}).
+		Execute()
+	return port, err
+}
+
+func (p *eqxmClient) DisbondPort(
+	ctx context.Context,
+	portID string,
+	bulk bool,
+) (*metalv1.Port, error) {
+	port, _, err := p.client.PortsApi.
+		DisbondPort(ctx, portID).
+		BulkDisable(bulk).
+		Execute()
+	return port, err
+}
+
+func (p *eqxmClient) AssignPortVLAN(
+	ctx context.Context,
+	portID string,
+	vnid string,
+) (*metalv1.Port, error) {
+	port, _, err := p.client.PortsApi.
+		AssignPort(ctx, portID).
+		PortAssignInput(metalv1.PortAssignInput{Vnid: &vnid}).
+		Execute()
+	return port, err
+}
@@ -0,0 +1,7 @@
+// SPDX-FileCopyrightText: 2024 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+//go:generate mockgen -package mock -destination=mocks.go github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal/client ClientInterface
+
+package mock
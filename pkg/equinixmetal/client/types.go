@@ -10,14 +10,86 @@ import (
 	"github.com/equinix/equinix-sdk-go/services/metalv1"
 )
 
+// NewClientFunc is a function which creates a new Client for the given Equinix Metal API token.
+type NewClientFunc func(apiKey string) (ClientInterface, error)
+
 // ClientInterface is an interface which must be implemented by Equinix Metal clients.
 type ClientInterface interface {
 	GetDevice(
 		ctx context.Context,
 		deviceID string,
 	) (*metalv1.Device, error)
+	ListDevices(
+		ctx context.Context,
+		projectID string,
+		tag string,
+	) ([]metalv1.Device, error)
+	UpdateDevice(
+		ctx context.Context,
+		deviceID string,
+		input metalv1.DeviceUpdateInput,
+	) (*metalv1.Device, error)
+	PerformDeviceAction(
+		ctx context.Context,
+		deviceID string,
+		input metalv1.DeviceActionInput,
+	) error
+	DeleteDevice(
+		ctx context.Context,
+		deviceID string,
+	) error
 	GetNetwork(
 		ctx context.Context,
 		projectID string,
 	) (*metalv1.IPReservationList, error)
+	ListHardwareReservations(
+		ctx context.Context,
+		projectID string,
+	) ([]metalv1.HardwareReservation, error)
+	ListProjectSSHKeys(
+		ctx context.Context,
+		projectID string,
+	) ([]metalv1.SSHKey, error)
+	GetSSHKey(
+		ctx context.Context,
+		keyID string,
+	) (*metalv1.SSHKey, error)
+	ListPlans(
+		ctx context.Context,
+		projectID string,
+	) ([]metalv1.Plan, error)
+	ListMetroCapacity(
+		ctx context.Context,
+	) (map[string]map[string]metalv1.CapacityLevelPerBaremetal, error)
+	ListFacilityCapacity(
+		ctx context.Context,
+	) (map[string]map[string]metalv1.CapacityLevelPerBaremetal, error)
+	ListVLANs(
+		ctx context.Context,
+		projectID string,
+		metro string,
+	) ([]metalv1.VirtualNetwork, error)
+	CreateVLAN(
+		ctx context.Context,
+		projectID string,
+		input metalv1.VirtualNetworkCreateInput,
+	) (*metalv1.VirtualNetwork, error)
+	DeleteVLAN(
+		ctx context.Context,
+		vlanID string,
+	) error
+	ConvertPortToLayer2(
+		ctx context.Context,
+		portID string,
+	) (*metalv1.Port, error)
+	DisbondPort(
+		ctx context.Context,
+		portID string,
+		bulk bool,
+	) (*metalv1.Port, error)
+	AssignPortVLAN(
+		ctx context.Context,
+		portID string,
+		vnid string,
+	) (*metalv1.Port, error)
 }
@@ -31,6 +31,28 @@ const (
 	// SSHKeyID key for accessing SSH key ID from outputs in terraform
 	SSHKeyID = "key_pair_id"
 
+	// SpotInstanceLabel is the key of the label and the taint of nodes which are spot market devices.
+	SpotInstanceLabel = "metal.equinix.com/spot-instance"
+	// FacilityLabel is the key of the label of nodes containing the facility code of their devices.
+	FacilityLabel = "metal.equinix.com/facility"
+	// MetroLabel is the key of the label of nodes containing the metro code of their devices.
+	MetroLabel = "metal.equinix.com/metro"
+	// PlanLabel is the key of the label of nodes containing the plan of their devices.
+	PlanLabel = "metal.equinix.com/plan"
+	// HardwareReservationLabel is the key of the label of nodes containing the ID of the hardware reservation used by
+	// their devices.
+	HardwareReservationLabel = "metal.equinix.com/hardware-reservation"
+	// CapacityTypeLabel is the key of the label of nodes indicating whether their devices are spot market or on-demand
+	// devices.
+	CapacityTypeLabel = "metal.equinix.com/capacity-type"
+
+	// ReinstallStartedAtAnnotation is the key of the annotation of machines whose devices are being reinstalled for an
+	// in-place update. It contains the time the reinstall was started at.
+	ReinstallStartedAtAnnotation = "equinixmetal.provider.extensions.gardener.cloud/reinstall-started-at"
+	// ReinstallBootIDAnnotation is the key of the annotation of machines whose devices are being reinstalled for an
+	// in-place update. It contains the boot ID of the node before the reinstall.
+	ReinstallBootIDAnnotation = "equinixmetal.provider.extensions.gardener.cloud/reinstall-boot-id"
+
 	// CloudControllerManagerName is a constant for the name of the CloudController deployed by the worker controller.
 	CloudControllerManagerName = "cloud-controller-manager"
 )
@@ -7,6 +7,7 @@ package controlplane
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/coreos/go-systemd/v22/unit"
@@ -14,18 +15,22 @@ import (
 	gcontext "github.com/gardener/gardener/extensions/pkg/webhook/context"
 	"github.com/gardener/gardener/extensions/pkg/webhook/controlplane"
 	"github.com/gardener/gardener/extensions/pkg/webhook/controlplane/genericmutator"
+	gardencorev1beta1 "github.com/gardener/gardener/pkg/apis/core/v1beta1"
 	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
 	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
 	"github.com/gardener/gardener/pkg/component/nodemanagement/machinecontrollermanager"
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	vpaautoscalingv1 "k8s.io/autoscaler/vertical-pod-autoscaler/pkg/apis/autoscaling.k8s.io/v1"
 	kubeletconfigv1beta1 "k8s.io/kubelet/config/v1beta1"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/gardener/gardener-extension-provider-equinix-metal/imagevector"
+	api "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal"
+	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/apis/equinixmetal/helper"
 	eqxcontrolplane "github.com/gardener/gardener-extension-provider-equinix-metal/pkg/controller/controlplane"
 	"github.com/gardener/gardener-extension-provider-equinix-metal/pkg/equinixmetal"
 )
@@ -147,67 +152,42 @@ func (e *ensurer) EnsureVPNSeedServerStatefulSet(ctx context.Context, gCtx gcont
 
 // EnsureAdditionalProvisionUnits ensures that additional required system units are added, that are required during provisioning.
 func (e *ensurer) EnsureAdditionalProvisionUnits(ctx context.Context, gctx gcontext.GardenContext, new, _ *[]extensionsv1alpha1.Unit) error {
-
-	// Define LVM setup unit
-	var lvmSetup = `
-[Unit]
-        Description=LVM Setup
-        DefaultDependencies=no
-        Before=local-fs-pre.target
-        [Service]
-        Type=oneshot
-        Restart=on-failure
-        RemainAfterExit=yes
-        ExecStart=/opt/bin/lvm.sh
-        [Install]
-        WantedBy=multi-user.target
-`
-	// Define LVM mount unit
-	var lvmMountContainerd = `
-[Unit]
-        Description=Mount LVM to containerd dir
-        After=lvm-setup.service
-        Before=containerd.service
-        [Mount]
-        What=/dev/vg-containerd/vol_containerd
-        Where=/var/lib/containerd
-        Type=ext4
-        Options=defaults
-        [Install]
-        WantedBy=local-fs.target
-`
-	volume, err := hasVolume(ctx, gctx)
+	pool, err := getWorkerPool(ctx, gctx)
 	if err != nil {
 		return err
 	}
-	if !volume {
+	if pool == nil {
 		return nil
 	}
-	operatingsystems, err := getOperatingSystems(ctx, gctx)
+	volume, err := hasVolume(*pool)
 	if err != nil {
 		return err
 	}
-	if len(operatingsystems) > 1 {
-		return fmt.Errorf("multiple operatingsystems used: %v; Only one allowed", operatingsystems)
+	if !volume {
+		return nil
 	}
 
-	switch operatingsystems[0] {
-	case "flatcar":
+	if _, ok := lvmSetupPrerequisites[operatingSystem(*pool)]; ok {
+		// The LVM setup is executed on every boot, it creates the volumes on the first boot and mounts them afterwards.
 		extensionswebhook.AppendUniqueUnit(new, extensionsv1alpha1.Unit{
 			Name:    "lvm-setup.service",
 			Enable:  ptr.To(true),
 			Command: ptr.To(extensionsv1alpha1.CommandStart),
-			Content: ptr.To(lvmSetup),
-		})
-
-		extensionswebhook.AppendUniqueUnit(new, extensionsv1alpha1.Unit{
-			Name:    "var-lib-containerd.mount",
-			Enable:  ptr.To(true),
-			Command: ptr.To(extensionsv1alpha1.CommandStart),
-			Content: ptr.To(lvmMountContainerd),
+			Content: ptr.To(`[Unit]
+Description=LVM Setup
+After=network-online.target
+Wants=network-online.target
+Before=containerd.service kubelet.service gardener-node-agent.service
+[Install]
+WantedBy=multi-user.target
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+Restart=on-failure
+RestartSec=10
+ExecStart=/opt/bin/lvm.sh
+`),
 		})
-
-		return nil
 	}
 
 	return nil
@@ -215,60 +195,131 @@ func (e *ensurer) EnsureAdditionalProvisionUnits(ctx context.Context, gctx gcont
 
 // EnsureAdditionalProvisionFiles ensures that additional required system files are added, that are required during provisioning.
 func (e *ensurer) EnsureAdditionalProvisionFiles(ctx context.Context, gctx gcontext.GardenContext, new, _ *[]extensionsv1alpha1.File) error {
-	volume, err := hasVolume(ctx, gctx)
+	pool, err := getWorkerPool(ctx, gctx)
+	if err != nil {
+		return err
+	}
+	if pool == nil {
+		return nil
+	}
+	volume, err := hasVolume(*pool)
 	if err != nil {
 		return err
 	}
 	if volume {
-		operatingsystems, err := getOperatingSystems(ctx, gctx)
-		if err != nil {
-			return err
-		}
-		if len(operatingsystems) > 1 {
-			return fmt.Errorf("multiple operatingsystems used: %v; Only one allowed", operatingsystems)
-		}
-
-		switch operatingsystems[0] {
-		case "flatcar":
-
+		if prerequisites, ok := lvmSetupPrerequisites[operatingSystem(*pool)]; ok {
 			var (
 				permissions       uint32 = 0755
 				customFileContent        = `#!/bin/bash
-          set -euo pipefail
-
-
-          # Function to find all disks
-          find_volumes(){
-            lsblk -d -o NAME,TYPE | awk '$2 == "disk" {print "/dev/" $1}'
-          }
-
-          create_pvs() {
-            disks=$(find_volumes)
-            usable_disks=""
-
-            for disk in $disks; do
-              if pvcreate -y $disk 2>&1 | grep -q "successfully created"; then
-                usable_disks="$usable_disks $disk"
-              fi
-            done
-
-            echo $usable_disks
-          }
-
-          # Get the list of usable disks
-          usable_disks=$(create_pvs)
-
-          # Create Physical Volumes
-          pvcreate ${usable_disks}
-
-          # Create Volume Group
-          vgcreate vg-containerd ${usable_disks}
-
-          # Create Logical Volume for data
-          lvcreate -n vol_containerd -l 100%FREE vg-containerd
+# The worker controller passes the volume layout of the worker pool via the custom data of the device. The layout is
+# cached on the root disk, so that the existing volumes are only mounted on subsequent boots.
+set -o errexit
+set -o nounset
+set -o pipefail
+` + prerequisites + `
+VG="vg-containerd"
+LAYOUT="/var/lib/lvm-setup/layout.json"
+
+if [[ ! -s "${LAYOUT}" ]]; then
+  VOLUMES="$(curl -sf https://metadata.platformequinix.com/metadata | jq -c '.customdata.gardener.volumes // empty')"
+  if [[ -z "${VOLUMES}" ]]; then
+    echo "No volume layout configured for this device"
+    exit 0
+  fi
+  mkdir -p "$(dirname "${LAYOUT}")"
+  echo "${VOLUMES}" > "${LAYOUT}"
+fi
+
+# select_disks prints the disks matching the disk selector of the layout. Disks which are partitioned or contain a
+# filesystem or another signature are never selected.
+select_disks() {
+  local types min_size max_size
+  types=" $(jq -r '.diskTypes // [] | join(" ")' "${LAYOUT}") "
+  min_size="$(jq -r '.minDiskSize // 0' "${LAYOUT}")"
+  max_size="$(jq -r '.maxDiskSize // 0' "${LAYOUT}")"
+
+  lsblk -d -b -n -o NAME,TYPE,ROTA,SIZE | while read -r name type rota size; do
+    if [[ "${type}" != "disk" || "$(lsblk -n -o NAME "/dev/${name}" | wc -l)" -ne 1 ]] || blkid -p "/dev/${name}" >/dev/null 2>&1; then
+      continue
+    fi
+
+    if [[ "${name}" == nvme* ]]; then
+      disk_type="nvme"
+    elif [[ "${rota}" == "0" ]]; then
+      disk_type="ssd"
+    else
+      disk_type="hdd"
+    fi
+
+    if [[ "${types}" != "  " && "${types}" != *" ${disk_type} "* ]] || (( size < min_size )) || (( max_size > 0 && size > max_size )); then
+      continue
+    fi
+    echo "/dev/${name}"
+  done
+}
 
-          # Format the data volume with ext4 filesystem
-          mkfs.ext4 /dev/vg-containerd/vol_containerd
+if ! vgs "${VG}" >/dev/null 2>&1; then
+  DISKS="$(select_disks)"
+  if [[ -z "${DISKS}" ]]; then
+    echo "No disks matching the volume layout found"
+    exit 1
+  fi
+  pvcreate -y ${DISKS}
+  vgcreate "${VG}" ${DISKS}
+fi
+
+RAID_LEVEL="$(jq -r '.raidLevel // empty' "${LAYOUT}")"
+FILESYSTEM="$(jq -r '.filesystem' "${LAYOUT}")"
+PV_COUNT="$(vgs --noheadings -o pv_count "${VG}" | tr -d ' ')"
+
+while read -r name mount_point size; do
+  lv="/dev/${VG}/vol_${name}"
+
+  if ! lvs "${VG}/vol_${name}" >/dev/null 2>&1; then
+    args=(-y -n "vol_${name}")
+    if (( size > 0 )); then
+      args+=(-L "${size}b")
+    else
+      args+=(-l 100%FREE)
+    fi
+    case "${RAID_LEVEL}" in
+      raid0|raid1)
+        if (( PV_COUNT < 2 )); then
+          echo "${RAID_LEVEL} requires at least two disks, found ${PV_COUNT}"
+          exit 1
+        fi
+        ;;&
+      raid0)
+        args+=(--type raid0 --stripes "${PV_COUNT}")
+        ;;
+      raid1)
+        args+=(--type raid1 --mirrors 1)
+        ;;
+    esac
+    lvcreate "${args[@]}" "${VG}"
+  fi
+
+  mkdir -p "${mount_point}"
+  if ! blkid -p "${lv}" >/dev/null 2>&1; then
+    "mkfs.${FILESYSTEM}" "${lv}"
+    # keep the existing content of the mount point, e.g. the logs written before the volume was mounted
+    tmp="$(mktemp -d)"
+    mount "${lv}" "${tmp}"
+    cp -a "${mount_point}/." "${tmp}/"
+    umount "${tmp}"
+    rmdir "${tmp}"
+  fi
+
+  if ! mountpoint -q "${mount_point}"; then
+    if [[ "${mount_point}" == "/var/log" ]]; then
+      journalctl --relinquish-var
+    fi
+    mount "${lv}" "${mount_point}"
+    if [[ "${mount_point}" == "/var/log" ]]; then
+      journalctl --flush
+    fi
+  fi
+done < <(jq -r '.volumes[] | "\(.name) \(.mountPoint) \(.size // 0)"' "${LAYOUT}")
 `
 			)
 
@@ -282,14 +333,61 @@ func (e *ensurer) EnsureAdditionalProvisionFiles(ctx context.Context, gctx gcont
 					},
 				},
 			})
-			return nil
 		}
 	}
 	return nil
 }
 
 // EnsureAdditionalUnits ensures that additional required system units are added.
-func (e *ensurer) EnsureAdditionalUnits(_ context.Context, _ gcontext.GardenContext, new, _ *[]extensionsv1alpha1.Unit) error {
+func (e *ensurer) EnsureAdditionalUnits(ctx context.Context, gctx gcontext.GardenContext, new, _ *[]extensionsv1alpha1.Unit) error {
+	spot, err := isSpotWorkerPool(ctx, gctx)
+	if err != nil {
+		return err
+	}
+	if spot {
+		extensionswebhook.AppendUniqueUnit(new, extensionsv1alpha1.Unit{
+			Name:    "spot-termination-handler.service",
+			Enable:  ptr.To(true),
+			Command: ptr.To(extensionsv1alpha1.CommandStart),
+			Content: ptr.To(`[Unit]
+Description=Drains and shuts down the node when the spot market device is about to be terminated
+After=network-online.target
+Wants=network-online.target
+[Install]
+WantedBy=multi-user.target
+[Service]
+Restart=always
+RestartSec=10
+ExecStart=/opt/bin/spot-termination-handler.sh
+`),
+		})
+	}
+
+	vlans, err := isVLANWorkerPool(ctx, gctx)
+	if err != nil {
+		return err
+	}
+	if vlans {
+		extensionswebhook.AppendUniqueUnit(new, extensionsv1alpha1.Unit{
+			Name:    "configure-vlans.service",
+			Enable:  ptr.To(true),
+			Command: ptr.To(extensionsv1alpha1.CommandStart),
+			Content: ptr.To(`[Unit]
+Description=Configures the VLAN interfaces of the device
+After=network-online.target
+Wants=network-online.target
+[Install]
+WantedBy=multi-user.target
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+Restart=on-failure
+RestartSec=10
+ExecStart=/opt/bin/configure-vlans.sh
+`),
+		})
+	}
+
 	extensionswebhook.AppendUniqueUnit(new, extensionsv1alpha1.Unit{
 		Name:    "bgp-peer-route.service",
 		Enable:  ptr.To(true),
@@ -310,7 +408,124 @@ ExecStart=/opt/bin/bgp-peer.sh
 }
 
 // EnsureAdditionalFiles ensures that additional required system files are added.
-func (e *ensurer) EnsureAdditionalFiles(_ context.Context, _ gcontext.GardenContext, new, _ *[]extensionsv1alpha1.File) error {
+func (e *ensurer) EnsureAdditionalFiles(ctx context.Context, gctx gcontext.GardenContext, new, _ *[]extensionsv1alpha1.File) error {
+	spot, err := isSpotWorkerPool(ctx, gctx)
+	if err != nil {
+		return err
+	}
+	if spot {
+		var (
+			permissions       uint32 = 0755
+			customFileContent        = `#!/bin/bash
+# Equinix Metal announces the termination of spot market devices via the metadata service. The node is then annotated
+# to be deleted by the machine-controller-manager which cordons and drains it, respecting PodDisruptionBudgets. The
+# device is only powered off once the node is gone or the termination is imminent, in which case the kubelet terminates
+# the remaining pods gracefully, see the kubelet's shutdownGracePeriod.
+KUBECONFIG_PATH="/var/lib/kubelet/kubeconfig-real"
+CLIENT_CERTIFICATE="/var/lib/kubelet/pki/kubelet-client-current.pem"
+SHUTDOWN_GRACE_PERIOD_SECONDS=90
+NODE_NAME="$(hostname)"
+
+function kube_api() {
+  local method="$1" path="$2" server ca_file
+  shift 2
+  server="$(grep -m1 'server:' "${KUBECONFIG_PATH}" | awk '{print $2}')"
+  ca_file="$(mktemp)"
+  grep -m1 'certificate-authority-data:' "${KUBECONFIG_PATH}" | awk '{print $2}' | base64 -d > "${ca_file}"
+  curl -s --cacert "${ca_file}" --cert "${CLIENT_CERTIFICATE}" -X "${method}" "${server}${path}" "$@"
+  rm -f "${ca_file}"
+}
+
+while true; do
+  TERMINATION_TIME="$(curl -sf https://metadata.platformequinix.com/metadata | jq -r '.spot.termination_time // empty')"
+  if [[ -n "${TERMINATION_TIME}" ]]; then
+    echo "Spot market device will be terminated at ${TERMINATION_TIME}, draining node ${NODE_NAME}"
+    DEADLINE=$(( $(date -d "${TERMINATION_TIME}" +%s || date +%s) - SHUTDOWN_GRACE_PERIOD_SECONDS ))
+    while [[ "$(date +%s)" -lt "${DEADLINE}" ]]; do
+      STATUS="$(kube_api PATCH "/api/v1/nodes/${NODE_NAME}" -o /dev/null -w '%{http_code}' \
+        -H "Content-Type: application/merge-patch+json" \
+        -d '{"metadata":{"annotations":{"node.machine.sapcloud.io/trigger-deletion-by-mcm":"true"}}}')"
+      if [[ "${STATUS}" == "404" ]]; then
+        echo "Node ${NODE_NAME} has been drained and deleted"
+        break
+      fi
+      sleep 5
+    done
+    echo "Shutting down"
+    systemctl poweroff
+    exit 0
+  fi
+  sleep 5
+done
+`
+		)
+
+		appendUniqueFile(new, extensionsv1alpha1.File{
+			Path:        "/opt/bin/spot-termination-handler.sh",
+			Permissions: &permissions,
+			Content: extensionsv1alpha1.FileContent{
+				Inline: &extensionsv1alpha1.FileContentInline{
+					Encoding: "",
+					Data:     customFileContent,
+				},
+			},
+		})
+	}
+
+	vlans, err := isVLANWorkerPool(ctx, gctx)
+	if err != nil {
+		return err
+	}
+	if vlans {
+		var (
+			permissions       uint32 = 0755
+			customFileContent        = `#!/bin/bash
+# The worker controller passes the network type and the VLAN IDs of the worker pool via the custom data of the device.
+set -o errexit
+set -o pipefail
+
+METADATA="$(curl -sf https://metadata.platformequinix.com/metadata)"
+NETWORK_TYPE="$(echo "${METADATA}" | jq -r '.customdata.gardener.network.type // empty')"
+VLAN_IDS="$(echo "${METADATA}" | jq -r '.customdata.gardener.network.vlans[]? // empty')"
+if [[ -z "${NETWORK_TYPE}" || -z "${VLAN_IDS}" ]]; then
+  echo "No VLANs configured for this device"
+  exit 0
+fi
+
+case "${NETWORK_TYPE}" in
+  hybrid-bonded|layer2-bonded)
+    PARENT="bond0"
+    ;;
+  *)
+    # the VLANs are assigned to the second port, which is not part of the bond anymore
+    MAC="$(echo "${METADATA}" | jq -r '.network.interfaces[1].mac')"
+    PARENT="$(ip -o link | grep -v ' bond' | grep -i -e "link/ether ${MAC}" -e "permaddr ${MAC}" | awk -F': ' '{print $2; exit}')"
+    ip link set "${PARENT}" nomaster
+    ;;
+esac
+
+ip link set "${PARENT}" up
+for VLAN_ID in ${VLAN_IDS}; do
+  if ! ip link show "${PARENT}.${VLAN_ID}" >/dev/null 2>&1; then
+    ip link add link "${PARENT}" name "${PARENT}.${VLAN_ID}" type vlan id "${VLAN_ID}"
+  fi
+  ip link set "${PARENT}.${VLAN_ID}" up
+done
+`
+		)
+
+		appendUniqueFile(new, extensionsv1alpha1.File{
+			Path:        "/opt/bin/configure-vlans.sh",
+			Permissions: &permissions,
+			Content: extensionsv1alpha1.FileContent{
+				Inline: &extensionsv1alpha1.FileContentInline{
+					Encoding: "",
+					Data:     customFileContent,
+				},
+			},
+		})
+	}
+
 	var (
 		permissions       uint32 = 0755
 		customFileContent        = `#!/bin/sh
@@ -377,57 +592,122 @@ func (e *ensurer) EnsureKubeletServiceUnitOptions(ctx context.Context, gctx gcon
 		command := extensionswebhook.DeserializeCommandLine(opt.Value)
 		command = ensureKubeletCommandLineArgs(command)
 
-		volume, err := hasVolume(ctx, gctx)
+		pool, err := getWorkerPool(ctx, gctx)
 		if err != nil {
 			return new, err
 		}
-		if volume {
-			command = ensureKubeletRootDirCommandLineArg(command)
+		if pool != nil {
+			defaultVolumeLayout, err := hasDefaultVolumeLayout(*pool)
+			if err != nil {
+				return new, err
+			}
+			if defaultVolumeLayout {
+				command = ensureKubeletRootDirCommandLineArg(command)
+			}
 		}
 		opt.Value = extensionswebhook.SerializeCommandLine(command, 1, " \\\n    ")
 	}
 	return new, nil
 }
 
-// getOperatingSystems returns an array of all operatingsytems used in the given cluster
-func getOperatingSystems(ctx context.Context, gctx gcontext.GardenContext) ([]string, error) {
-	operatingsystems := make(map[string]int)
+// aptLVMSetupPrerequisites installs the tools required by the LVM setup on Debian based operating systems, if they are
+// not part of the machine image.
+const aptLVMSetupPrerequisites = `
+if ! command -v lvcreate >/dev/null || ! command -v mkfs.xfs >/dev/null || ! command -v jq >/dev/null; then
+  apt-get update -qq
+  DEBIAN_FRONTEND=noninteractive apt-get install -y -qq lvm2 xfsprogs jq
+fi
+`
+
+// lvmSetupPrerequisites contains the operating systems supporting the LVM setup and the commands installing the tools
+// required by the LVM setup, if they are not part of the machine images.
+var lvmSetupPrerequisites = map[string]string{
+	"flatcar":     "",
+	"gardenlinux": aptLVMSetupPrerequisites,
+	"ubuntu":      aptLVMSetupPrerequisites,
+}
+
+// getWorkerPool returns the worker pool of the mutated OperatingSystemConfig, or nil if it is unknown
+func getWorkerPool(ctx context.Context, gctx gcontext.GardenContext) (*gardencorev1beta1.Worker, error) {
+	name, ok := workerPoolFromContext(ctx)
+	if !ok {
+		return nil, nil
+	}
 
 	cluster, err := gctx.GetCluster(ctx)
 	if err != nil {
-		return []string{}, err
+		return nil, err
+	}
+	for i, worker := range cluster.Shoot.Spec.Provider.Workers {
+		if worker.Name == name {
+			return &cluster.Shoot.Spec.Provider.Workers[i], nil
+		}
 	}
+	return nil, nil
+}
 
-	for _, worker := range cluster.Shoot.Spec.Provider.Workers {
-		operatingsystems[worker.Machine.Image.Name]++
+// operatingSystem returns the name of the machine image of the given worker pool
+func operatingSystem(worker gardencorev1beta1.Worker) string {
+	if worker.Machine.Image == nil {
+		return ""
 	}
-	return getMapKeys(operatingsystems), nil
+	return worker.Machine.Image.Name
 }
 
-// getMapKeys takes a map[string](int) and returns the keys as []string
-func getMapKeys(inputMap map[string]int) []string {
-	var output []string
-	for k := range inputMap {
-		output = append(output, k)
+// hasVolume checks if the given worker has set a value for `Volume` or uses a volume layout
+func hasVolume(worker gardencorev1beta1.Worker) (bool, error) {
+	if worker.Volume != nil {
+		return true, nil
+	}
+	workerConfig, err := helper.WorkerConfigFromRawExtension(worker.ProviderConfig)
+	if err != nil {
+		return false, fmt.Errorf("could not decode provider config of worker pool %q: %w", worker.Name, err)
 	}
-	return output
+	return workerConfig.VolumeLayout != nil, nil
 }
 
-// hasVolume checks if any worker has set a value for `Volume`
-func hasVolume(ctx context.Context, gctx gcontext.GardenContext) (bool, error) {
-	cluster, err := gctx.GetCluster(ctx)
+// hasDefaultVolumeLayout checks if the given worker has set a value for `Volume` without using a volume layout, i.e.,
+// uses a single logical volume for containerd
+func hasDefaultVolumeLayout(worker gardencorev1beta1.Worker) (bool, error) {
+	if worker.Volume == nil {
+		return false, nil
+	}
+	workerConfig, err := helper.WorkerConfigFromRawExtension(worker.ProviderConfig)
 	if err != nil {
+		return false, fmt.Errorf("could not decode provider config of worker pool %q: %w", worker.Name, err)
+	}
+	return workerConfig.VolumeLayout == nil, nil
+}
+
+// isSpotWorkerPool checks if the worker pool of the mutated OperatingSystemConfig uses spot market devices
+func isSpotWorkerPool(ctx context.Context, gctx gcontext.GardenContext) (bool, error) {
+	return workerPoolMatches(ctx, gctx, func(workerConfig *api.WorkerConfig) bool {
+		return ptr.Deref(workerConfig.SpotInstance, false)
+	})
+}
+
+// isVLANWorkerPool checks if the worker pool of the mutated OperatingSystemConfig attaches VLANs to its devices
+func isVLANWorkerPool(ctx context.Context, gctx gcontext.GardenContext) (bool, error) {
+	return workerPoolMatches(ctx, gctx, func(workerConfig *api.WorkerConfig) bool {
+		return workerConfig.Network != nil && len(workerConfig.Network.VLANs) > 0
+	})
+}
+
+// workerPoolMatches checks if the provider config of the worker pool of the mutated OperatingSystemConfig matches the
+// given predicate
+func workerPoolMatches(ctx context.Context, gctx gcontext.GardenContext, predicate func(*api.WorkerConfig) bool) (bool, error) {
+	pool, err := getWorkerPool(ctx, gctx)
+	if err != nil || pool == nil {
 		return false, err
 	}
-	for _, worker := range cluster.Shoot.Spec.Provider.Workers {
-		if worker.Volume != nil {
-			return true, nil
-		}
+	workerConfig, err := helper.WorkerConfigFromRawExtension(pool.ProviderConfig)
+	if err != nil {
+		return false, fmt.Errorf("could not decode provider config of worker pool %q: %w", pool.Name, err)
 	}
-	return false, nil
+	return predicate(workerConfig), nil
 }
 
-// ensureKubeletRootDirCommandLineArg adds a flag to the kubelet to use /var/lib/containerd which is what where we also mount the created LVM if `volume` is set in the worker config
+// ensureKubeletRootDirCommandLineArg adds a flag to the kubelet to use /var/lib/containerd which is what where we also mount the created LVM if `volume` is set without a volume layout in the worker config
 func ensureKubeletRootDirCommandLineArg(command []string) []string {
 	return extensionswebhook.EnsureStringWithPrefix(command, "--root-dir=", "/var/lib/containerd")
 }
@@ -437,8 +717,19 @@ func ensureKubeletCommandLineArgs(command []string) []string {
 }
 
 // EnsureKubeletConfiguration ensures that the kubelet configuration conforms to the provider requirements.
-func (e *ensurer) EnsureKubeletConfiguration(_ context.Context, _ gcontext.GardenContext, _ *semver.Version, new, _ *kubeletconfigv1beta1.KubeletConfiguration) error {
+func (e *ensurer) EnsureKubeletConfiguration(ctx context.Context, gctx gcontext.GardenContext, _ *semver.Version, new, _ *kubeletconfigv1beta1.KubeletConfiguration) error {
 	new.EnableControllerAttachDetach = ptr.To(true)
 
+	spot, err := isSpotWorkerPool(ctx, gctx)
+	if err != nil {
+		return err
+	}
+	if spot && new.ShutdownGracePeriod.Duration == 0 {
+		// the spot termination handler powers off the node if it could not be drained in time, the kubelet must then
+		// terminate the remaining pods gracefully
+		new.ShutdownGracePeriod = metav1.Duration{Duration: 90 * time.Second}
+		new.ShutdownGracePeriodCriticalPods = metav1.Duration{Duration: 30 * time.Second}
+	}
+
 	return nil
 }
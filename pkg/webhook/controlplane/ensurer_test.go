@@ -8,6 +8,7 @@ import (
 	"context"
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/coreos/go-systemd/v22/unit"
 	extensionscontroller "github.com/gardener/gardener/extensions/pkg/controller"
@@ -405,6 +406,271 @@ var _ = Describe("Ensurer", func() {
 		})
 	})
 
+	Describe("spot instances", func() {
+		var (
+			ensurer     genericmutator.Ensurer
+			spotContext gcontext.GardenContext
+		)
+
+		BeforeEach(func() {
+			ensurer = NewEnsurer(c, logger)
+			spotContext = gcontext.NewInternalGardenContext(
+				&extensionscontroller.Cluster{
+					Shoot: &gardencorev1beta1.Shoot{
+						Spec: gardencorev1beta1.ShootSpec{
+							Provider: gardencorev1beta1.Provider{
+								Workers: []gardencorev1beta1.Worker{
+									{Name: "on-demand"},
+									{
+										Name: "spot",
+										ProviderConfig: &runtime.RawExtension{Raw: encode(&v1alpha1.WorkerConfig{
+											TypeMeta: metav1.TypeMeta{
+												APIVersion: v1alpha1.SchemeGroupVersion.String(),
+												Kind:       "WorkerConfig",
+											},
+											SpotInstance: ptr.To(true),
+										})},
+									},
+								},
+							},
+						},
+					},
+				},
+			)
+		})
+
+		It("should add the spot termination handler", func() {
+			var (
+				units []extensionsv1alpha1.Unit
+				files []extensionsv1alpha1.File
+			)
+
+			Expect(ensurer.EnsureAdditionalUnits(contextWithWorkerPool(ctx, "spot"), spotContext, &units, nil)).To(Succeed())
+			Expect(ensurer.EnsureAdditionalFiles(contextWithWorkerPool(ctx, "spot"), spotContext, &files, nil)).To(Succeed())
+
+			Expect(units).To(ContainElement(HaveField("Name", "spot-termination-handler.service")))
+			Expect(files).To(ContainElement(And(
+				HaveField("Path", "/opt/bin/spot-termination-handler.sh"),
+				HaveField("Content.Inline.Data", ContainSubstring("node.machine.sapcloud.io/trigger-deletion-by-mcm")),
+			)))
+		})
+
+		It("should not add the spot termination handler to other worker pools", func() {
+			var (
+				units []extensionsv1alpha1.Unit
+				files []extensionsv1alpha1.File
+			)
+
+			Expect(ensurer.EnsureAdditionalUnits(contextWithWorkerPool(ctx, "on-demand"), spotContext, &units, nil)).To(Succeed())
+			Expect(ensurer.EnsureAdditionalFiles(contextWithWorkerPool(ctx, "on-demand"), spotContext, &files, nil)).To(Succeed())
+
+			Expect(units).NotTo(ContainElement(HaveField("Name", "spot-termination-handler.service")))
+			Expect(files).NotTo(ContainElement(HaveField("Path", "/opt/bin/spot-termination-handler.sh")))
+		})
+
+		It("should enable the graceful node shutdown of the kubelet", func() {
+			kubeletConfig := &kubeletconfigv1beta1.KubeletConfiguration{}
+
+			Expect(ensurer.EnsureKubeletConfiguration(contextWithWorkerPool(ctx, "spot"), spotContext, nil, kubeletConfig, nil)).To(Succeed())
+			Expect(kubeletConfig.ShutdownGracePeriod).To(Equal(metav1.Duration{Duration: 90 * time.Second}))
+			Expect(kubeletConfig.ShutdownGracePeriodCriticalPods).To(Equal(metav1.Duration{Duration: 30 * time.Second}))
+		})
+
+		It("should not change the graceful node shutdown of the kubelet of other worker pools", func() {
+			kubeletConfig := &kubeletconfigv1beta1.KubeletConfiguration{}
+
+			Expect(ensurer.EnsureKubeletConfiguration(contextWithWorkerPool(ctx, "on-demand"), spotContext, nil, kubeletConfig, nil)).To(Succeed())
+			Expect(kubeletConfig.ShutdownGracePeriod.Duration).To(BeZero())
+		})
+	})
+
+	Describe("VLANs", func() {
+		var (
+			ensurer     genericmutator.Ensurer
+			vlanContext gcontext.GardenContext
+		)
+
+		BeforeEach(func() {
+			ensurer = NewEnsurer(c, logger)
+			vlanContext = gcontext.NewInternalGardenContext(
+				&extensionscontroller.Cluster{
+					Shoot: &gardencorev1beta1.Shoot{
+						Spec: gardencorev1beta1.ShootSpec{
+							Provider: gardencorev1beta1.Provider{
+								Workers: []gardencorev1beta1.Worker{
+									{Name: "layer3"},
+									{
+										Name: "hybrid",
+										ProviderConfig: &runtime.RawExtension{Raw: encode(&v1alpha1.WorkerConfig{
+											TypeMeta: metav1.TypeMeta{
+												APIVersion: v1alpha1.SchemeGroupVersion.String(),
+												Kind:       "WorkerConfig",
+											},
+											Network: &v1alpha1.WorkerNetwork{
+												Type:  ptr.To("hybrid"),
+												VLANs: []v1alpha1.VLAN{{Name: "storage"}},
+											},
+										})},
+									},
+								},
+							},
+						},
+					},
+				},
+			)
+		})
+
+		It("should add the VLAN configuration", func() {
+			var (
+				units []extensionsv1alpha1.Unit
+				files []extensionsv1alpha1.File
+			)
+
+			Expect(ensurer.EnsureAdditionalUnits(contextWithWorkerPool(ctx, "hybrid"), vlanContext, &units, nil)).To(Succeed())
+			Expect(ensurer.EnsureAdditionalFiles(contextWithWorkerPool(ctx, "hybrid"), vlanContext, &files, nil)).To(Succeed())
+
+			Expect(units).To(ContainElement(HaveField("Name", "configure-vlans.service")))
+			Expect(files).To(ContainElement(HaveField("Path", "/opt/bin/configure-vlans.sh")))
+		})
+
+		It("should not add the VLAN configuration to worker pools without VLANs", func() {
+			var (
+				units []extensionsv1alpha1.Unit
+				files []extensionsv1alpha1.File
+			)
+
+			Expect(ensurer.EnsureAdditionalUnits(contextWithWorkerPool(ctx, "layer3"), vlanContext, &units, nil)).To(Succeed())
+			Expect(ensurer.EnsureAdditionalFiles(contextWithWorkerPool(ctx, "layer3"), vlanContext, &files, nil)).To(Succeed())
+
+			Expect(units).NotTo(ContainElement(HaveField("Name", "configure-vlans.service")))
+			Expect(files).NotTo(ContainElement(HaveField("Path", "/opt/bin/configure-vlans.sh")))
+		})
+	})
+
+	Describe("volumes", func() {
+		var (
+			ensurer        genericmutator.Ensurer
+			kubeletOptions []*unit.UnitOption
+			volumeContext  gcontext.GardenContext
+		)
+
+		volumeLayoutConfig := &runtime.RawExtension{Raw: encode(&v1alpha1.WorkerConfig{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: v1alpha1.SchemeGroupVersion.String(),
+				Kind:       "WorkerConfig",
+			},
+			VolumeLayout: &v1alpha1.VolumeLayout{
+				Volumes: []v1alpha1.LogicalVolume{{Name: "containerd"}, {Name: "kubelet"}},
+			},
+		})}
+
+		newWorker := func(name, image string, volume bool, providerConfig *runtime.RawExtension) gardencorev1beta1.Worker {
+			worker := gardencorev1beta1.Worker{
+				Name:           name,
+				Machine:        gardencorev1beta1.Machine{Image: &gardencorev1beta1.ShootMachineImage{Name: image}},
+				ProviderConfig: providerConfig,
+			}
+			if volume {
+				worker.Volume = &gardencorev1beta1.Volume{VolumeSize: "50Gi"}
+			}
+			return worker
+		}
+
+		BeforeEach(func() {
+			ensurer = NewEnsurer(c, logger)
+			kubeletOptions = []*unit.UnitOption{{Section: "Service", Name: "ExecStart", Value: "/opt/bin/kubelet"}}
+			volumeContext = gcontext.NewInternalGardenContext(
+				&extensionscontroller.Cluster{
+					Shoot: &gardencorev1beta1.Shoot{
+						Spec: gardencorev1beta1.ShootSpec{
+							Provider: gardencorev1beta1.Provider{
+								Workers: []gardencorev1beta1.Worker{
+									newWorker("flatcar-volume", "flatcar", true, nil),
+									newWorker("gardenlinux-layout", "gardenlinux", false, volumeLayoutConfig),
+									newWorker("ubuntu-layout", "ubuntu", true, volumeLayoutConfig),
+									newWorker("ubuntu-no-volume", "ubuntu", false, nil),
+									newWorker("unsupported-volume", "foo", true, nil),
+								},
+							},
+						},
+					},
+				},
+			)
+		})
+
+		ensureProvisionUnitsAndFiles := func(pool string) ([]extensionsv1alpha1.Unit, []extensionsv1alpha1.File) {
+			var (
+				units []extensionsv1alpha1.Unit
+				files []extensionsv1alpha1.File
+			)
+
+			Expect(ensurer.EnsureAdditionalProvisionUnits(contextWithWorkerPool(ctx, pool), volumeContext, &units, nil)).To(Succeed())
+			Expect(ensurer.EnsureAdditionalProvisionFiles(contextWithWorkerPool(ctx, pool), volumeContext, &files, nil)).To(Succeed())
+			return units, files
+		}
+
+		ensureKubeletCommand := func(pool string) string {
+			opts, err := ensurer.EnsureKubeletServiceUnitOptions(contextWithWorkerPool(ctx, pool), volumeContext, nil, kubeletOptions, nil)
+			Expect(err).NotTo(HaveOccurred())
+			return opts[0].Value
+		}
+
+		It("should add the LVM setup and the kubelet root dir for worker pools with a volume", func() {
+			units, files := ensureProvisionUnitsAndFiles("flatcar-volume")
+
+			Expect(units).To(ConsistOf(HaveField("Name", "lvm-setup.service")))
+			Expect(files).To(ConsistOf(HaveField("Path", "/opt/bin/lvm.sh")))
+			Expect(files[0].Content.Inline.Data).NotTo(ContainSubstring("apt-get"))
+			Expect(ensureKubeletCommand("flatcar-volume")).To(ContainSubstring("--root-dir=/var/lib/containerd"))
+		})
+
+		It("should add the LVM setup without the kubelet root dir for worker pools with a volume layout", func() {
+			units, files := ensureProvisionUnitsAndFiles("gardenlinux-layout")
+
+			Expect(units).To(ConsistOf(HaveField("Name", "lvm-setup.service")))
+			Expect(files).To(ConsistOf(HaveField("Path", "/opt/bin/lvm.sh")))
+			Expect(files[0].Content.Inline.Data).To(ContainSubstring("apt-get install -y -qq lvm2 xfsprogs jq"))
+			Expect(ensureKubeletCommand("gardenlinux-layout")).NotTo(ContainSubstring("--root-dir"))
+		})
+
+		It("should install the required tools on Ubuntu", func() {
+			units, files := ensureProvisionUnitsAndFiles("ubuntu-layout")
+
+			Expect(units).To(ConsistOf(HaveField("Name", "lvm-setup.service")))
+			Expect(files).To(ConsistOf(HaveField("Path", "/opt/bin/lvm.sh")))
+			Expect(files[0].Content.Inline.Data).To(ContainSubstring("apt-get install -y -qq lvm2 xfsprogs jq"))
+			Expect(ensureKubeletCommand("ubuntu-layout")).NotTo(ContainSubstring("--root-dir"))
+		})
+
+		It("should not add the LVM setup for worker pools without volume", func() {
+			units, files := ensureProvisionUnitsAndFiles("ubuntu-no-volume")
+
+			Expect(units).To(BeEmpty())
+			Expect(files).To(BeEmpty())
+			Expect(ensureKubeletCommand("ubuntu-no-volume")).NotTo(ContainSubstring("--root-dir"))
+		})
+
+		It("should not add the LVM setup for unsupported operating systems", func() {
+			units, files := ensureProvisionUnitsAndFiles("unsupported-volume")
+
+			Expect(units).To(BeEmpty())
+			Expect(files).To(BeEmpty())
+		})
+
+		It("should not add the LVM setup if the worker pool is unknown", func() {
+			var (
+				units []extensionsv1alpha1.Unit
+				files []extensionsv1alpha1.File
+			)
+
+			Expect(ensurer.EnsureAdditionalProvisionUnits(ctx, volumeContext, &units, nil)).To(Succeed())
+			Expect(ensurer.EnsureAdditionalProvisionFiles(ctx, volumeContext, &files, nil)).To(Succeed())
+
+			Expect(units).To(BeEmpty())
+			Expect(files).To(BeEmpty())
+		})
+	})
+
 	Describe("#EnsureMachineControllerManagerDeployment", func() {
 		var (
 			ensurer    genericmutator.Ensurer
@@ -12,6 +12,8 @@ import (
 
 	extensionswebhook "github.com/gardener/gardener/extensions/pkg/webhook"
 	extensionscontextwebhook "github.com/gardener/gardener/extensions/pkg/webhook/context"
+	v1beta1constants "github.com/gardener/gardener/pkg/apis/core/v1beta1/constants"
+	extensionsv1alpha1 "github.com/gardener/gardener/pkg/apis/extensions/v1alpha1"
 	resourcemanagerv1alpha1 "github.com/gardener/gardener/pkg/resourcemanager/apis/config/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -53,9 +55,31 @@ func (m *customMutator) Mutate(ctx context.Context, new, old client.Object) erro
 		return nil
 	}
 
+	// The ensurer only receives the files and units of OperatingSystemConfigs, hence, their worker pool is passed via
+	// the context.
+	if osc, ok := new.(*extensionsv1alpha1.OperatingSystemConfig); ok {
+		if pool, ok := osc.Labels[v1beta1constants.LabelWorkerPool]; ok {
+			ctx = contextWithWorkerPool(ctx, pool)
+		}
+	}
+
 	return m.delegateMutator.Mutate(ctx, new, old)
 }
 
+type workerPoolContextKey struct{}
+
+// contextWithWorkerPool returns a copy of the given context which contains the name of the worker pool of the mutated
+// OperatingSystemConfig.
+func contextWithWorkerPool(ctx context.Context, pool string) context.Context {
+	return context.WithValue(ctx, workerPoolContextKey{}, pool)
+}
+
+// workerPoolFromContext returns the name of the worker pool of the mutated OperatingSystemConfig, if any.
+func workerPoolFromContext(ctx context.Context) (string, bool) {
+	pool, ok := ctx.Value(workerPoolContextKey{}).(string)
+	return pool, ok
+}
+
 func (m *customMutator) mutate(ctx context.Context, new, old client.Object) (bool, error) {
 	if new.GetDeletionTimestamp() != nil {
 		return true, nil